@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"time"
 	"xfirefly/pkg/cli"
+	"xfirefly/pkg/network"
 	"xfirefly/pkg/runner"
+	"xfirefly/pkg/server"
 	"xfirefly/pkg/types"
 	"xfirefly/pkg/utils/common"
 
@@ -53,8 +56,32 @@ func Execute() {
 
 	// 打印所有内置配置
 	if options.PrintPreset {
-		logger.Info("正在打印内置指纹信息")
-		if err := runner.PrintPresetFinger(); err != nil {
+		if !options.JSONOutput {
+			logger.Info("正在打印内置指纹信息")
+		}
+		if err := runner.PrintPresetFinger(options.JSONOutput); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// 打印当前加载的指纹信息
+	if options.ListFingers {
+		if err := runner.ListFingers(options.FingerOptions, options.JSONOutput); err != nil {
+			logger.Error(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// 重放单个原始请求，用于规则调试
+	if options.ReplayRequest != "" {
+		if len(options.Target) == 0 {
+			logger.Error("--replay-request 需要使用-u指定目标")
+			os.Exit(1)
+		}
+		if err := runner.ReplayRequest(options.Target[0], options); err != nil {
+			logger.Error(err)
 			os.Exit(1)
 		}
 		os.Exit(0)
@@ -106,8 +133,18 @@ func Execute() {
 	// 记录运行开始时间
 	startTime := time.Now()
 
-	// 运行核心程序
-	run(options)
+	// 服务模式
+	if options.Serve != "" {
+		serve(options)
+		return
+	}
+
+	// 运行核心程序，指定被动识别目录时仅处理已捕获的数据
+	if options.Passive != "" {
+		passive(options)
+	} else {
+		run(options)
+	}
 
 	// 运行结束时间
 	// 计算并打印运行时间
@@ -203,12 +240,92 @@ func run(options *types.CmdOptionsType) {
 	runner.StartMemoryMonitor()
 	// 停止内存监控，延时调用，后进先出
 	defer runner.StopMemoryMonitor()
+	// 启动前验证代理可用性
+	if err := checkProxy(options); err != nil {
+		logger.Error(err)
+		return
+	}
 	// 声明一个新的Runner
-	r := runner.NewRunner(options)
+	r, err := runner.NewRunner(options)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
 	// 运行扫描
 	if err := r.Run(options); err != nil {
 		// 错误已在Run函数内部记录，这里无需额外处理
 		logger.Error(err)
 		return
 	}
+	// 部分目标失败时仅提示汇总信息
+	if summary := r.LastScanSummary(); summary != nil {
+		logger.Warn(summary.Error())
+	}
+}
+
+// serve
+//
+//	@Description: 以HTTP服务模式运行，通过接口接收扫描目标
+func serve(options *types.CmdOptionsType) {
+	if err := checkProxy(options); err != nil {
+		logger.Error(err)
+		return
+	}
+	r, err := runner.NewRunner(options)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	// 加载指纹并初始化规则池
+	if err := r.Start(options); err != nil {
+		logger.Error(err)
+		return
+	}
+	defer r.Stop()
+
+	if err := server.NewServer(r).ListenAndServe(options.Serve); err != nil {
+		logger.Error(fmt.Sprintf("HTTP服务异常退出: %v", err))
+	}
+}
+
+// passive
+//
+//	@Description: 被动识别模式，仅对目录中已捕获的请求响应执行指纹识别
+func passive(options *types.CmdOptionsType) {
+	r, err := runner.NewRunner(options)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	if err := r.RunPassive(options); err != nil {
+		logger.Error(err)
+	}
+}
+
+// checkProxy
+//
+//	@Description: 指定代理时校验代理地址并连接代理服务器，指定 --proxy-test 时再通过代理发送一次探测请求，代理不可用时返回错误，开启 --ignore-proxy-errors 时仅告警
+func checkProxy(options *types.CmdOptionsType) error {
+	if options.Proxy == "" {
+		return nil
+	}
+	timeout := time.Duration(options.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = network.DefaultTimeout
+	}
+	err := network.CheckProxy(options.Proxy, timeout)
+	if err == nil && options.ProxyTest != "" {
+		// 探测前应用代理证书校验配置
+		network.SetTLSVerify(options.VerifyTLS, options.VerifyProxyTLS)
+		err = network.TestProxy(options.Proxy, options.ProxyTest, timeout)
+	}
+	if err == nil {
+		logger.Debugf("代理检测通过：%s", options.Proxy)
+		return nil
+	}
+	if options.IgnoreProxyErrors {
+		logger.Warnf("代理检测失败，继续运行: %v", err)
+		return nil
+	}
+	return fmt.Errorf("代理不可用: %v（可使用 --ignore-proxy-errors 忽略）", err)
 }
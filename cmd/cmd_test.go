@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"net"
+	"testing"
+	"xfirefly/pkg/types"
+)
+
+func TestCheckProxy(t *testing.T) {
+	// 占用后立即关闭的端口，连接必然失败
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadProxy := "http://" + ln.Addr().String()
+	_ = ln.Close()
+
+	live, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = live.Close() }()
+
+	tests := []struct {
+		name    string
+		options types.CmdOptionsType
+		wantErr bool
+	}{
+		{"未指定代理", types.CmdOptionsType{}, false},
+		{"代理无法连接", types.CmdOptionsType{Proxy: deadProxy, Timeout: 2}, true},
+		{"代理协议不支持", types.CmdOptionsType{Proxy: "ftp://127.0.0.1:21", Timeout: 2}, true},
+		{"代理缺少主机", types.CmdOptionsType{Proxy: "http://", Timeout: 2}, true},
+		{"忽略代理错误", types.CmdOptionsType{Proxy: deadProxy, Timeout: 2, IgnoreProxyErrors: true}, false},
+		{"代理可连接", types.CmdOptionsType{Proxy: "http://" + live.Addr().String(), Timeout: 2}, false},
+	}
+	for _, tt := range tests {
+		if err := checkProxy(&tt.options); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkProxy() 错误 = %v，期望出错 %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
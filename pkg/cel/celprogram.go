@@ -23,6 +23,9 @@ import (
 	"github.com/google/cel-go/common/types/ref"
 )
 
+// timeNow 日期辅助函数使用的当前时间，测试中可替换为固定时刻
+var timeNow = time.Now
+
 // FunctionEnvOptions 将所有函数绑定迁移到 Env 期，避免已弃用 Program 期的 cel.Functions 与 interpreter/functions.Overload
 var FunctionEnvOptions = []cel.EnvOption{
 	// icontains: instance string method
@@ -42,6 +45,57 @@ var FunctionEnvOptions = []cel.EnvOption{
 			}),
 		),
 	),
+	// iequals: 忽略大小写判断字符串相等
+	cel.Function("iequals",
+		cel.MemberOverload("string_iequals_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.String)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to iequals", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to iequals", rhs.Type())
+				}
+				return types.Bool(strings.EqualFold(string(v1), string(v2)))
+			}),
+		),
+	),
+	// istartsWith: 忽略大小写判断字符串前缀
+	cel.Function("istartsWith",
+		cel.MemberOverload("string_istartsWith_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.String)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to istartsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to istartsWith", rhs.Type())
+				}
+				return types.Bool(strings.HasPrefix(strings.ToLower(string(v1)), strings.ToLower(string(v2))))
+			}),
+		),
+	),
+	// iendsWith: 忽略大小写判断字符串后缀
+	cel.Function("iendsWith",
+		cel.MemberOverload("string_iendsWith_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.String)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to iendsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to iendsWith", rhs.Type())
+				}
+				return types.Bool(strings.HasSuffix(strings.ToLower(string(v1)), strings.ToLower(string(v2))))
+			}),
+		),
+	),
 	// substr(s, start, length)
 	cel.Function("substr",
 		cel.Overload("substr_string_int_int",
@@ -184,6 +238,152 @@ var FunctionEnvOptions = []cel.EnvOption{
 			}),
 		),
 	),
+	// bendsWith: 判断字节流后缀
+	cel.Function("bendsWith",
+		cel.MemberOverload("bytes_bendsWith_bytes",
+			[]*cel.Type{cel.BytesType, cel.BytesType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to bendsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to bendsWith", rhs.Type())
+				}
+				return types.Bool(bytes.HasSuffix(v1, v2))
+			}),
+		),
+	),
+	// ibstartsWith: 忽略大小写判断字节流前缀
+	cel.Function("ibstartsWith",
+		cel.MemberOverload("bytes_ibstartsWith_bytes",
+			[]*cel.Type{cel.BytesType, cel.BytesType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to ibstartsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to ibstartsWith", rhs.Type())
+				}
+				return types.Bool(bytes.HasPrefix(bytes.ToLower(v1), bytes.ToLower(v2)))
+			}),
+		),
+	),
+	// ibendsWith: 忽略大小写判断字节流后缀
+	cel.Function("ibendsWith",
+		cel.MemberOverload("bytes_ibendsWith_bytes",
+			[]*cel.Type{cel.BytesType, cel.BytesType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to ibendsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to ibendsWith", rhs.Type())
+				}
+				return types.Bool(bytes.HasSuffix(bytes.ToLower(v1), bytes.ToLower(v2)))
+			}),
+		),
+	),
+	// 字节流与字符串参数的重载：response.body 与 response.rawbody 为 bytes，可直接写 response.body.contains("xx")，无需转换整个响应体
+	cel.Function("contains",
+		cel.MemberOverload("bytes_contains_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to contains", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to contains", rhs.Type())
+				}
+				return types.Bool(bytes.Contains(v1, []byte(v2)))
+			}),
+		),
+	),
+	cel.Function("icontains",
+		cel.MemberOverload("bytes_icontains_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to icontains", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to icontains", rhs.Type())
+				}
+				return types.Bool(bytes.Contains(bytes.ToLower(v1), bytes.ToLower([]byte(v2))))
+			}),
+		),
+	),
+	cel.Function("startsWith",
+		cel.MemberOverload("bytes_startsWith_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to startsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to startsWith", rhs.Type())
+				}
+				return types.Bool(bytes.HasPrefix(v1, []byte(v2)))
+			}),
+		),
+	),
+	cel.Function("endsWith",
+		cel.MemberOverload("bytes_endsWith_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to endsWith", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to endsWith", rhs.Type())
+				}
+				return types.Bool(bytes.HasSuffix(v1, []byte(v2)))
+			}),
+		),
+	),
+	// count(haystack, needle) 统计 needle 在 haystack 中不重叠出现的次数，needle 为空时返回 0
+	cel.Function("count",
+		cel.Overload("count_string_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+			cel.BinaryBinding(countOccurrences),
+		),
+		cel.Overload("count_bytes_bytes",
+			[]*cel.Type{cel.BytesType, cel.BytesType}, cel.IntType,
+			cel.BinaryBinding(countOccurrences),
+		),
+		cel.MemberOverload("string_count_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+			cel.BinaryBinding(countOccurrences),
+		),
+		cel.MemberOverload("bytes_count_bytes",
+			[]*cel.Type{cel.BytesType, cel.BytesType}, cel.IntType,
+			cel.BinaryBinding(countOccurrences),
+		),
+	),
+	// bcontainsAt(body, offset, needle) 判断字节流在指定偏移处是否为 needle，越界返回 false
+	cel.Function("bcontainsAt",
+		cel.Overload("bcontainsAt_bytes_int_bytes",
+			[]*cel.Type{cel.BytesType, cel.IntType, cel.BytesType}, cel.BoolType,
+			cel.FunctionBinding(bytesContainsAt),
+		),
+		cel.MemberOverload("bytes_bcontainsAt_int_bytes",
+			[]*cel.Type{cel.BytesType, cel.IntType, cel.BytesType}, cel.BoolType,
+			cel.FunctionBinding(bytesContainsAt),
+		),
+	),
 	// encode
 	cel.Function("md5",
 		cel.Overload("md5_string",
@@ -249,6 +449,36 @@ var FunctionEnvOptions = []cel.EnvOption{
 			}),
 		),
 	),
+	cel.Function("base64DecodeBytes",
+		cel.Overload("base64DecodeBytes_string",
+			[]*cel.Type{cel.StringType}, cel.BytesType,
+			cel.UnaryBinding(func(value ref.Val) ref.Val {
+				v, ok := value.(types.String)
+				if !ok {
+					return types.ValOrErr(value, "unexpected type '%v' passed to base64DecodeBytes_string", value.Type())
+				}
+				decodeBytes, err := base64.StdEncoding.DecodeString(string(v))
+				if err != nil {
+					return types.NewErr("%v", err)
+				}
+				return types.Bytes(decodeBytes)
+			}),
+		),
+		cel.Overload("base64DecodeBytes_bytes",
+			[]*cel.Type{cel.BytesType}, cel.BytesType,
+			cel.UnaryBinding(func(value ref.Val) ref.Val {
+				v, ok := value.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(value, "unexpected type '%v' passed to base64DecodeBytes_bytes", value.Type())
+				}
+				decodeBytes, err := base64.StdEncoding.DecodeString(string(v))
+				if err != nil {
+					return types.NewErr("%v", err)
+				}
+				return types.Bytes(decodeBytes)
+			}),
+		),
+	),
 	cel.Function("urlencode",
 		cel.Overload("urlencode_string",
 			[]*cel.Type{cel.StringType}, cel.StringType,
@@ -315,6 +545,37 @@ var FunctionEnvOptions = []cel.EnvOption{
 			}),
 		),
 	),
+	// simhash 用于对相似响应体聚类（如默认错误页）
+	cel.Function("simhash",
+		cel.Overload("simhash_stringOrBytes",
+			[]*cel.Type{cel.DynType}, cel.IntType,
+			cel.UnaryBinding(func(value ref.Val) ref.Val {
+				if b, ok := value.(types.Bytes); ok {
+					return types.Int(common.SimHash(b))
+				}
+				if bStr, ok := value.(types.String); ok {
+					return types.Int(common.SimHash([]byte(bStr)))
+				}
+				return types.ValOrErr(value, "unexpected type '%v' passed to simhash", value.Type())
+			}),
+		),
+	),
+	cel.Function("simhashDistance",
+		cel.Overload("simhashDistance_int_int",
+			[]*cel.Type{cel.IntType, cel.IntType}, cel.IntType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Int)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to simhashDistance", lhs.Type())
+				}
+				v2, ok := rhs.(types.Int)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to simhashDistance", rhs.Type())
+				}
+				return types.Int(common.HammingDistance(uint64(v1), uint64(v2)))
+			}),
+		),
+	),
 	cel.Function("hexdecode",
 		cel.Overload("hexdecode_string",
 			[]*cel.Type{cel.StringType}, cel.StringType,
@@ -366,23 +627,7 @@ var FunctionEnvOptions = []cel.EnvOption{
 	cel.Function("bmatches",
 		cel.MemberOverload("string_bmatches_bytes",
 			[]*cel.Type{cel.StringType, cel.BytesType}, cel.BoolType,
-			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
-				var isMatch = false
-				var err error
-				v1, ok := lhs.(types.String)
-				if !ok {
-					return types.ValOrErr(lhs, "unexpected type '%v' passed to bmatches", lhs.Type())
-				}
-				v2, ok := rhs.(types.Bytes)
-				if !ok {
-					return types.ValOrErr(rhs, "unexpected type '%v' passed to bmatches", rhs.Type())
-				}
-				re := regexp2.MustCompile(string(v1), 0)
-				if isMatch, err = re.MatchString(string(v2)); err != nil {
-					return types.NewErr("%v", err)
-				}
-				return types.Bool(isMatch)
-			}),
+			cel.BinaryBinding(regexBytesMatches),
 		),
 	),
 	cel.Function("submatch",
@@ -415,27 +660,48 @@ var FunctionEnvOptions = []cel.EnvOption{
 	cel.Function("bsubmatch",
 		cel.MemberOverload("string_bsubmatch_bytes",
 			[]*cel.Type{cel.StringType, cel.BytesType}, cel.MapType(cel.StringType, cel.StringType),
+			cel.BinaryBinding(regexBytesSubmatch),
+		),
+	),
+	// header
+	cel.Function("hasHeader",
+		cel.Overload("hasHeader_dyn_string",
+			[]*cel.Type{cel.DynType, cel.StringType}, cel.BoolType,
 			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
-				resultMap := make(map[string]string)
-				v1, ok := lhs.(types.String)
+				headers, ok := headersOf(lhs.Value())
 				if !ok {
-					return types.ValOrErr(lhs, "unexpected type '%v' passed to bsubmatch", lhs.Type())
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to hasHeader", lhs.Type())
 				}
-				v2, ok := rhs.(types.Bytes)
+				name, ok := rhs.(types.String)
 				if !ok {
-					return types.ValOrErr(rhs, "unexpected type '%v' passed to bsubmatch", rhs.Type())
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to hasHeader", rhs.Type())
 				}
-				re := regexp2.MustCompile(string(v1), regexp2.RE2)
-				if m, _ := re.FindStringMatch(string(v2)); m != nil {
-					gps := m.Groups()
-					for n, gp := range gps {
-						if n == 0 {
-							continue
-						}
-						resultMap[gp.Name] = gp.String()
-					}
+				_, found := lookupHeader(headers, string(name))
+				return types.Bool(found)
+			}),
+		),
+	),
+	cel.Function("headerContains",
+		cel.Overload("headerContains_dyn_string_string",
+			[]*cel.Type{cel.DynType, cel.StringType, cel.StringType}, cel.BoolType,
+			cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+				headers, ok := headersOf(args[0].Value())
+				if !ok {
+					return types.ValOrErr(args[0], "unexpected type '%v' passed to headerContains", args[0].Type())
 				}
-				return types.NewStringStringMap(types.DefaultTypeAdapter, resultMap)
+				name, ok := args[1].(types.String)
+				if !ok {
+					return types.ValOrErr(args[1], "unexpected type '%v' passed to headerContains", args[1].Type())
+				}
+				value, ok := args[2].(types.String)
+				if !ok {
+					return types.ValOrErr(args[2], "unexpected type '%v' passed to headerContains", args[2].Type())
+				}
+				v, found := lookupHeader(headers, string(name))
+				if !found {
+					return types.False
+				}
+				return types.Bool(strings.Contains(strings.ToLower(v), strings.ToLower(string(value))))
 			}),
 		),
 	),
@@ -491,7 +757,7 @@ var FunctionEnvOptions = []cel.EnvOption{
 		cel.Overload("year_string",
 			[]*cel.Type{cel.IntType}, cel.StringType,
 			cel.UnaryBinding(func(_ ref.Val) ref.Val {
-				year := time.Now().Format("2006")
+				year := timeNow().Format("2006")
 				return types.String(year)
 			}),
 		),
@@ -500,7 +766,7 @@ var FunctionEnvOptions = []cel.EnvOption{
 		cel.Overload("shortyear_string",
 			[]*cel.Type{cel.IntType}, cel.StringType,
 			cel.UnaryBinding(func(_ ref.Val) ref.Val {
-				year := time.Now().Format("06")
+				year := timeNow().Format("06")
 				return types.String(year)
 			}),
 		),
@@ -509,7 +775,7 @@ var FunctionEnvOptions = []cel.EnvOption{
 		cel.Overload("month_string",
 			[]*cel.Type{cel.IntType}, cel.StringType,
 			cel.UnaryBinding(func(_ ref.Val) ref.Val {
-				month := time.Now().Format("01")
+				month := timeNow().Format("01")
 				return types.String(month)
 			}),
 		),
@@ -518,7 +784,7 @@ var FunctionEnvOptions = []cel.EnvOption{
 		cel.Overload("day_string",
 			[]*cel.Type{cel.IntType}, cel.StringType,
 			cel.UnaryBinding(func(_ ref.Val) ref.Val {
-				day := time.Now().Format("02")
+				day := timeNow().Format("02")
 				return types.String(day)
 			}),
 		),
@@ -527,11 +793,44 @@ var FunctionEnvOptions = []cel.EnvOption{
 		cel.Overload("timestamp_second_string",
 			[]*cel.Type{cel.IntType}, cel.StringType,
 			cel.UnaryBinding(func(_ ref.Val) ref.Val {
-				timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+				timestamp := strconv.FormatInt(timeNow().Unix(), 10)
 				return types.String(timestamp)
 			}),
 		),
 	),
+	// UTC 时间辅助函数，避免不同时区机器生成的payload不一致
+	cel.Function("yearUTC",
+		cel.Overload("yearUTC_string",
+			[]*cel.Type{cel.IntType}, cel.StringType,
+			cel.UnaryBinding(func(_ ref.Val) ref.Val {
+				return types.String(timeNow().UTC().Format("2006"))
+			}),
+		),
+	),
+	cel.Function("shortyearUTC",
+		cel.Overload("shortyearUTC_string",
+			[]*cel.Type{cel.IntType}, cel.StringType,
+			cel.UnaryBinding(func(_ ref.Val) ref.Val {
+				return types.String(timeNow().UTC().Format("06"))
+			}),
+		),
+	),
+	cel.Function("monthUTC",
+		cel.Overload("monthUTC_string",
+			[]*cel.Type{cel.IntType}, cel.StringType,
+			cel.UnaryBinding(func(_ ref.Val) ref.Val {
+				return types.String(timeNow().UTC().Format("01"))
+			}),
+		),
+	),
+	cel.Function("dayUTC",
+		cel.Overload("dayUTC_string",
+			[]*cel.Type{cel.IntType}, cel.StringType,
+			cel.UnaryBinding(func(_ ref.Val) ref.Val {
+				return types.String(timeNow().UTC().Format("02"))
+			}),
+		),
+	),
 }
 
 // reverseCheck 检查反向连接
@@ -583,3 +882,121 @@ func jndiCheck(reverse *proto.Reverse, timeout int64) bool {
 
 	return false
 }
+
+// bytesContainsAt bcontainsAt 的实现，比较字节流在固定偏移处的内容
+func bytesContainsAt(values ...ref.Val) ref.Val {
+	if len(values) != 3 {
+		return types.NewErr("invalid arguments to 'bcontainsAt'")
+	}
+	body, ok := values[0].(types.Bytes)
+	if !ok {
+		return types.ValOrErr(values[0], "unexpected type '%v' passed to bcontainsAt", values[0].Type())
+	}
+	offset, ok := values[1].(types.Int)
+	if !ok {
+		return types.ValOrErr(values[1], "unexpected type '%v' passed to bcontainsAt", values[1].Type())
+	}
+	needle, ok := values[2].(types.Bytes)
+	if !ok {
+		return types.ValOrErr(values[2], "unexpected type '%v' passed to bcontainsAt", values[2].Type())
+	}
+	// 以减法比较，避免偏移接近 int64 上限时相加溢出
+	if offset < 0 || len(needle) > len(body) || int64(offset) > int64(len(body)-len(needle)) {
+		return types.False
+	}
+	return types.Bool(bytes.Equal(body[offset:int(offset)+len(needle)], needle))
+}
+
+// regexBytesMatches bmatches 的实现，以正则匹配字节流
+func regexBytesMatches(lhs ref.Val, rhs ref.Val) ref.Val {
+	var isMatch = false
+	var err error
+	v1, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to bmatches", lhs.Type())
+	}
+	v2, ok := rhs.(types.Bytes)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed to bmatches", rhs.Type())
+	}
+	re := regexp2.MustCompile(string(v1), 0)
+	if isMatch, err = re.MatchString(string(v2)); err != nil {
+		return types.NewErr("%v", err)
+	}
+	return types.Bool(isMatch)
+}
+
+// regexBytesSubmatch bsubmatch 的实现，返回字节流中正则命名分组的匹配结果
+func regexBytesSubmatch(lhs ref.Val, rhs ref.Val) ref.Val {
+	resultMap := make(map[string]string)
+	v1, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to bsubmatch", lhs.Type())
+	}
+	v2, ok := rhs.(types.Bytes)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed to bsubmatch", rhs.Type())
+	}
+	re := regexp2.MustCompile(string(v1), regexp2.RE2)
+	if m, _ := re.FindStringMatch(string(v2)); m != nil {
+		gps := m.Groups()
+		for n, gp := range gps {
+			if n == 0 {
+				continue
+			}
+			resultMap[gp.Name] = gp.String()
+		}
+	}
+	return types.NewStringStringMap(types.DefaultTypeAdapter, resultMap)
+}
+
+// countOccurrences count 的实现，支持字符串与字节流，按 strings.Count 语义统计不重叠出现次数
+func countOccurrences(lhs ref.Val, rhs ref.Val) ref.Val {
+	switch haystack := lhs.(type) {
+	case types.String:
+		needle, ok := rhs.(types.String)
+		if !ok {
+			return types.ValOrErr(rhs, "unexpected type '%v' passed to count", rhs.Type())
+		}
+		if len(needle) == 0 {
+			return types.Int(0)
+		}
+		return types.Int(strings.Count(string(haystack), string(needle)))
+	case types.Bytes:
+		needle, ok := rhs.(types.Bytes)
+		if !ok {
+			return types.ValOrErr(rhs, "unexpected type '%v' passed to count", rhs.Type())
+		}
+		if len(needle) == 0 {
+			return types.Int(0)
+		}
+		return types.Int(bytes.Count(haystack, needle))
+	default:
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to count", lhs.Type())
+	}
+}
+
+// headersOf 取出请求或响应对象的HTTP头
+func headersOf(v interface{}) (map[string]string, bool) {
+	switch obj := v.(type) {
+	case *proto.Response:
+		return obj.GetHeaders(), true
+	case *proto.Request:
+		return obj.GetHeaders(), true
+	default:
+		return nil, false
+	}
+}
+
+// lookupHeader 忽略大小写查找HTTP头
+func lookupHeader(headers map[string]string, name string) (string, bool) {
+	if v, ok := headers[strings.ToLower(name)]; ok {
+		return v, true
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
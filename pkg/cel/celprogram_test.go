@@ -0,0 +1,323 @@
+package cel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+	"xfirefly/pkg/utils/proto"
+)
+
+// evalValue 在默认环境中执行表达式并返回结果值
+func evalValue(t *testing.T, expression string, variables map[string]any) any {
+	t.Helper()
+	out, err := NewCustomLib().Evaluate(expression, variables)
+	if err != nil {
+		t.Fatalf("执行表达式 %s 失败: %v", expression, err)
+	}
+	return out.Value()
+}
+
+// evalBool 在默认环境中执行表达式并返回布尔结果
+func evalBool(t *testing.T, expression string, variables map[string]any) bool {
+	t.Helper()
+	out, err := NewCustomLib().Evaluate(expression, variables)
+	if err != nil {
+		t.Fatalf("执行表达式 %s 失败: %v", expression, err)
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		t.Fatalf("表达式 %s 返回非布尔值: %v", expression, out)
+	}
+	return b
+}
+
+func TestResponseBodyAndRawbody(t *testing.T) {
+	resp := &proto.Response{}
+	resp.SetBody([]byte("<title>Hello World</title>"))
+	vars := map[string]any{"response": resp}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		// body 为字节流，可直接使用字符串参数重载
+		{`response.body.contains("Hello")`, true},
+		{`response.body.icontains("hello world")`, true},
+		{`response.body.startsWith("<title>")`, true},
+		{`response.body.endsWith("</title>")`, true},
+		{`response.body.contains("missing")`, false},
+		{`string(response.body).matches("Hello\\s+World")`, true},
+		{`response.body == b"<title>Hello World</title>"`, true},
+		// 字节流方法
+		{`response.body.bcontains(b"Hello")`, true},
+		{`response.body.ibcontains(b"HELLO")`, true},
+		{`response.body.bcontainsAt(7, b"Hello")`, true},
+		{`bcontainsAt(response.body, 8, b"Hello")`, false},
+		{`"Hello\\s+World".bmatches(response.body)`, true},
+		{`"(?P<name>W\\w+)".bsubmatch(response.body)["name"] == "World"`, true},
+		// rawbody 与 body 内容相同
+		{`response.rawbody.bcontains(b"Hello")`, true},
+		{`response.rawbody.contains("World")`, true},
+		{`response.rawbody == response.body`, true},
+	}
+	for _, tt := range tests {
+		if got := evalBool(t, tt.expression, vars); got != tt.want {
+			t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestResponseSetBodyCopies(t *testing.T) {
+	raw := []byte("binary\x00\xffbody")
+	resp := &proto.Response{}
+	resp.SetBody(raw)
+	if string(resp.Body) != "binary\x00\xffbody" || &resp.Body[0] != &resp.Rawbody[0] {
+		t.Fatalf("body = %q，rawbody = %q，期望共用同一份响应体", resp.Body, resp.Rawbody)
+	}
+	// 调用方之后修改原切片不影响响应体
+	raw[0] = 'B'
+	if resp.Body[0] != 'b' {
+		t.Errorf("修改原切片后 body = %q，期望不受影响", resp.Body)
+	}
+
+	resp.SetBody(nil)
+	if resp.Body == nil || len(resp.Rawbody) != 0 {
+		t.Errorf("空响应体 body = %v，rawbody = %v，期望为空切片", resp.Body, resp.Rawbody)
+	}
+}
+
+func TestUTCDateFunctions(t *testing.T) {
+	// 固定在 UTC 跨年前的时刻，+14 时区的本地日期已是次年，本地与UTC函数结果必然不同
+	previousLocal, previousNow := time.Local, timeNow
+	time.Local = time.FixedZone("X", 14*3600)
+	timeNow = func() time.Time { return time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC).In(time.Local) }
+	t.Cleanup(func() {
+		time.Local, timeNow = previousLocal, previousNow
+	})
+
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{"yearUTC(0)", "2024"},
+		{"shortyearUTC(0)", "24"},
+		{"monthUTC(0)", "12"},
+		{"dayUTC(0)", "31"},
+		// 本地时间函数按本地时区计算
+		{"year(0)", "2025"},
+		{"shortyear(0)", "25"},
+		{"month(0)", "01"},
+		{"day(0)", "01"},
+	}
+	for _, tt := range tests {
+		if got := evalValue(t, tt.expression, nil); got != tt.want {
+			t.Errorf("%s = %v，期望 %s", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestCaseInsensitiveStringFunctions(t *testing.T) {
+	resp := &proto.Response{Headers: map[string]string{"server": "Microsoft-IIS/10.0"}}
+	variables := map[string]any{"response": resp}
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`response.headers["server"].iequals("microsoft-iis/10.0")`, true},
+		{`response.headers["server"].iequals("microsoft-iis")`, false},
+		{`response.headers["server"].istartsWith("MICROSOFT-IIS")`, true},
+		{`response.headers["server"].istartsWith("iis")`, false},
+		{`response.headers["server"].iendsWith("/10.0")`, true},
+		{`response.headers["server"].iendsWith("IIS")`, false},
+		{`"".istartsWith("")`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			if got := evalBool(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBytesPrefixSuffixFunctions(t *testing.T) {
+	resp := &proto.Response{}
+	resp.SetBody([]byte("<!DOCTYPE html><html>Powered by Jetty</HTML>"))
+	variables := map[string]any{"response": resp}
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`response.rawbody.bendsWith(b"</HTML>")`, true},
+		{`response.rawbody.bendsWith(b"</html>")`, false},
+		{`response.rawbody.ibstartsWith(b"<!doctype HTML")`, true},
+		{`response.rawbody.ibstartsWith(b"<html>")`, false},
+		{`response.rawbody.ibendsWith(b"</html>")`, true},
+		{`response.rawbody.ibendsWith(b"jetty")`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			if got := evalBool(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountFunction(t *testing.T) {
+	resp := &proto.Response{}
+	resp.SetBody([]byte(`<script src="a.js"></script><script src="b.js"></script>`))
+	variables := map[string]any{"response": resp}
+	tests := []struct {
+		expression string
+		want       int64
+	}{
+		{`string(response.body).count("<script")`, 2},
+		{`count(string(response.body), "</script>")`, 2},
+		{`response.rawbody.count(b"src=")`, 2},
+		{`count(response.rawbody, b"<link")`, 0},
+		{`"aaaa".count("aa")`, 2}, // 不重叠统计
+		{`string(response.body).count("")`, 0},
+		{`count(b"abc", b"")`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			if got := evalValue(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %d", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderFunctions(t *testing.T) {
+	variables := map[string]any{
+		"response": &proto.Response{Headers: map[string]string{"server": "Apache-Coyote/1.1", "X-Custom": "Enabled"}},
+		"request":  &proto.Request{Headers: map[string]string{"user-agent": "xfirefly"}},
+	}
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`hasHeader(response, "Server")`, true},
+		{`hasHeader(response, "x-custom")`, true},
+		{`hasHeader(response, "X-Powered-By")`, false},
+		{`hasHeader(request, "User-Agent")`, true},
+		{`headerContains(response, "SERVER", "coyote")`, true},
+		{`headerContains(response, "x-custom", "ENABLED")`, true},
+		{`headerContains(response, "server", "nginx")`, false},
+		{`headerContains(response, "x-powered-by", "")`, false}, // 响应头不存在
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			if got := evalBool(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+
+	// 非请求/响应对象返回错误
+	if _, err := NewCustomLib().Evaluate(`hasHeader("server", "server")`, variables); err == nil {
+		t.Error("hasHeader 传入字符串时应返回错误")
+	}
+}
+
+func TestSimhashFunctions(t *testing.T) {
+	// 仅个别单词不同的默认错误页应聚为一类，内容无关的页面则相距较远
+	page := "<html><head><title>404 Not Found</title></head><body><h1>Not Found</h1>" +
+		"<p>The requested URL was not found on this server.</p><hr><address>Apache Server at %s Port 80</address></body></html>"
+	unrelated := `{"code":0,"data":{"items":[1,2,3],"total":3},"message":"success","request_id":"f3b1c2"}` +
+		` login dashboard settings profile logout admin user password token session`
+	pair := func(response, request string) map[string]any {
+		resp := &proto.Response{}
+		resp.SetBody([]byte(response))
+		return map[string]any{"response": resp, "request": &proto.Request{Body: []byte(request)}}
+	}
+	distance := `simhashDistance(simhash(response.body), simhash(request.body))`
+
+	same := pair(strings.ReplaceAll(page, "%s", "example.com"), strings.ReplaceAll(page, "%s", "example.com"))
+	if got := evalValue(t, distance, same); got != int64(0) {
+		t.Errorf("相同输入的 simhash 距离 = %v，期望 0", got)
+	}
+	if !evalBool(t, `simhash(response.body) == simhash(string(response.body))`, same) {
+		t.Error("字符串与字节流的 simhash 应一致")
+	}
+
+	near, _ := evalValue(t, distance, pair(strings.ReplaceAll(page, "%s", "example.com"), strings.ReplaceAll(page, "%s", "example.org"))).(int64)
+	far, _ := evalValue(t, distance, pair(strings.ReplaceAll(page, "%s", "example.com"), unrelated)).(int64)
+	// 64 位指纹中相似页面仅有少数位不同，无关页面约一半位不同
+	if near > 8 {
+		t.Errorf("相似页面的 simhash 距离 = %d，期望不超过 8", near)
+	}
+	if far < 20 {
+		t.Errorf("无关页面的 simhash 距离 = %d，期望不小于 20", far)
+	}
+
+	if got := evalValue(t, `simhash(b"")`, nil); got != int64(0) {
+		t.Errorf("simhash(b\"\") = %v，期望 0", got)
+	}
+}
+
+func TestBcontainsAtBounds(t *testing.T) {
+	resp := &proto.Response{}
+	resp.SetBody([]byte("\x16\x03\x01HELLO"))
+	variables := map[string]any{"response": resp}
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{"起始偏移", `response.body.bcontainsAt(0, b"\x16\x03")`, true},
+		{"末尾对齐", `response.body.bcontainsAt(3, b"HELLO")`, true},
+		{"末尾空串", `response.body.bcontainsAt(8, b"")`, true},
+		{"负偏移", `response.body.bcontainsAt(-1, b"\x16")`, false},
+		{"负偏移空串", `bcontainsAt(response.body, -1, b"")`, false},
+		{"超出末尾", `response.body.bcontainsAt(4, b"HELLO")`, false},
+		{"偏移等于长度", `response.body.bcontainsAt(8, b"O")`, false},
+		{"偏移远超长度", `response.body.bcontainsAt(1000, b"H")`, false},
+		{"偏移接近上限", `response.body.bcontainsAt(9223372036854775807, b"H")`, false},
+		{"needle长于body", `response.body.bcontainsAt(0, b"\x16\x03\x01HELLO!")`, false},
+		{"空body", `b"".bcontainsAt(0, b"H")`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evalBool(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBase64DecodeBytes(t *testing.T) {
+	// 非UTF-8的二进制内容解码后应逐字节保留
+	raw := []byte{0x00, 0xff, 0xfe, 0x80, 0x1f, 0x8b, 0x08, 0x00, 0xc3, 0x28}
+	resp := &proto.Response{}
+	resp.SetBody(raw)
+	variables := map[string]any{"response": resp}
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`base64DecodeBytes("` + encoded + `") == response.body`, true},
+		{`base64DecodeBytes(b"` + encoded + `") == response.body`, true},
+		{`response.body.bcontains(base64DecodeBytes("` + encoded + `"))`, true},
+		{`size(base64DecodeBytes("` + encoded + `")) == 10`, true},
+		{`base64DecodeBytes("AP/+gA==") == b"\x00\xff\xfe\x80"`, true},
+		{`base64DecodeBytes("AP/+gA==") == b"\x00\xff\xfe"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			if got := evalBool(t, tt.expression, variables); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+
+	out := evalValue(t, `base64DecodeBytes("`+encoded+`")`, nil)
+	if got, ok := out.([]byte); !ok || !bytes.Equal(got, raw) {
+		t.Errorf("base64DecodeBytes 结果 = %v，期望 %v", out, raw)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"xfirefly/pkg/output"
 	"xfirefly/pkg/types"
 
 	"github.com/donnie4w/go-logger/logger"
@@ -27,25 +28,80 @@ func NewCmdOptions() (*types.CmdOptionsType, error) {
 	// 定义命令行参数
 	flagset.StringSliceVarP(&options.Target, "url", "u", []string{}, "扫描目标: 可以为URL/IP/域名/Host:Port等多种形式的混合输入")
 	flagset.StringVarP(&options.TargetsList, "list", "l", "", "目标文件: 指定含有扫描目标的文本文件")
+	flagset.StringVar(&options.InputJSON, "input-json", "", "从历史JSON输出结果文件中读取url字段作为扫描目标，用于重新扫描")
+	flagset.StringVar(&options.PathPrefix, "path-prefix", "", "协议识别后追加到每个目标的路径，如 /api/v1/status，便于直接使用裸主机列表")
+	flagset.BoolVar(&options.RandomizeTargets, "randomize-targets", false, "打乱目标扫描顺序，避免按顺序连续请求同一网段触发限流")
+	flagset.Int64Var(&options.Seed, "seed", 0, "打乱目标顺序使用的随机种子，相同种子得到相同顺序，0表示随机生成")
+	flagset.StringSliceVar(&options.ExcludeExtensions, "exclude-extensions", []string{}, "跳过URL路径为指定扩展名的目标，如: js,css,png,jpg")
+	flagset.IntVar(&options.ChunkSize, "chunk-size", 0, "分批扫描的每批目标数，批次间刷新输出并清理缓存，0表示不分批")
+	flagset.IntVar(&options.MaxTargets, "max-targets", 0, "最大目标数量，超过时报错，0表示不限制")
+	flagset.BoolVar(&options.TruncateTargets, "max-targets-truncate", false, "目标数量超过--max-targets时截断并告警，而不是报错退出")
 	flagset.StringVarP(&options.Output, "output", "o", "", "结果输出: 指定保存结果的文件路径（txt/csv，根据扩展名自动识别；也可配合 --json 输出JSON）")
 	flagset.BoolVar(&options.JSONOutput, "json", false, "使用JSON格式输出结果到文件")
+	flagset.StringVar(&options.OutputEncoding, "output-encoding", "utf8", "txt/csv输出文件的字符编码，支持 utf8、gbk（utf8编码的CSV文件带BOM）")
+	flagset.IntVar(&options.TitleMaxLen, "title-max-len", 200, "标题最大长度（字符数），超出部分截断，0表示不限制")
+	flagset.BoolVar(&options.KeepRaw, "keep-raw", false, "结果输出后保留匹配的请求/响应数据（默认释放以降低内存占用）")
+	flagset.BoolVar(&options.OutputIncludeRequest, "output-include-request", false, "JSON输出（--json/--sock）中附带匹配指纹的原始请求，便于复现")
+	flagset.BoolVar(&options.OutputIncludeResponse, "output-include-response", false, "JSON输出（--json/--sock）中附带匹配指纹的原始响应")
+	flagset.StringSliceVar(&options.RedactHeaders, "redact-headers", output.DefaultRedactHeaders, "输出结果中掩码值的敏感请求/响应头，传入空字符串时不掩码")
+	flagset.BoolVar(&options.OutputGzip, "output-gzip", false, "以gzip压缩格式写入输出文件，文件名自动追加.gz扩展名")
+	flagset.IntVar(&options.OutputFlushInterval, "output-flush-interval", 0, "每隔指定秒数将输出文件刷新到磁盘，避免长时间运行中异常退出丢失结果，0表示不定期刷新")
+	flagset.BoolVar(&options.OutputDedup, "output-dedup", false, "丢弃同一次运行中序列化后完全相同的输出记录")
+	flagset.BoolVar(&options.OutputAppendID, "output-append-id", false, "每条输出记录附带本次运行ID，便于合并多次扫描结果后区分来源")
+	flagset.StringVar(&options.OutputTemplate, "output-template", "", "自定义控制台输出模板(Go text/template)，如 '{{.URL}} {{.Title}} {{fingers .}}'")
 	flagset.StringVar(&options.SockOutput, "sock", "", "结果输出: 输出socket文件")
+	flagset.StringVar(&options.RequestLog, "request-log", "", "请求审计日志: 记录每个发出的HTTP请求（时间、方法、URL、状态码）")
 	flagset.StringVarP(&options.Proxy, "proxy", "p", "", "HTTP客户端代理: [http|https|socks5://][username[:password]@]host[:port]")
+	flagset.IntVar(&options.DNSCacheTTL, "dns-cache", 0, "缓存DNS解析结果的秒数，同一主机在有效期内只解析一次，0表示不缓存")
+	flagset.BoolVar(&options.EnvProxy, "env-proxy", false, "未指定--proxy时使用HTTP_PROXY/HTTPS_PROXY/NO_PROXY环境变量中的代理")
+	flagset.StringVar(&options.ProxyTest, "proxy-test", "", "启动时额外通过代理请求该地址验证代理可用性，如 http://example.com，为空时仅检测代理端口可连接（默认）")
+	flagset.BoolVar(&options.IgnoreProxyErrors, "ignore-proxy-errors", false, "代理地址无效或无法连接时仅告警并继续扫描")
+	flagset.StringVar(&options.SpoofIP, "spoof-ip", "off", "在X-Forwarded-For中携带随机来源IP，支持 off、ipv4、ipv6、mixed（以IPv4为主，偶尔使用IPv6）")
+	flagset.BoolVar(&options.ProxyFallback, "proxy-fallback", false, "经代理请求失败时直连重试一次")
+	flagset.StringSliceVar(&options.NoProxy, "no-proxy", []string{}, "不走代理的主机列表，支持域名后缀与CIDR，如: localhost,.corp.com,10.0.0.0/8")
+	flagset.StringVar(&options.MinTLS, "min-tls", "1.0", "HTTP请求允许的最低TLS版本: 1.0|1.1|1.2|1.3")
+	flagset.BoolVar(&options.VerifyTLS, "verify-tls", false, "校验目标站点的TLS证书，默认不校验")
+	flagset.BoolVar(&options.VerifyProxyTLS, "verify-proxy-tls", false, "校验HTTPS代理的TLS证书，默认不校验，与目标站点的证书校验相互独立")
+	flagset.BoolVar(&options.TLSDefaultCiphers, "tls-default-ciphers", false, "使用Go默认的TLS加密套件，而不是内置的兼容性套件列表")
+	flagset.BoolVar(&options.TLSProbe, "tls-probe", false, "探测HTTPS目标接受的TLS1.0/1.1/1.2/1.3协议版本，标记弱协议暴露")
 	flagset.IntVarP(&options.Threads, "threads", "t", 5, "URL并发线程数")
 	flagset.IntVar(&options.RuleThreads, "rule-threads", 200, "指纹规则并发线程数")
 	flagset.IntVar(&options.Timeout, "timeout", 5, "读超时: 从连接中读取数据的最大耗时")
+	flagset.Float64Var(&options.HTTPSTimeoutFactor, "timeout-multiplier-for-https", 1, "https目标的超时倍数，用于补偿TLS握手耗时，如 1.5 表示https目标使用1.5倍超时")
+	flagset.IntVar(&options.FingerprintTimeout, "fingerprint-timeout", 0, "单个指纹评估的最长耗时（秒），超时后放弃该指纹，0表示不限制")
 	flagset.IntVar(&options.Retries, "retries", 2, "请求失败重试次数")
 	flagset.IntVar(&options.MaxRedirects, "max-redirects", 5, "最大允许 HTTP 请求跳转次数")
+	flagset.IntVar(&options.ProbeRetries, "probe-retries", 3, "基础信息探测失败重试次数，0表示不重试，独立于指纹规则请求的重试次数")
+	flagset.StringVar(&options.ProbeMethod, "probe-method", "GET", "基础信息探测使用的请求方法，如 GET/HEAD/POST")
+	flagset.BoolVar(&options.RetryOnEmptyBody, "retry-on-empty-body", false, "基础信息探测返回200且响应体为空时重试一次")
 	flagset.BoolVar(&options.Debug, "debug", false, "调试：打印debug日志")
 	flagset.BoolVar(&options.NoTimestamp, "no-timestamp", false, "不显示时间戳")
 	flagset.BoolVar(&options.FileLog, "file-log", false, "保存日志到文件")
-	flagset.StringVar(&options.FingerOptions.FingerPath, "finger-path", "", "指纹路径")
+	flagset.StringVar(&options.FingerOptions.FingerPath, "finger-path", "", "指纹路径，支持通配符，如 'rules/**/apache-*.yaml'")
 	flagset.StringSliceVarP(&options.FingerOptions.FingerYaml, "finger", "f", []string{}, "指纹文件")
 	flagset.BoolVarP(&options.Active, "active", "a", false, "启用主动指纹探测")
+	flagset.BoolVar(&options.Ordered, "ordered", false, "按输入顺序输出结果（会缓存已完成但未轮到输出的结果）")
+	flagset.BoolVar(&options.ExcludeCDN, "exclude-cdn", false, "跳过CDN后的目标，仅记录基础信息")
+	flagset.BoolVar(&options.StopAtFirstMatch, "stop-at-first-match", false, "目标命中首个指纹后停止评估剩余指纹，适用于只需确认是否存在任一指纹的场景")
+	flagset.BoolVar(&options.HeaderOnlyMatch, "header-only-match", false, "仅依赖首页响应头/标题的指纹直接基于基础信息评估，不进入规则池")
+	flagset.BoolVar(&options.RawMode, "raw-mode", false, "指纹规则的HTTP请求统一通过rawhttp发送，请求头按规则书写顺序与大小写原样发送（仅支持 http 与 socks5 代理）")
+	flagset.BoolVar(&options.TryWWW, "try-www", false, "目标域名解析不存在时，改用www/非www形式（如 example.com 与 www.example.com）重试一次")
+	flagset.BoolVar(&options.IgnoreBody, "ignore-body", false, "基础信息探测不读取响应体以节省带宽，仅基于状态码与响应头识别（自动启用--header-only-match，标题为空）")
+	flagset.StringVar(&options.CacheDir, "cache-dir", "", "基础信息持久化缓存目录，重复扫描相同目标时在24小时内复用已获取的首页响应")
+	flagset.BoolVar(&options.Wappalyzer, "wappalyzer", true, "启用Wappalyzer站点技术识别，仅需指纹规则结果时可使用--wappalyzer=false降低CPU占用")
+	flagset.BoolVar(&options.NoFavicon, "no-favicon", false, "禁用favicon抓取与hash计算，减少请求数量")
+	flagset.BoolVar(&options.AllIcons, "all-icons", false, "计算页面中全部候选icon的hash写入response.icon_hashes，每个候选icon都会发起一次请求")
+	flagset.StringVar(&options.IconURL, "icon-url", "", "手动指定icon地址并直接计算hash，跳过页面icon解析，支持完整URL或以/开头的路径（拼接到每个目标）")
+	flagset.StringSliceVar(&options.FaviconPaths, "favicon-paths", []string{}, "页面图标与/favicon.ico均未获取到hash时依次尝试的备用路径，如 /favicon.png,/static/favicon.ico,/assets/logo.png")
 	flagset.BoolVar(&options.InitConfig, "init-config", false, "初始化配置文件")
-	flagset.BoolVar(&options.PrintPreset, "print", false, "打印所有预置配置")
+	flagset.BoolVar(&options.PrintPreset, "print", false, "打印所有预置配置（可配合 --json 输出JSON）")
+	flagset.BoolVar(&options.ListFingers, "list-fingers", false, "打印当前加载的指纹信息（可配合 --json 输出JSON）")
+	flagset.StringVar(&options.ReplayRequest, "replay-request", "", "规则调试：将文件中的原始请求发送到-u指定的目标，打印请求与响应后退出")
+	flagset.StringVar(&options.ReplayExpr, "replay-expr", "", "配合--replay-request使用，对重放的请求与响应评估CEL表达式，如 'response.status == 200'")
 	flagset.StringVarP(&options.Config, "config", "c", "config.yaml", "配置文件路径")
 	flagset.BoolVarP(&options.Version, "version", "v", false, "查看版本信息")
+	flagset.StringVar(&options.Serve, "serve", "", "以HTTP服务模式运行并监听指定地址，如 :8080，通过 POST /scan 提交扫描目标，提供 /healthz 与 /metrics")
+	flagset.StringVar(&options.Passive, "passive", "", "被动识别模式：读取指定目录中的HAR文件或原始请求响应转储执行指纹识别，不发起任何网络请求")
 
 	// 禁止自动排序参数
 	flagset.SortFlags = false
@@ -60,6 +116,8 @@ func NewCmdOptions() (*types.CmdOptionsType, error) {
 		fmt.Println()
 		fmt.Println("示例:")
 		fmt.Println("  ", os.Args[0], "-t http://test.com")
+		fmt.Println("   cat urls.txt |", os.Args[0])
+		fmt.Println("  ", os.Args[0], "--serve :8080")
 	}
 
 	// 解析命令行参数
@@ -79,23 +137,60 @@ func verifyOptions(opt *types.CmdOptionsType) error {
 	//optionsStr := fmt.Sprintf("%+v", *opt)
 	//fmt.Println("命令行选项：", optionsStr)
 	// 验证版本输入、初始化配置、打印内置配置参数
-	if opt.Version || opt.InitConfig || opt.PrintPreset {
+	if opt.Version || opt.InitConfig || opt.PrintPreset || opt.ListFingers {
 		return nil
 	}
 
-	// 验证目标输入
-	if len(opt.Target) == 0 && opt.TargetsList == "" {
-		return fmt.Errorf("必须使用`-u`或`-l`参数指定扫描目标")
+	// 目标输入来源只能指定一种，避免其中一种被静默忽略
+	var inputs []string
+	if len(opt.Target) > 0 {
+		inputs = append(inputs, "`-u`")
+	}
+	if opt.TargetsList != "" {
+		inputs = append(inputs, "`-l`")
+	}
+	if opt.InputJSON != "" {
+		inputs = append(inputs, "`--input-json`")
+	}
+	if len(inputs) > 1 {
+		return fmt.Errorf("%s 参数不能同时使用，请只指定一种目标输入", strings.Join(inputs, "、"))
+	}
+
+	// 验证目标输入，未指定时尝试从管道读取，服务模式下目标由请求传入，被动模式下目标来自捕获数据
+	if len(opt.Target) == 0 && opt.TargetsList == "" && opt.InputJSON == "" && opt.Serve == "" && opt.Passive == "" {
+		if !hasStdinInput() {
+			return fmt.Errorf("必须使用`-u`、`-l`或`--input-json`参数指定扫描目标，或通过管道传入目标")
+		}
+		opt.StdinInput = true
 	}
 
 	// 验证输出文件格式
 	if opt.Output != "" && !opt.JSONOutput { // 如果启用了JSON格式输出，则不检查文件扩展名
-		ext := strings.ToLower(filepath.Ext(opt.Output))
+		ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(opt.Output), ".gz")))
 		if ext != ".txt" && ext != ".csv" {
 			return fmt.Errorf("输出文件格式仅支持.txt或.csv，也可以使用-json参数启用JSON格式输出")
 		}
 	}
 
+	// 验证目标数量上限
+	if opt.MaxTargets < 0 {
+		logger.Warn("指定最大目标数量不合法，将不限制目标数量")
+		opt.MaxTargets = 0
+	}
+
+	// 验证分批大小
+	if opt.ChunkSize < 0 {
+		logger.Warn("指定分批大小不合法，将不分批扫描")
+		opt.ChunkSize = 0
+	}
+
+	// 验证输出模板
+	if opt.OutputTemplate != "" {
+		if _, err := output.ParseOutputTemplate(opt.OutputTemplate); err != nil {
+			return fmt.Errorf("输出模板解析失败: %v", err)
+		}
+	}
+
 	// 验证socket文件扩展名
 	if opt.SockOutput != "" {
 		ext := strings.ToLower(filepath.Ext(opt.SockOutput))
@@ -131,6 +226,15 @@ func verifyOptions(opt *types.CmdOptionsType) error {
 		opt.Retries = 1
 	}
 
+	// 基础信息探测请求方法
+	opt.ProbeMethod = strings.ToUpper(strings.TrimSpace(opt.ProbeMethod))
+	if opt.ProbeMethod == "" {
+		opt.ProbeMethod = "GET"
+	}
+	if opt.ProbeMethod == "HEAD" {
+		logger.Warn("探测请求方法为HEAD时无法获取响应体，标题与站点技术识别结果可能缺失")
+	}
+
 	// 最大跳转次数
 	if opt.MaxRedirects < 0 {
 		logger.Warn("指定最大跳转次数不合法，将使用默认值5")
@@ -139,3 +243,12 @@ func verifyOptions(opt *types.CmdOptionsType) error {
 
 	return nil
 }
+
+// hasStdinInput 判断标准输入是否为管道或重定向文件（非终端）
+func hasStdinInput() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice == 0
+}
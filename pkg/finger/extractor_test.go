@@ -0,0 +1,114 @@
+package finger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"xfirefly/pkg/cel"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestExtractorExtract(t *testing.T) {
+	resp := &proto.Response{
+		Headers: map[string]string{"server": "nginx/1.20.1", "x-powered-by": "PHP/7.4"},
+		Body:    []byte(`<meta name="generator" content="WordPress 6.2">`),
+	}
+
+	tests := []struct {
+		name      string
+		extractor Extractor
+		want      map[string]string
+	}{
+		{"正则取分组", Extractor{Type: "regex", Name: "version", Regex: []string{`WordPress ([\d.]+)`}, Group: 1}, map[string]string{"version": "6.2"}},
+		{"正则默认取整个匹配", Extractor{Type: "regex", Name: "version", Regex: []string{`WordPress [\d.]+`}}, map[string]string{"version": "WordPress 6.2"}},
+		{"名称为空时使用默认名称", Extractor{Type: "regex", Regex: []string{`[\d.]+`}}, map[string]string{"r0_0": "6.2"}},
+		{"按顺序取第一个命中的正则", Extractor{Type: "regex", Name: "v", Regex: []string{`Joomla`, `nginx/([\d.]+)`}, Part: "header", Group: 1}, map[string]string{"v": "1.20.1"}},
+		{"分组不存在", Extractor{Type: "regex", Name: "v", Regex: []string{`WordPress`}, Group: 1}, map[string]string{}},
+		{"kval 下划线等同于连字符", Extractor{Type: "kval", Kval: []string{"X_Powered_By"}}, map[string]string{"X_Powered_By": "PHP/7.4"}},
+		{"kval 单个响应头使用名称", Extractor{Type: "kval", Name: "server", Kval: []string{"Server"}}, map[string]string{"server": "nginx/1.20.1"}},
+		{"kval 多个响应头使用响应头名称", Extractor{Type: "kval", Name: "ignored", Kval: []string{"server", "x_powered_by", "via"}}, map[string]string{"server": "nginx/1.20.1", "x_powered_by": "PHP/7.4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.extractor.Extract(resp, "r0_0")
+			if err != nil {
+				t.Fatalf("Extract 返回错误: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Extract = %v，期望 %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("Extract[%s] = %q，期望 %q", k, got[k], v)
+				}
+			}
+		})
+	}
+
+	for _, e := range []Extractor{{Type: "regex", Regex: []string{"("}}, {Type: "json"}} {
+		if _, err := e.Extract(resp, "r0_0"); err == nil {
+			t.Errorf("提取器 %+v 应返回错误", e)
+		}
+	}
+}
+
+func TestRunExtractors(t *testing.T) {
+	variableMap := map[string]any{"response": &proto.Response{Body: []byte("build 1024")}}
+	customLib := cel.NewCustomLib()
+	extractors := []Extractor{
+		{Type: "regex", Name: "build", Regex: []string{`build (\d+)`}, Group: 1},
+		{Type: "json"}, // 执行失败的提取器被跳过
+	}
+
+	results := RunExtractors("r0", extractors, variableMap, customLib)
+	if len(results) != 1 || results["build"] != "1024" {
+		t.Fatalf("RunExtractors = %v，期望 build=1024", results)
+	}
+	// 提取结果可在后续规则的表达式中引用
+	out, err := customLib.Evaluate(`build == "1024"`, variableMap)
+	if err != nil || out.Value() != true {
+		t.Errorf("表达式引用提取结果 = %v（%v），期望 true", out, err)
+	}
+}
+
+func TestReadExtractors(t *testing.T) {
+	content := `
+id: extractor-finger
+info:
+  name: extractor-finger
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200
+    extractors:
+      - type: regex
+        name: version
+        group: 1
+        regex:
+          - 'WordPress ([\d.]+)'
+      - type: kval
+        kval:
+          - server
+expression: r0()
+`
+	path := filepath.Join(t.TempDir(), "extractor.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	extractors := f.Rules[0].Value.Extractors
+	if len(extractors) != 2 {
+		t.Fatalf("提取器数量 = %d，期望 2", len(extractors))
+	}
+	if e := extractors[0]; e.Type != "regex" || e.Name != "version" || e.Group != 1 || len(e.Regex) != 1 || e.Regex[0] != `WordPress ([\d.]+)` {
+		t.Errorf("第一个提取器 = %+v", e)
+	}
+	if e := extractors[1]; e.Type != "kval" || len(e.Kval) != 1 || e.Kval[0] != "server" {
+		t.Errorf("第二个提取器 = %+v", e)
+	}
+}
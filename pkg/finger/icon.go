@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"xfirefly/pkg/network"
 	"xfirefly/pkg/utils/common"
@@ -19,6 +20,87 @@ import (
 	_ "github.com/vmihailenco/msgpack/v5"
 )
 
+// faviconDisabled 是否禁用favicon抓取与hash计算
+var faviconDisabled bool
+
+// SetFaviconDisabled 设置是否禁用favicon抓取，禁用后 response.icon_hash 为空
+func SetFaviconDisabled(disabled bool) {
+	faviconDisabled = disabled
+}
+
+// IsFaviconDisabled 是否已禁用favicon抓取
+func IsFaviconDisabled() bool {
+	return faviconDisabled
+}
+
+// iconURLOverride 手动指定的icon地址，完整URL对所有目标生效，以 / 开头时拼接到各目标站点根目录
+var iconURLOverride string
+
+// fixedIconOnce 手动指定完整icon URL时其hash对所有目标相同，只计算一次
+var (
+	fixedIconOnce sync.Once
+	fixedIconHash string
+)
+
+// SetIconURLOverride 设置手动指定的icon地址，设置后跳过HTML中的icon解析
+func SetIconURLOverride(iconURL string) {
+	iconURLOverride = strings.TrimSpace(iconURL)
+	fixedIconOnce = sync.Once{}
+	fixedIconHash = ""
+}
+
+// overrideIconHash 计算手动指定icon地址的hash，完整URL只请求一次，站点路径按目标分别计算
+func overrideIconHash(iconURL string, proxy string) string {
+	if strings.HasPrefix(iconURLOverride, "/") {
+		return NewGetIconHash(iconURL, proxy).hashIconURL()
+	}
+	fixedIconOnce.Do(func() {
+		fixedIconHash = NewGetIconHash(iconURL, proxy).hashIconURL()
+	})
+	return fixedIconHash
+}
+
+// overrideIconURL 根据手动指定的icon地址获取目标实际使用的icon URL，未指定时返回空
+func overrideIconURL(pageURL string) string {
+	if iconURLOverride == "" {
+		return ""
+	}
+	if !strings.HasPrefix(iconURLOverride, "/") {
+		return iconURLOverride
+	}
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil || parsedURL.Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, iconURLOverride)
+}
+
+// allIconHashes 是否计算页面中全部候选icon的hash
+var allIconHashes bool
+
+// SetAllIconHashes 设置是否计算页面中全部候选icon的hash，开启后每个候选icon都会发起一次请求
+func SetAllIconHashes(enabled bool) {
+	allIconHashes = enabled
+}
+
+// faviconFallbackPaths 页面图标与 /favicon.ico 均未获取到hash时依次尝试的备用路径
+var faviconFallbackPaths []string
+
+// SetFaviconFallbackPaths 设置备用favicon路径列表，如 /favicon.png、/static/favicon.ico
+func SetFaviconFallbackPaths(paths []string) {
+	faviconFallbackPaths = faviconFallbackPaths[:0]
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		faviconFallbackPaths = append(faviconFallbackPaths, p)
+	}
+}
+
 // GetIconHash 获取icon hash
 type GetIconHash struct {
 	iconURL    string            // 目标图标URL
@@ -55,14 +137,20 @@ func NewGetIconHash(iconURL string, proxy string, retries ...int) *GetIconHash {
 // getDefaultIconURL 获取默认的icon URL
 // return: http://xxx.com/favicon.ico
 func (g *GetIconHash) getDefaultIconURL(iconURL string) string {
+	return g.getSiteIconURL(iconURL, "/favicon.ico")
+}
+
+// getSiteIconURL 获取站点根目录下指定路径的icon URL
+// return: http://xxx.com/favicon.png
+func (g *GetIconHash) getSiteIconURL(iconURL string, iconPath string) string {
 	if iconURL == "" {
 		return ""
 	}
 	parsedURL, err := url.Parse(iconURL)
-	if err != nil {
+	if err != nil || parsedURL.Host == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s://%s/favicon.ico", parsedURL.Scheme, parsedURL.Host)
+	return fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, iconPath)
 }
 
 // getIconHash
@@ -150,19 +238,61 @@ func (g *GetIconHash) hashHTTPURL(iconURL string) int32 {
 	return 0
 }
 
+// hashIconURL 仅计算指定icon地址的hash，不尝试默认与备用路径
+func (g *GetIconHash) hashIconURL() string {
+	return fmt.Sprintf("%d", g.getIconHash(g.iconURL))
+}
+
+// RunAll 计算其余候选icon的hash，firstHash 为 Run 已得到的首个icon hash（可能来自默认或备用路径），
+// 排在结果首位且不再重复请求，跳过获取失败与重复的hash，结果按候选顺序排列
+func (g *GetIconHash) RunAll(firstHash string, iconURLs []string) []string {
+	hashes := make([]string, 0, len(iconURLs)+1)
+	seen := make(map[string]struct{}, len(iconURLs)+1)
+	if firstHash != "" && firstHash != "0" {
+		hashes = append(hashes, firstHash)
+		seen[firstHash] = struct{}{}
+	}
+	for _, iconURL := range iconURLs {
+		hash := g.getIconHash(iconURL)
+		if hash == 0 {
+			continue
+		}
+		hashStr := fmt.Sprintf("%d", hash)
+		if _, ok := seen[hashStr]; ok {
+			continue
+		}
+		seen[hashStr] = struct{}{}
+		logger.Debugf("icon %s 的hash：%s", iconURL, hashStr)
+		hashes = append(hashes, hashStr)
+	}
+	return hashes
+}
+
 // Run 运行获取icon hash的流程
 func (g *GetIconHash) Run() string {
 	var hash int32
 	if g.iconURL != "" {
 		hash = g.getIconHash(g.iconURL)
 	}
+	defaultURL := g.getDefaultIconURL(g.iconURL)
 	if hash == 0 {
 		// 浏览器访问会发送一个默认的icon请求
-		defaultURL := g.getDefaultIconURL(g.iconURL)
-		if defaultURL != "" {
+		if defaultURL != "" && defaultURL != g.iconURL {
 			hash = g.getIconHash(defaultURL)
 		}
 	}
+	// 依次尝试备用路径，跳过已请求过的地址
+	for _, p := range faviconFallbackPaths {
+		if hash != 0 {
+			break
+		}
+		fallbackURL := g.getSiteIconURL(g.iconURL, p)
+		if fallbackURL == "" || fallbackURL == g.iconURL || fallbackURL == defaultURL {
+			continue
+		}
+		logger.Debugf("尝试备用icon地址: %s", fallbackURL)
+		hash = g.getIconHash(fallbackURL)
+	}
 	return fmt.Sprintf("%d", hash)
 }
 
@@ -173,10 +303,23 @@ func (g *GetIconHash) Run() string {
 //	@param html HTML内容(有最大限制512KB)
 //	@return string icon的url地址
 func GetIconURL(pageURL string, html string) string {
+	iconURLs := GetIconURLs(pageURL, html)
+	if len(iconURLs) == 0 {
+		return ""
+	}
+	return iconURLs[0]
+}
+
+// GetIconURLs 获取页面中所有候选icon的url地址，按优先级排序，未找到时仅包含默认favicon
+//
+//	@param pageURL 请求页面的URL(用于拼接最终的URL)
+//	@param html HTML内容(有最大限制512KB)
+//	@return []string 候选icon的url地址
+func GetIconURLs(pageURL string, html string) []string {
 	parsedURL, err := url.Parse(pageURL)
 	if err != nil {
 		logger.Errorf("URL解析错误: %s", err)
-		return ""
+		return nil
 	}
 
 	baseURL := fmt.Sprintf("%s://%s/", parsedURL.Scheme, parsedURL.Host)
@@ -345,20 +488,22 @@ func GetIconURL(pageURL string, html string) string {
 		candidateIcons = append(candidateIcons, iconMap[cleaned])
 	}
 
+	var iconURLs []string
 	for _, iconPath := range candidateIcons {
 		absoluteURL := buildAbsoluteURL(parsedURL, baseURL, basePath, iconPath)
 		if absoluteURL != "" {
 			normalized := normalizeFaviconURL(absoluteURL)
 			logger.Debug(fmt.Sprintf("找到可能的icon url: %s", normalized))
-			return normalized
-
+			iconURLs = append(iconURLs, normalized)
 		}
 	}
 
 	// 如果没有找到有效的图标，返回默认favicon
-	defaultURL := normalizeFaviconURL(faviconURL)
+	if len(iconURLs) == 0 {
+		iconURLs = append(iconURLs, normalizeFaviconURL(faviconURL))
+	}
 
-	return defaultURL
+	return iconURLs
 }
 
 // buildAbsoluteURL 构建绝对URL
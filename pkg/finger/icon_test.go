@@ -0,0 +1,222 @@
+package finger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"xfirefly/pkg/cel"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestRunAllReusesFirstHash(t *testing.T) {
+	var firstHits int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a.png", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&firstHits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("first icon"))
+	})
+	mux.HandleFunc("/b.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("second icon"))
+	})
+	mux.HandleFunc("/c.png", func(w http.ResponseWriter, r *http.Request) {
+		// 与首个icon内容相同，hash去重
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("first icon"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	urls := []string{srv.URL + "/a.png", srv.URL + "/b.png", srv.URL + "/c.png", srv.URL + "/missing.png"}
+	getter := NewGetIconHash(urls[0], "")
+	first := getter.Run()
+	if first == "" || first == "0" {
+		t.Fatalf("首个icon hash 获取失败: %q", first)
+	}
+
+	hashes := getter.RunAll(first, urls[1:])
+	if len(hashes) != 2 || hashes[0] != first {
+		t.Fatalf("hashes = %v，期望以 %s 开头的2个hash", hashes, first)
+	}
+	if hits := atomic.LoadInt64(&firstHits); hits != 1 {
+		t.Errorf("首个icon被请求 %d 次，期望 1 次", hits)
+	}
+}
+
+func TestRunAllIncludesFallbackHash(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/x-icon")
+		_, _ = w.Write([]byte("default icon"))
+	})
+	mux.HandleFunc("/b.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("second icon"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// 首个候选icon不存在时 Run 回退到 /favicon.ico，该hash也应出现在结果中
+	urls := []string{srv.URL + "/missing.png", srv.URL + "/b.png"}
+	getter := NewGetIconHash(urls[0], "")
+	first := getter.Run()
+	hashes := getter.RunAll(first, urls[1:])
+	if len(hashes) != 2 || hashes[0] != first {
+		t.Fatalf("hashes = %v，期望包含回退得到的 %s 与第二个icon的hash", hashes, first)
+	}
+}
+
+// newIconSite 创建首页引用 /favicon.ico 的站点，返回icon被请求次数的计数
+func newIconSite(t *testing.T) (*httptest.Server, *int64) {
+	t.Helper()
+	var iconHits int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><link rel="icon" href="/favicon.ico"></head><body>home</body></html>`))
+	})
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&iconHits, 1)
+		w.Header().Set("Content-Type", "image/x-icon")
+		_, _ = w.Write([]byte("site icon"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &iconHits
+}
+
+// requestHome 请求站点首页并返回构建的响应
+func requestHome(t *testing.T, target string) (*proto.Response, map[string]any) {
+	t.Helper()
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/"}}
+	variableMap, err := SendRequest(context.Background(), target, rule.Request, rule, map[string]any{}, "", 5)
+	if err != nil {
+		t.Fatalf("SendRequest 失败: %v", err)
+	}
+	return variableMap["response"].(*proto.Response), variableMap
+}
+
+func TestSetFaviconDisabled(t *testing.T) {
+	srv, iconHits := newIconSite(t)
+	defer SetFaviconDisabled(false)
+
+	SetFaviconDisabled(true)
+	if response, _ := requestHome(t, srv.URL); response.IconHash != "" || len(response.IconHashes) != 0 {
+		t.Errorf("禁用favicon后 icon_hash = %q，icon_hashes = %v，期望为空", response.IconHash, response.IconHashes)
+	}
+	if hits := atomic.LoadInt64(iconHits); hits != 0 {
+		t.Errorf("禁用favicon后icon被请求 %d 次，期望 0 次", hits)
+	}
+
+	SetFaviconDisabled(false)
+	if response, _ := requestHome(t, srv.URL); response.IconHash == "" || response.IconHash == "0" {
+		t.Errorf("启用favicon后 icon_hash = %q，期望计算出hash", response.IconHash)
+	}
+	if hits := atomic.LoadInt64(iconHits); hits != 1 {
+		t.Errorf("启用favicon后icon被请求 %d 次，期望 1 次", hits)
+	}
+}
+
+func TestResponseFaviconHash(t *testing.T) {
+	srv, _ := newIconSite(t)
+	response, variableMap := requestHome(t, srv.URL)
+	if response.IconHash == "" || response.IconHash == "0" {
+		t.Fatalf("icon_hash = %q，期望计算出hash", response.IconHash)
+	}
+	// favicon_hash 为 icon_hash 的整数形式
+	if got := strconv.FormatInt(response.FaviconHash, 10); got != response.IconHash {
+		t.Errorf("favicon_hash = %s，期望与 icon_hash %s 一致", got, response.IconHash)
+	}
+	expression := "response.favicon_hash == " + response.IconHash
+	out, err := cel.NewCustomLib().Evaluate(expression, variableMap)
+	if err != nil || out.Value() != true {
+		t.Errorf("表达式 %s 结果 = %v（%v），期望 true", expression, out, err)
+	}
+}
+
+func TestFaviconFallbackPaths(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path != "/static/logo.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("fallback icon"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer SetFaviconFallbackPaths(nil)
+
+	// 未配置备用路径时页面图标与 /favicon.ico 均不存在
+	if hash := NewGetIconHash(srv.URL+"/missing.png", "").Run(); hash != "0" {
+		t.Errorf("未配置备用路径时 hash = %s，期望 0", hash)
+	}
+
+	// 空路径被忽略，缺少前导 / 时自动补全，已请求过的 /favicon.ico 不再重复请求
+	SetFaviconFallbackPaths([]string{" ", "favicon.ico", "assets/none.png", "/static/logo.png", "/never.png"})
+	if got := strings.Join(faviconFallbackPaths, ","); got != "/favicon.ico,/assets/none.png,/static/logo.png,/never.png" {
+		t.Errorf("备用路径 = %s", got)
+	}
+	mu.Lock()
+	requested = nil
+	mu.Unlock()
+	if hash := NewGetIconHash(srv.URL+"/missing.png", "").Run(); hash == "0" || hash == "" {
+		t.Fatalf("备用路径 hash = %q，期望计算出hash", hash)
+	}
+	// 命中后不再尝试后续备用路径
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(requested, ","); got != "/missing.png,/favicon.ico,/assets/none.png,/static/logo.png" {
+		t.Errorf("请求的icon路径 = %s", got)
+	}
+}
+
+func TestSetIconURLOverride(t *testing.T) {
+	var iconHits int64
+	iconSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&iconHits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("override icon"))
+	}))
+	defer iconSrv.Close()
+	defer SetIconURLOverride("")
+
+	siteA, siteIconHits := newIconSite(t)
+	siteB, _ := newIconSite(t)
+	SetIconURLOverride(" " + iconSrv.URL + "/logo.png ")
+
+	// 完整URL跳过页面icon解析，hash对所有目标相同，只请求一次
+	first, _ := requestHome(t, siteA.URL)
+	second, _ := requestHome(t, siteB.URL)
+	if first.IconHash == "" || first.IconHash == "0" || second.IconHash != first.IconHash {
+		t.Errorf("icon_hash = %q 与 %q，期望为相同的指定icon hash", first.IconHash, second.IconHash)
+	}
+	if hits := atomic.LoadInt64(&iconHits); hits != 1 {
+		t.Errorf("指定icon被请求 %d 次，期望 1 次", hits)
+	}
+	if hits := atomic.LoadInt64(siteIconHits); hits != 0 {
+		t.Errorf("页面中的icon被请求 %d 次，期望 0 次", hits)
+	}
+
+	// 以 / 开头的路径拼接到各目标站点根目录
+	SetIconURLOverride("/favicon.ico")
+	response, _ := requestHome(t, siteA.URL)
+	if response.IconHash == "" || response.IconHash == "0" || response.IconHash == first.IconHash {
+		t.Errorf("icon_hash = %q，期望为站点 /favicon.ico 的hash", response.IconHash)
+	}
+	if hits := atomic.LoadInt64(siteIconHits); hits != 1 {
+		t.Errorf("站点icon被请求 %d 次，期望 1 次", hits)
+	}
+}
@@ -0,0 +1,212 @@
+package finger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"xfirefly/pkg/utils/proto"
+)
+
+// 匹配器类型
+const (
+	MatcherWord   = "word"
+	MatcherRegex  = "regex"
+	MatcherStatus = "status"
+)
+
+// Matcher 声明式匹配器，兼容 nuclei 模板中常用的 word/regex/status 写法，可替代CEL表达式
+type Matcher struct {
+	Type            string   `yaml:"type"`             // 匹配类型：word、regex、status
+	Part            string   `yaml:"part"`             // 匹配位置：body（默认）、header、all，status 类型忽略该字段
+	Words           []string `yaml:"words"`            // word 类型的关键字列表
+	Regex           []string `yaml:"regex"`            // regex 类型的正则列表
+	Status          []int    `yaml:"status"`           // status 类型的状态码列表
+	Condition       string   `yaml:"condition"`        // 多个关键字/正则/状态码之间的关系：or（默认）、and
+	CaseInsensitive bool     `yaml:"case-insensitive"` // word 类型是否忽略大小写
+	Negative        bool     `yaml:"negative"`         // 是否对匹配结果取反
+}
+
+// regexCache 已编译的匹配器正则，避免每次评估重复编译
+var regexCache sync.Map
+
+// compileMatcherRegex 编译并缓存正则表达式
+func compileMatcherRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
+
+// Validate 校验匹配器类型与正则表达式，便于在加载指纹时发现错误
+func (m Matcher) Validate() error {
+	switch strings.ToLower(m.Type) {
+	case MatcherStatus, MatcherWord:
+		return nil
+	case MatcherRegex:
+		for _, expr := range m.Regex {
+			if _, err := compileMatcherRegex(expr); err != nil {
+				return fmt.Errorf("正则 %s 编译失败: %v", expr, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的匹配器类型: %s", m.Type)
+	}
+}
+
+// HeaderOnly 匹配器是否只依赖响应状态码或响应头
+func (m Matcher) HeaderOnly() bool {
+	return strings.ToLower(m.Type) == MatcherStatus || strings.ToLower(m.Part) == "header"
+}
+
+// Match 对响应执行匹配
+func (m Matcher) Match(resp *proto.Response) (bool, error) {
+	if resp == nil {
+		return false, nil
+	}
+	and := strings.ToLower(m.Condition) == "and"
+
+	var matched bool
+	switch strings.ToLower(m.Type) {
+	case MatcherStatus:
+		matched = matchAll(len(m.Status), and, func(i int) (bool, error) {
+			return int32(m.Status[i]) == resp.Status, nil
+		})
+	case MatcherWord:
+		content := matcherPart(resp, m.Part)
+		if m.CaseInsensitive {
+			content = strings.ToLower(content)
+		}
+		matched = matchAll(len(m.Words), and, func(i int) (bool, error) {
+			word := m.Words[i]
+			if m.CaseInsensitive {
+				word = strings.ToLower(word)
+			}
+			return strings.Contains(content, word), nil
+		})
+	case MatcherRegex:
+		content := matcherPart(resp, m.Part)
+		var compileErr error
+		matched = matchAll(len(m.Regex), and, func(i int) (bool, error) {
+			re, err := compileMatcherRegex(m.Regex[i])
+			if err != nil {
+				compileErr = fmt.Errorf("正则 %s 编译失败: %v", m.Regex[i], err)
+				return false, compileErr
+			}
+			return re.MatchString(content), nil
+		})
+		if compileErr != nil {
+			return false, compileErr
+		}
+	default:
+		return false, fmt.Errorf("不支持的匹配器类型: %s", m.Type)
+	}
+
+	if m.Negative {
+		return !matched, nil
+	}
+	return matched, nil
+}
+
+// String 返回匹配器的简要描述，用于记录命中规则
+func (m Matcher) String() string {
+	var values []string
+	switch strings.ToLower(m.Type) {
+	case MatcherStatus:
+		for _, s := range m.Status {
+			values = append(values, fmt.Sprintf("%d", s))
+		}
+	case MatcherWord:
+		values = m.Words
+	case MatcherRegex:
+		values = m.Regex
+	}
+	desc := m.Type
+	if m.Part != "" && strings.ToLower(m.Type) != MatcherStatus {
+		desc += "(" + m.Part + ")"
+	}
+	if m.Negative {
+		desc = "!" + desc
+	}
+	return desc + " " + strings.Join(values, ", ")
+}
+
+// matchAll 按 and/or 关系依次判断，出错时视为不匹配并停止
+func matchAll(n int, and bool, fn func(i int) (bool, error)) bool {
+	if n == 0 {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		ok, err := fn(i)
+		if err != nil {
+			return false
+		}
+		if ok && !and {
+			return true
+		}
+		if !ok && and {
+			return false
+		}
+	}
+	return and
+}
+
+// matcherPart 获取匹配位置对应的响应内容
+func matcherPart(resp *proto.Response, part string) string {
+	switch strings.ToLower(part) {
+	case "header":
+		return responseHeaderText(resp)
+	case "all":
+		return responseHeaderText(resp) + "\r\n\r\n" + string(resp.Body)
+	default:
+		return string(resp.Body)
+	}
+}
+
+// responseHeaderText 获取原始响应头文本，缺失时按 "键: 值" 逐行拼接解析后的响应头
+func responseHeaderText(resp *proto.Response) string {
+	if len(resp.RawHeader) > 0 {
+		return string(resp.RawHeader)
+	}
+	keys := make([]string, 0, len(resp.Headers))
+	for k := range resp.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(": ")
+		b.WriteString(resp.Headers[k])
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// MatchRule 依次评估规则中的匹配器，matchersCondition 为 and 时需全部命中，默认任一命中即可
+func MatchRule(matchers []Matcher, matchersCondition string, resp *proto.Response) (bool, error) {
+	if len(matchers) == 0 {
+		return false, nil
+	}
+	and := strings.ToLower(matchersCondition) == "and"
+	for _, m := range matchers {
+		ok, err := m.Match(resp)
+		if err != nil {
+			return false, err
+		}
+		if ok && !and {
+			return true, nil
+		}
+		if !ok && and {
+			return false, nil
+		}
+	}
+	return and, nil
+}
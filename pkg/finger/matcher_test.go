@@ -0,0 +1,139 @@
+package finger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	resp := &proto.Response{
+		Status:  200,
+		Headers: map[string]string{"server": "nginx/1.20.1", "x-powered-by": "PHP/7.4"},
+		Body:    []byte("<title>Welcome to JBoss</title>"),
+	}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		want    bool
+	}{
+		{"状态码命中", Matcher{Type: "status", Status: []int{404, 200}}, true},
+		{"状态码未命中", Matcher{Type: "status", Status: []int{302}}, false},
+		{"关键字默认匹配响应体", Matcher{Type: "word", Words: []string{"JBoss"}}, true},
+		{"关键字区分大小写", Matcher{Type: "word", Words: []string{"jboss"}}, false},
+		{"关键字忽略大小写", Matcher{Type: "word", Words: []string{"jboss"}, CaseInsensitive: true}, true},
+		{"关键字 or 关系", Matcher{Type: "word", Words: []string{"Tomcat", "JBoss"}}, true},
+		{"关键字 and 关系", Matcher{Type: "word", Words: []string{"Tomcat", "JBoss"}, Condition: "and"}, false},
+		{"关键字匹配响应头", Matcher{Type: "word", Part: "header", Words: []string{"server: nginx"}}, true},
+		{"响应头中不含响应体", Matcher{Type: "word", Part: "header", Words: []string{"JBoss"}}, false},
+		{"关键字匹配全部响应", Matcher{Type: "word", Part: "all", Words: []string{"PHP/7.4", "JBoss"}, Condition: "and"}, true},
+		{"正则命中", Matcher{Type: "regex", Regex: []string{`Welcome to \w+`}}, true},
+		{"正则匹配响应头", Matcher{Type: "regex", Part: "header", Regex: []string{`nginx/1\.\d+`}}, true},
+		{"取反", Matcher{Type: "word", Words: []string{"Tomcat"}, Negative: true}, true},
+		{"列表为空不命中", Matcher{Type: "word"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.matcher.Match(resp)
+			if err != nil {
+				t.Fatalf("Match 返回错误: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s 匹配结果 = %v，期望 %v", tt.matcher, got, tt.want)
+			}
+		})
+	}
+
+	// 原始响应头优先于解析后的响应头
+	raw := &proto.Response{RawHeader: []byte("HTTP/1.1 200 OK\r\nSet-Cookie: JSESSIONID=1"), Headers: map[string]string{"server": "nginx"}}
+	if ok, _ := (Matcher{Type: "word", Part: "header", Words: []string{"JSESSIONID"}}).Match(raw); !ok {
+		t.Error("存在原始响应头时应匹配原始响应头")
+	}
+
+	for _, m := range []Matcher{{Type: "regex", Regex: []string{"("}}, {Type: "dsl"}} {
+		if _, err := m.Match(resp); err == nil {
+			t.Errorf("%s 应返回错误", m)
+		}
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	resp := &proto.Response{Status: 200, Body: []byte("Grafana")}
+	status := Matcher{Type: "status", Status: []int{200}}
+	word := Matcher{Type: "word", Words: []string{"Kibana"}}
+
+	tests := []struct {
+		name      string
+		matchers  []Matcher
+		condition string
+		want      bool
+	}{
+		{"无匹配器", nil, "", false},
+		{"默认任一命中", []Matcher{word, status}, "", true},
+		{"and 需全部命中", []Matcher{status, word}, "and", false},
+		{"and 全部命中", []Matcher{status, {Type: "word", Words: []string{"Grafana"}}}, "AND", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchRule(tt.matchers, tt.condition, resp)
+			if err != nil {
+				t.Fatalf("MatchRule 返回错误: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchRule = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMatchers(t *testing.T) {
+	const finger = `
+id: matcher-finger
+info:
+  name: matcher-finger
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    matchers-condition: and
+    matchers:
+      - type: status
+        status:
+          - 200
+      - type: %s
+        part: header
+        regex:
+          - '%s'
+expression: r0()
+`
+	write := func(matcherType, regex string) string {
+		path := filepath.Join(t.TempDir(), "matcher.yaml")
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(finger, matcherType, regex)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	f, err := Read(write("regex", `nginx/\d+`))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	rule := f.Rules[0].Value
+	if rule.MatchersCondition != "and" || len(rule.Matchers) != 2 {
+		t.Fatalf("matchers-condition = %q，匹配器数量 = %d，期望 and 与 2", rule.MatchersCondition, len(rule.Matchers))
+	}
+	if m := rule.Matchers[1]; m.Type != "regex" || m.Part != "header" || len(m.Regex) != 1 || m.Regex[0] != `nginx/\d+` {
+		t.Errorf("第二个匹配器 = %+v", m)
+	}
+
+	// 无效的正则或匹配器类型在加载时报错
+	for _, tt := range [][2]string{{"regex", "("}, {"dsl", "x"}} {
+		if _, err := Read(write(tt[0], tt[1])); err == nil {
+			t.Errorf("匹配器类型 %s、正则 %s 应加载失败", tt[0], tt[1])
+		}
+	}
+}
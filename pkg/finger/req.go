@@ -3,6 +3,7 @@ package finger
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"xfirefly/pkg/network"
 	"xfirefly/pkg/utils/common"
@@ -68,6 +69,12 @@ func buildProtoRequest(resp *http.Response, req RuleRequest) *proto.Request {
 	}
 
 	protoReq.Headers = headers
+	if cookies := resp.Request.Cookies(); len(cookies) > 0 {
+		protoReq.Cookies = make(map[string]string, len(cookies))
+		for _, c := range cookies {
+			protoReq.Cookies[c.Name] = c.Value
+		}
+	}
 	protoReq.Raw = []byte(fmt.Sprintf("%s %s %s\nHost: %s\n%s\n\n%s", req.Method, resp.Request.URL.Path, resp.Proto, resp.Request.URL.Host, strings.Trim(rawReqHeaderBuilder.String(), "\n"), req.Body))
 	protoReq.RawHeader = []byte(strings.Trim(rawReqHeaderBuilder.String(), "\n"))
 
@@ -89,29 +96,68 @@ func buildProtoResponse(resp *http.Response, utf8RespBody string, latency int64,
 		rawHeaderBuilder.WriteString(resp.Header.Get(k))
 		rawHeaderBuilder.WriteString("\n")
 	}
+	// Trailer 仅在响应体读取完毕后才会填充，gRPC 等协议在其中返回状态
+	trailers := make(map[string]string, len(resp.Trailer))
+	for k := range resp.Trailer {
+		trailers[strings.ToLower(k)] = resp.Trailer.Get(k)
+	}
 	// 仅在首页HTML且为GET请求时尝试解析/抓取favicon，避免在高并发下重复抓取导致内存与网络开销暴涨
 	var iconHashStr = ""
-	if resp.Request != nil && resp.Request.Method == http.MethodGet {
+	var iconHashes []string
+	if !faviconDisabled && resp.Request != nil && resp.Request.Method == http.MethodGet {
 		path := resp.Request.URL.Path
 		ct := resp.Header.Get("Content-Type")
-		if (path == "" || path == "/") && strings.Contains(strings.ToLower(ct), "text/html") {
-			iconUrl := GetIconURL(resp.Request.URL.String(), utf8RespBody)
-			logger.Debugf("提取到iconUrl为: %s", iconUrl)
-			iconHashStr = NewGetIconHash(iconUrl, proxy).Run()
-			logger.Debugf("icon hash：%s", iconHashStr)
+		if iconUrl := overrideIconURL(resp.Request.URL.String()); iconUrl != "" {
+			// 手动指定icon地址时跳过HTML解析，直接计算该地址的hash
+			if path == "" || path == "/" {
+				iconHashStr = overrideIconHash(iconUrl, proxy)
+				logger.Debugf("目标 %s 指定icon地址 %s 的hash：%s", resp.Request.URL.String(), iconUrl, iconHashStr)
+			}
+		} else if (path == "" || path == "/") && strings.Contains(strings.ToLower(ct), "text/html") {
+			iconUrls := GetIconURLs(resp.Request.URL.String(), utf8RespBody)
+			if len(iconUrls) > 0 {
+				logger.Debugf("提取到iconUrl为: %s", iconUrls[0])
+				getter := NewGetIconHash(iconUrls[0], proxy)
+				iconHashStr = getter.Run()
+				logger.Debugf("icon hash：%s", iconHashStr)
+				if allIconHashes && len(iconUrls) > 1 {
+					iconHashes = getter.RunAll(iconHashStr, iconUrls[1:])
+				}
+			}
 		}
 	}
-	return &proto.Response{
-		Status:      int32(resp.StatusCode),
-		Url:         network.Url2ProtoUrl(resp.Request.URL),
-		Headers:     headers,
-		ContentType: resp.Header.Get("Content-Type"),
-		Body:        []byte(utf8RespBody),
-		Raw:         []byte(fmt.Sprintf("%s\n\n%s", strings.Trim(rawHeaderBuilder.String(), "\n"), utf8RespBody)),
-		RawHeader:   []byte(strings.Trim(rawHeaderBuilder.String(), "\n")),
-		Latency:     latency,
-		IconHash:    iconHashStr,
+	// 未计算全部候选icon时，仅包含首个获取成功的hash
+	if len(iconHashes) == 0 && iconHashStr != "" && iconHashStr != "0" {
+		iconHashes = []string{iconHashStr}
+	}
+	// 整数形式的icon hash，便于规则中直接与数字比较
+	var faviconHash int64
+	if iconHashStr != "" {
+		faviconHash, _ = strconv.ParseInt(iconHashStr, 10, 64)
+	}
+	response := &proto.Response{
+		Status:        int32(resp.StatusCode),
+		Url:           network.Url2ProtoUrl(resp.Request.URL),
+		Headers:       headers,
+		ContentType:   resp.Header.Get("Content-Type"),
+		Raw:           []byte(fmt.Sprintf("%s\n\n%s", strings.Trim(rawHeaderBuilder.String(), "\n"), utf8RespBody)),
+		RawHeader:     []byte(strings.Trim(rawHeaderBuilder.String(), "\n")),
+		Latency:       latency,
+		IconHash:      iconHashStr,
+		IconHashes:    iconHashes,
+		Trailers:      trailers,
+		FaviconHash:   faviconHash,
+		ContentLength: network.ContentLength(resp),
+	}
+	// https 响应提供对端证书信息
+	if resp.TLS != nil {
+		response.Cert = network.CertVariables(resp.TLS.PeerCertificates)
 	}
+	// 转换得到的字节流为新分配的缓冲区，body 与 rawbody 直接共用，不再复制
+	body := []byte(utf8RespBody)
+	response.Body = body
+	response.Rawbody = body
+	return response
 }
 
 // BuildProtoRequest 构造proto.Request结构体 (公开版本)
@@ -1,27 +1,36 @@
 package finger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"net/http/httptrace"
+	"net/textproto"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 	"xfirefly/pkg/network"
 	"xfirefly/pkg/utils/common"
+	"xfirefly/pkg/utils/proto"
 
 	"github.com/donnie4w/go-logger/logger"
-	"golang.org/x/net/context"
 )
 
 var (
 	// 本地上限与 network.MaxDefaultBody 对齐，由调用方统一限制
 	maxDefaultBody int64 = 512 * 1024      // 512KB
 	defaultTimeout       = 5 * time.Second // 5秒
+	// udpProxyWarnOnce 配置代理时UDP规则被跳过的告警只输出一次
+	udpProxyWarnOnce sync.Once
 )
 
-// SendRequest yaml poc发送http请求
-func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[string]any, proxy string, timeout int) (map[string]any, error) {
+// SendRequest yaml poc发送http请求，ctx 取消或超时后正在进行的http请求随之中止
+func SendRequest(ctx context.Context, target string, req RuleRequest, rule Rule, variableMap map[string]any, proxy string, timeout int) (map[string]any, error) {
 
 	// 设置超时时间，如果传入的超时时间为0，则使用默认超时时间
 	timeoutDuration := time.Duration(timeout) * time.Second
@@ -37,7 +46,11 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		InsecureSkipVerify: true,                          // 忽略SSL证书错误
 		CustomHeaders:      map[string]string{},           // 创建自定义headers
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(parent, options.Timeout)
 	defer cancel() // 在读取完响应后取消
 
 	// 设置代理地址
@@ -52,8 +65,12 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		}
 	}
 
+	// 注入目标主机相关变量，便于规则在路径、请求体、请求头中引用
+	initialVars := targetVariables(target)
+	updateTargetVariables(variableMap, nil, initialVars)
+
 	// 获取规则中的请求路径并处理
-	newPath := formatPath(rule.Request.Path)
+	newPath := formatPath(SetVariableMap(rule.Request.Path, variableMap))
 
 	// 处理url
 	urlStr := common.ParseTarget(target, newPath)
@@ -63,12 +80,15 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 
 	// 处理自定义headers
 	for k, v := range rule.Request.Headers {
-		options.CustomHeaders[k] = v
+		options.CustomHeaders[k] = SetVariableMap(v, variableMap)
 	}
 
+	// 规则指定的cookie合并到Cookie请求头
+	applyRuleCookies(rule.Request.Cookies, options.CustomHeaders, variableMap)
+
 	// 判断请求方式
 	reqType := strings.ToLower(rule.Request.Type)
-	if len(reqType) > 0 && reqType != common.HttpType {
+	if len(reqType) > 0 && reqType != common.HttpType && reqType != common.Http2Type {
 		switch reqType {
 		case common.TcpType:
 			rule.Request.Host = SetVariableMap(rule.Request.Host, variableMap)
@@ -78,7 +98,7 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 			}
 			nc, err := network.NewTcpClient(rule.Request.Host, network.TcpOrUdpConfig{
 				Network:     rule.Request.Type,
-				ReadTimeout: time.Duration(rule.Request.ReadTimeout),
+				ReadTimeout: time.Duration(rule.Request.ReadTimeout) * time.Second,
 				ReadSize:    rule.Request.ReadSize,
 				MaxRetries:  1,
 				ProxyURL:    options.Proxy,
@@ -97,12 +117,15 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 					data = common.FromHex(data)
 				}
 			}
-			logger.Debugf("TCP发送数据：%s", data)
-			errs := nc.Send([]byte(data))
-			if errs != nil {
-				logger.Debugf("tcp send error：%s", errs.Error())
+			// 未配置发送内容时仅读取服务主动返回的banner
+			if len(data) > 0 {
+				logger.Debugf("TCP发送数据：%s", data)
+				errs := nc.Send([]byte(data))
+				if errs != nil {
+					logger.Debugf("tcp send error：%s", errs.Error())
+				}
 			}
-			res, err := nc.RecvTcp()
+			res, err := nc.ReadUntil([]byte(rule.Request.ReadUntil))
 			if err != nil {
 				logger.Debugf("tcp receive error：%s", err.Error())
 			}
@@ -120,13 +143,19 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 			}
 			nc, err := network.NewUdpClient(rule.Request.Host, network.TcpOrUdpConfig{
 				Network:     rule.Request.Type,
-				ReadTimeout: time.Duration(rule.Request.ReadTimeout),
+				ReadTimeout: time.Duration(rule.Request.ReadTimeout) * time.Second,
 				ReadSize:    rule.Request.ReadSize,
 				MaxRetries:  1,
 				ProxyURL:    options.Proxy,
 				IsLts:       info.IsLts,
 				ServerName:  info.Host,
 			})
+			if errors.Is(err, network.ErrUDPProxy) {
+				udpProxyWarnOnce.Do(func() {
+					logger.Warn("已配置代理，UDP规则无法经代理发送，相关规则将被跳过")
+				})
+				return nil, err
+			}
 			if err != nil {
 				logger.Debugf("udp error：%s", err.Error())
 				return nil, err
@@ -144,7 +173,7 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 				//fmt.Println("udp send error:", errs.Error())
 				logger.Errorf("udp send error: %s", errs.Error())
 			}
-			res, err := nc.RecvTcp()
+			res, err := nc.RecvUdp()
 			if err != nil {
 				//fmt.Println("udp receive error:", err.Error())
 				logger.Errorf("udp receive error: %s", err.Error())
@@ -156,6 +185,84 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 				logger.Errorf("udp or udp parse error: %s", err.Error())
 			}
 			return variableMap, nil
+		case common.SslType:
+			rule.Request.Host = SetVariableMap(rule.Request.Host, variableMap)
+			info, err := common.ParseAddress(rule.Request.Host)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing address: %v\n", err)
+			}
+			// ssl 默认使用 443 端口并强制进行TLS握手
+			address := info.Host
+			if info.Port != "" {
+				address = net.JoinHostPort(info.Host, info.Port)
+			}
+			// 握手与读写不超过 ctx 剩余时间，--fingerprint-timeout 可中止卡住的握手
+			dialTimeout := network.DefaultDialTimeout
+			readTimeout := time.Duration(rule.Request.ReadTimeout) * time.Second
+			if deadline, ok := ctx.Deadline(); ok {
+				remaining := time.Until(deadline)
+				if remaining <= 0 {
+					return nil, context.DeadlineExceeded
+				}
+				dialTimeout = min(dialTimeout, remaining)
+				if readTimeout > remaining {
+					readTimeout = remaining
+				}
+			}
+			nc, err := network.NewLtsTcpClient(address, network.TcpOrUdpConfig{
+				DialTimeout: dialTimeout,
+				ReadTimeout: readTimeout,
+				ReadSize:    rule.Request.ReadSize,
+				MaxRetries:  1,
+				ProxyURL:    options.Proxy,
+				ServerName:  info.Host,
+			})
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				if nc != nil {
+					_ = nc.Close()
+				}
+				return nil, ctxErr
+			}
+			if err != nil {
+				logger.Debugf("ssl error：%s", err.Error())
+				return nil, err
+			}
+			// ctx 结束时关闭连接，中断阻塞中的读写
+			stop := context.AfterFunc(ctx, func() { _ = nc.Close() })
+			data := rule.Request.Data
+
+			if len(rule.Request.DataType) > 0 {
+				dataType := strings.ToLower(rule.Request.DataType)
+				if dataType == "hex" {
+					data = common.FromHex(data)
+				}
+			}
+			// 仅在配置了发送内容时进行读写，否则只完成握手获取证书
+			var res []byte
+			if len(data) > 0 {
+				logger.Debugf("SSL发送数据：%s", data)
+				if errs := nc.SendLtsTcp([]byte(data)); errs != nil {
+					logger.Debugf("ssl send error：%s", errs.Error())
+				}
+				res, err = nc.RecvLtsTcp()
+				if err != nil {
+					logger.Debugf("ssl receive error：%s", err.Error())
+				}
+			}
+			stop()
+			certs := nc.PeerCertificates()
+			_ = nc.Close()
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			err = network.RawParse(nc, []byte(data), res, variableMap)
+			if err != nil {
+				logger.Debugf("ssl parse error：%s", err.Error())
+			}
+			if response, ok := variableMap["response"].(*proto.Response); ok {
+				response.Cert = network.CertVariables(certs)
+			}
+			return variableMap, nil
 		case common.GoType:
 			//fmt.Println("执行go模块调用发送请求，当前模块未完成")
 			logger.Fatal("执行go模块调用发送请求，当前模块未完成")
@@ -165,8 +272,13 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		if len(rule.Request.Raw) > 0 {
 			// 执行raw格式请求
 			logger.Info("执行raw格式请求")
-			rt := network.RawHttp{RawhttpClient: network.GetRawHTTP(int(options.Timeout))}
-			err := rt.RawHttpRequest(rule.Request.Raw, target, variableMap)
+			rt := network.RawHttp{RawhttpClient: network.GetRawHTTP(int(options.Timeout / time.Second)), Timeout: options.Timeout}
+			var err error
+			if network.IsRawMode() {
+				err = rt.OrderedRawHttpRequest(rule.Request.Raw, target, true, variableMap)
+			} else {
+				err = rt.RawHttpRequest(rule.Request.Raw, target, variableMap)
+			}
 			if err != nil {
 				return variableMap, err
 			}
@@ -174,8 +286,16 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		}
 	}
 
-	// 处理协议，增加通信协议
-	NewUrlStr, err := network.CheckProtocol(urlStr, options.Proxy)
+	// 处理协议，增加通信协议，http2类型未指定协议时按明文h2c处理，https目标保持TLS
+	NewUrlStr := urlStr
+	var err error
+	if reqType == common.Http2Type {
+		if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
+			NewUrlStr = "http://" + urlStr
+		}
+	} else {
+		NewUrlStr, err = network.CheckProtocol(urlStr, options.Proxy)
+	}
 	if err != nil {
 		logger.Debugf("检查http通信协议出错，错误信息：%s", err)
 		if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
@@ -183,10 +303,30 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		}
 	}
 
+	// 协议确定后https目标按倍数延长超时
+	if scaled := network.ScaleTimeout(NewUrlStr, options.Timeout); scaled != options.Timeout {
+		options.Timeout = scaled
+		var scaledCancel context.CancelFunc
+		ctx, scaledCancel = context.WithTimeout(parent, scaled)
+		defer scaledCancel()
+	}
+
 	logger.Debugf("请求URL：%s", NewUrlStr)
+	// 协议确定后更新完整目标地址与默认端口，set中自定义的同名变量保持不变
+	updateTargetVariables(variableMap, initialVars, targetVariables(NewUrlStr))
 
-	// 发送请求
-	resp, err := network.SendRequestHttp(ctx, req.Method, NewUrlStr, rule.Request.Body, options)
+	// 原始请求模式下通过rawhttp按规则书写顺序发送请求头，h2c请求不受影响
+	if network.IsRawMode() && reqType != common.Http2Type {
+		return sendRawModeRequest(ctx, NewUrlStr, rule.Request, options, variableMap)
+	}
+
+	// 发送请求，http2类型http目标使用h2c先验知识方式，https目标通过TLS协商h2
+	var resp *http.Response
+	if reqType == common.Http2Type {
+		resp, err = network.SendRequestH2C(ctx, req.Method, NewUrlStr, rule.Request.Body, options)
+	} else {
+		resp, err = network.SendRequestHttp(ctx, req.Method, NewUrlStr, rule.Request.Body, options)
+	}
 	if err != nil {
 		logger.Debugf("发送请求出错，错误信息：%s", err)
 		return variableMap, err
@@ -224,3 +364,166 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 	variableMap["response"] = protoResp
 	return variableMap, nil
 }
+
+// sendRawModeRequest 将规则请求转换为原始HTTP报文，通过rawhttp按请求头书写顺序与大小写原样发送
+// rawhttp 不支持上下文，发送前后检查 ctx，并将超时收紧到 ctx 的剩余时间
+func sendRawModeRequest(ctx context.Context, urlStr string, req RuleRequest, options network.OptionsRequest, variableMap map[string]any) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return variableMap, err
+	}
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return variableMap, fmt.Errorf("解析请求URL失败: %v", err)
+	}
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	var raw strings.Builder
+	raw.WriteString(method + " " + u.RequestURI() + " HTTP/1.1\r\n")
+	// 规则请求头按书写顺序与大小写在前，其后依次补充其余自定义请求头与通用请求头，同名头不重复发送
+	written := make(map[string]bool)
+	writeHeader := func(k, v string) {
+		raw.WriteString(k + ": " + v + "\r\n")
+		written[textproto.CanonicalMIMEHeaderKey(k)] = true
+	}
+	for _, k := range req.HeaderKeys() {
+		writeHeader(k, options.CustomHeaders[k])
+	}
+	for _, headers := range []map[string]string{options.CustomHeaders, network.DefaultRequestHeaders()} {
+		keys := make([]string, 0, len(headers))
+		for k := range headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !written[textproto.CanonicalMIMEHeaderKey(k)] {
+				writeHeader(k, headers[k])
+			}
+		}
+	}
+	if method == http.MethodPost && !written["Content-Type"] {
+		writeHeader("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if req.Body != "" && !hasHeader(req.Headers, "Content-Length") {
+		raw.WriteString(fmt.Sprintf("Content-Length: %d\r\n", len(req.Body)))
+	}
+	raw.WriteString("\r\n")
+	raw.WriteString(req.Body)
+
+	timeout := options.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return variableMap, context.DeadlineExceeded
+		}
+		if remaining < timeout {
+			timeout = remaining
+		}
+	}
+	rt := network.RawHttp{RawhttpClient: network.GetRawHTTP(int(options.Timeout / time.Second)), Timeout: timeout}
+	baseURL := u.Scheme + "://" + u.Host
+	err = rt.OrderedRawHttpRequest(raw.String(), baseURL, options.FollowRedirects, variableMap)
+	// 请求期间上下文已取消或超时时丢弃结果
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return variableMap, ctxErr
+	}
+	if err != nil {
+		return variableMap, err
+	}
+	// 原始报文按站点根地址发送，恢复请求与响应中的完整URL
+	if protoReq, ok := variableMap["request"].(*proto.Request); ok && protoReq != nil {
+		protoReq.Url = common.Url2UrlType(u)
+	}
+	if protoResp, ok := variableMap["response"].(*proto.Response); ok && protoResp != nil {
+		protoResp.Url = common.Url2UrlType(u)
+	}
+	variableMap["fulltarget"] = urlStr
+	return variableMap, nil
+}
+
+// hasHeader 判断请求头中是否包含指定名称的头，不区分大小写
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTargetVariables 根据目标地址设置 host（主机）、port（端口）、hostname（主机:端口）与 fulltarget（完整地址）变量，
+// 已存在的 host、port、hostname 变量（如 set 中自定义的同名变量）不会被覆盖，fulltarget 始终更新为本次请求地址
+// 目标未携带协议时无法确定默认端口，port 为空
+func SetTargetVariables(target string, variableMap map[string]any) {
+	updateTargetVariables(variableMap, nil, targetVariables(target))
+}
+
+// updateTargetVariables 写入目标变量，仅覆盖不存在或仍为 previous 中旧值的 host、port、hostname 变量
+func updateTargetVariables(variableMap, previous, current map[string]any) {
+	for key, value := range current {
+		if old, ok := variableMap[key]; ok && key != "fulltarget" && (previous == nil || old != previous[key]) {
+			continue
+		}
+		variableMap[key] = value
+	}
+}
+
+// targetVariables 根据目标地址计算目标相关变量，无法解析时返回nil
+func targetVariables(target string) map[string]any {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("//" + target)
+		if err != nil || u.Host == "" {
+			return nil
+		}
+	}
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	hostname := u.Host
+	if port != "" {
+		hostname = net.JoinHostPort(u.Hostname(), port)
+	}
+	return map[string]any{
+		"host":       u.Hostname(),
+		"port":       port,
+		"hostname":   hostname,
+		"fulltarget": target,
+	}
+}
+
+// applyRuleCookies 将规则中的cookie按名称排序后合并到Cookie请求头，规则已设置Cookie请求头时追加在其后
+func applyRuleCookies(cookies map[string]string, headers map[string]string, variableMap map[string]any) {
+	if len(cookies) == 0 {
+		return
+	}
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+1)
+	key := "Cookie"
+	for k, v := range headers {
+		if strings.EqualFold(k, "Cookie") {
+			key = k
+			if v != "" {
+				pairs = append(pairs, v)
+			}
+			break
+		}
+	}
+	for _, name := range names {
+		pairs = append(pairs, name+"="+SetVariableMap(cookies[name], variableMap))
+	}
+	headers[key] = strings.Join(pairs, "; ")
+}
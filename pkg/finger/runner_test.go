@@ -0,0 +1,451 @@
+package finger
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"xfirefly/pkg/cel"
+	"xfirefly/pkg/network"
+	"xfirefly/pkg/utils/proto"
+)
+
+// selfSignedCert 生成指定CN的自签名证书
+func selfSignedCert(t *testing.T, cn string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSendRequestResponseCert(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t, "xfirefly-test")}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/"}}
+	variableMap, err := SendRequest(context.Background(), srv.URL, rule.Request, rule, map[string]any{}, "", 5)
+	if err != nil {
+		t.Fatalf("SendRequest 失败: %v", err)
+	}
+	response, ok := variableMap["response"].(*proto.Response)
+	if !ok {
+		t.Fatalf("response 类型为 %T，期望 *proto.Response", variableMap["response"])
+	}
+	if got := response.Cert["subject_cn"]; got != "xfirefly-test" {
+		t.Errorf("response.Cert[subject_cn] = %q，期望 %q", got, "xfirefly-test")
+	}
+
+	expression := `response.cert["subject_cn"] == "xfirefly-test"`
+	out, err := cel.NewCustomLib().Evaluate(expression, variableMap)
+	if err != nil {
+		t.Fatalf("执行表达式 %s 失败: %v", expression, err)
+	}
+	if out.Value() != true {
+		t.Errorf("表达式 %s = %v，期望 true", expression, out)
+	}
+	if _, ok := variableMap["cert"]; ok {
+		t.Error("variableMap 不应再包含 cert 别名，证书信息仅通过 response.cert 访问")
+	}
+}
+
+func TestSendRequestSslTransport(t *testing.T) {
+	// TLS服务收到 PING 后返回 PONG
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t, "ssl-service")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 16)
+			if n, _ := conn.Read(buf); strings.HasPrefix(string(buf[:n]), "PING") {
+				_, _ = conn.Write([]byte("PONG\r\n"))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name     string
+		data     string
+		wantBody string
+	}{
+		{"握手后收发数据", "PING\r\n", "PONG\r\n"},
+		{"仅握手获取证书", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Request: RuleRequest{Type: "ssl", Host: ln.Addr().String(), Data: tt.data, ReadTimeout: 2}}
+			variableMap, err := SendRequest(context.Background(), ln.Addr().String(), rule.Request, rule, map[string]any{}, "", 5)
+			if err != nil {
+				t.Fatalf("SendRequest 失败: %v", err)
+			}
+			response, ok := variableMap["response"].(*proto.Response)
+			if !ok {
+				t.Fatalf("response 类型为 %T，期望 *proto.Response", variableMap["response"])
+			}
+			if string(response.Body) != tt.wantBody {
+				t.Errorf("response.body = %q，期望 %q", response.Body, tt.wantBody)
+			}
+			if got := response.Cert["subject_cn"]; got != "ssl-service" {
+				t.Errorf("response.cert[subject_cn] = %q，期望 ssl-service", got)
+			}
+		})
+	}
+}
+
+func TestSendRequestSslStalledHandshake(t *testing.T) {
+	// 服务端接受连接后不进行握手，ctx 到期后应立即返回而非等待默认拨号超时
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				<-done
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	rule := Rule{Request: RuleRequest{Type: "ssl", Host: ln.Addr().String()}}
+	start := time.Now()
+	_, err = SendRequest(ctx, ln.Addr().String(), rule.Request, rule, map[string]any{}, "", 5)
+	if err == nil {
+		t.Fatal("握手未完成时 SendRequest 应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SendRequest 耗时 %v，应在 ctx 到期后尽快返回", elapsed)
+	}
+}
+
+func TestSendRequestResponseTrailers(t *testing.T) {
+	// 分块响应在响应体之后返回 Trailer，如 gRPC 的 grpc-status
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		_, _ = w.Write([]byte("payload"))
+		w.(http.Flusher).Flush()
+		w.Header().Set("Grpc-Status", "12")
+		w.Header().Set("Grpc-Message", "unimplemented")
+	}))
+	defer srv.Close()
+
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/"}}
+	variableMap, err := SendRequest(context.Background(), srv.URL, rule.Request, rule, map[string]any{}, "", 5)
+	if err != nil {
+		t.Fatalf("SendRequest 失败: %v", err)
+	}
+	response := variableMap["response"].(*proto.Response)
+	if response.Trailers["grpc-status"] != "12" || response.Trailers["grpc-message"] != "unimplemented" {
+		t.Errorf("response.trailers = %v，期望包含 grpc-status 与 grpc-message", response.Trailers)
+	}
+	out, err := cel.NewCustomLib().Evaluate(`response.trailers["grpc-status"] == "12"`, variableMap)
+	if err != nil || out.Value() != true {
+		t.Errorf("表达式读取 response.trailers 结果 = %v（%v），期望 true", out, err)
+	}
+}
+
+func TestSetTargetVariables(t *testing.T) {
+	tests := []struct {
+		target       string
+		wantHost     string
+		wantPort     string
+		wantHostname string
+	}{
+		{"http://example.com/app", "example.com", "80", "example.com:80"},
+		{"https://example.com", "example.com", "443", "example.com:443"},
+		{"https://[::1]:8443/", "::1", "8443", "[::1]:8443"},
+		{"example.com:8080", "example.com", "8080", "example.com:8080"},
+		{"example.com", "example.com", "", "example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			variableMap := map[string]any{}
+			SetTargetVariables(tt.target, variableMap)
+			if variableMap["host"] != tt.wantHost || variableMap["port"] != tt.wantPort || variableMap["hostname"] != tt.wantHostname {
+				t.Errorf("host/port/hostname = %v/%v/%v，期望 %s/%s/%s", variableMap["host"], variableMap["port"], variableMap["hostname"], tt.wantHost, tt.wantPort, tt.wantHostname)
+			}
+			if variableMap["fulltarget"] != tt.target {
+				t.Errorf("fulltarget = %v，期望 %s", variableMap["fulltarget"], tt.target)
+			}
+		})
+	}
+
+	// set 中自定义的同名变量不被覆盖
+	variableMap := map[string]any{"host": "custom.example.com"}
+	SetTargetVariables("http://example.com", variableMap)
+	if variableMap["host"] != "custom.example.com" || variableMap["port"] != "80" {
+		t.Errorf("host/port = %v/%v，期望保留自定义 host", variableMap["host"], variableMap["port"])
+	}
+}
+
+func TestSendRequestTargetVariables(t *testing.T) {
+	var gotPath, gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Get("X-Origin")
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	rule := Rule{Request: RuleRequest{
+		Method:  http.MethodGet,
+		Path:    "/check/{{port}}",
+		Headers: map[string]string{"X-Origin": "{{hostname}}"},
+	}}
+	if _, err := SendRequest(context.Background(), srv.URL, rule.Request, rule, map[string]any{}, "", 5); err != nil {
+		t.Fatalf("SendRequest 失败: %v", err)
+	}
+	if gotPath != "/check/"+u.Port() {
+		t.Errorf("请求路径 = %s，期望 /check/%s", gotPath, u.Port())
+	}
+	if gotHeader != u.Host {
+		t.Errorf("X-Origin = %s，期望 %s", gotHeader, u.Host)
+	}
+}
+
+func TestSendRequestResponseContentLength(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			_, _ = w.Write([]byte("part"))
+			w.(http.Flusher).Flush()
+			return
+		}
+		w.Header().Set("Content-Length", "5")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		want int64 // 未声明 Content-Length 时为 -1
+	}{
+		{"/", 5},
+		{"/chunked", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: tt.path}}
+			variableMap, err := SendRequest(context.Background(), srv.URL, rule.Request, rule, map[string]any{}, "", 5)
+			if err != nil {
+				t.Fatalf("SendRequest 失败: %v", err)
+			}
+			if got := variableMap["response"].(*proto.Response).ContentLength; got != tt.want {
+				t.Errorf("response.content_length = %d，期望 %d", got, tt.want)
+			}
+			expression := fmt.Sprintf("response.content_length == %d", tt.want)
+			if out, err := cel.NewCustomLib().Evaluate(expression, variableMap); err != nil || out.Value() != true {
+				t.Errorf("表达式 %s 结果 = %v（%v），期望 true", expression, out, err)
+			}
+		})
+	}
+}
+
+func TestSendRequestRawMode(t *testing.T) {
+	// 记录收到的原始请求头行
+	lines := make(chan []string, 1)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var got []string
+		for {
+			line, err := reader.ReadString('\n')
+			line = strings.TrimRight(line, "\r\n")
+			if err != nil || line == "" {
+				break
+			}
+			got = append(got, line)
+		}
+		lines <- got
+		_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok"))
+	}()
+
+	content := `
+id: raw-mode
+info:
+  name: raw-mode
+rules:
+  r0:
+    request:
+      method: GET
+      path: /check
+      headers:
+        X-Zeta: "1"
+        x-alpha: "2"
+        User-Agent: raw-agent
+    expression: response.status == 200
+expression: r0()
+`
+	path := filepath.Join(t.TempDir(), "raw-mode.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	rule := f.Rules[0].Value
+	if got := strings.Join(rule.Request.HeaderKeys(), ","); got != "X-Zeta,x-alpha,User-Agent" {
+		t.Fatalf("请求头顺序 = %s，期望按书写顺序", got)
+	}
+
+	network.SetRawMode(true)
+	defer network.SetRawMode(false)
+	variableMap, err := SendRequest(context.Background(), "http://"+ln.Addr().String(), rule.Request, rule, map[string]any{}, "", 5)
+	if err != nil {
+		t.Fatalf("SendRequest 失败: %v", err)
+	}
+	if body := variableMap["response"].(*proto.Response).Body; string(body) != "ok" {
+		t.Errorf("response.body = %q，期望 ok", body)
+	}
+
+	var got []string
+	select {
+	case got = <-lines:
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到请求")
+	}
+	// 首行之后为补充的 Host 头，规则请求头按书写顺序与大小写原样发送，之后补充通用请求头
+	want := []string{"GET /check HTTP/1.1", "Host: " + ln.Addr().String(), "X-Zeta: 1", "x-alpha: 2", "User-Agent: raw-agent"}
+	if len(got) < len(want) || strings.Join(got[:len(want)], "\n") != strings.Join(want, "\n") {
+		t.Fatalf("原始请求 = %q，期望以 %q 开头", got, want)
+	}
+	var userAgents int
+	var hasAccept bool
+	for _, line := range got {
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "user-agent:") {
+			userAgents++
+		}
+		hasAccept = hasAccept || strings.HasPrefix(lower, "accept:")
+	}
+	if userAgents != 1 || !hasAccept {
+		t.Errorf("原始请求 = %q，期望补充通用请求头且同名头不重复发送", got)
+	}
+}
+
+func TestSendRequestRawModeContext(t *testing.T) {
+	// 服务端收到请求后迟迟不响应
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				time.Sleep(3 * time.Second)
+			}()
+		}
+	}()
+
+	rule := Rule{Request: RuleRequest{Method: "GET", Path: "/"}, Expression: "response.status == 200"}
+	network.SetRawMode(true)
+	defer network.SetRawMode(false)
+	target := "http://" + ln.Addr().String()
+
+	// 上下文超时先于请求超时，rawhttp 按上下文的剩余时间结束请求
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := SendRequest(ctx, target, rule.Request, rule, map[string]any{}, "", 5); err == nil {
+		t.Error("上下文超时后请求应失败")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("上下文超时后请求耗时 %v，期望随上下文及时结束", elapsed)
+	}
+
+	// 已取消的上下文不再发送请求
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := SendRequest(canceled, target, rule.Request, rule, map[string]any{}, "", 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("已取消的上下文错误 = %v，期望 context.Canceled", err)
+	}
+}
+
+func TestSendRequestHTTPSTimeoutMultiplier(t *testing.T) {
+	// 响应耗时超过1秒超时但在放大后的超时之内
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+		_, _ = w.Write([]byte("slow"))
+	}))
+	defer srv.Close()
+	defer network.SetHTTPSTimeoutMultiplier(1)
+
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/"}}
+	for _, tt := range []struct {
+		multiplier float64
+		wantErr    bool
+	}{
+		{1, true},
+		{3, false},
+	} {
+		network.SetHTTPSTimeoutMultiplier(tt.multiplier)
+		_, err := SendRequest(context.Background(), srv.URL, rule.Request, rule, map[string]any{}, "", 1)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("超时倍数 %g 时 SendRequest 错误 = %v，期望出错 %v", tt.multiplier, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package finger
+
+import (
+	"net/http"
+	"xfirefly/pkg/types"
+)
+
+// securityHeaderNames 需要分析的安全响应头，按输出顺序排列
+var securityHeaderNames = []string{
+	"Content-Security-Policy",
+	"Strict-Transport-Security",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+}
+
+// GetSecurityHeaders 从HTTP响应头中提取关键安全响应头，并记录缺失项
+// 参数:
+//   - header: HTTP响应头
+//
+// 返回值:
+//   - *types.SecurityHeaders: 安全响应头分析结果
+func GetSecurityHeaders(header http.Header) *types.SecurityHeaders {
+	result := &types.SecurityHeaders{}
+	if header == nil {
+		result.Missing = append(result.Missing, securityHeaderNames...)
+		return result
+	}
+
+	values := map[string]*string{
+		"Content-Security-Policy":   &result.ContentSecurityPolicy,
+		"Strict-Transport-Security": &result.StrictTransportSecurity,
+		"X-Frame-Options":           &result.XFrameOptions,
+		"X-Content-Type-Options":    &result.XContentTypeOptions,
+	}
+	for _, name := range securityHeaderNames {
+		value := header.Get(name)
+		if value == "" {
+			result.Missing = append(result.Missing, name)
+			continue
+		}
+		*values[name] = value
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package finger
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"xfirefly/pkg/types"
+)
+
+func TestGetSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   *types.SecurityHeaders
+	}{
+		{
+			"全部存在",
+			http.Header{
+				"Content-Security-Policy":   {"default-src 'self'"},
+				"Strict-Transport-Security": {"max-age=31536000"},
+				"X-Frame-Options":           {"DENY"},
+				"X-Content-Type-Options":    {"nosniff"},
+			},
+			&types.SecurityHeaders{
+				ContentSecurityPolicy:   "default-src 'self'",
+				StrictTransportSecurity: "max-age=31536000",
+				XFrameOptions:           "DENY",
+				XContentTypeOptions:     "nosniff",
+			},
+		},
+		{
+			"部分缺失且响应头大小写不敏感",
+			func() http.Header {
+				h := http.Header{}
+				h.Set("x-frame-options", "SAMEORIGIN")
+				h.Set("strict-transport-security", "max-age=600")
+				return h
+			}(),
+			&types.SecurityHeaders{
+				StrictTransportSecurity: "max-age=600",
+				XFrameOptions:           "SAMEORIGIN",
+				Missing:                 []string{"Content-Security-Policy", "X-Content-Type-Options"},
+			},
+		},
+		{
+			"空值视为缺失",
+			http.Header{"X-Content-Type-Options": {""}},
+			&types.SecurityHeaders{Missing: securityHeaderNames},
+		},
+		{"无响应头", nil, &types.SecurityHeaders{Missing: securityHeaderNames}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetSecurityHeaders(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSecurityHeaders = %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
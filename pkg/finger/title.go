@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+	"xfirefly/pkg/network"
 	"xfirefly/pkg/utils/common"
 
 	"github.com/donnie4w/go-logger/logger"
@@ -122,8 +124,8 @@ func GetTitle(urlStr string, resp *http.Response) string {
 		}
 	}
 
-	// 尝试从i18n JavaScript文件获取标题
-	if titleURL != "" {
+	// 尝试从i18n JavaScript文件获取标题，离线模式下跳过
+	if titleURL != "" && !network.IsOffline() {
 		logger.Debug("识别到国际化，从i18n JS文件获取标题数据")
 
 		retries := 3
@@ -147,10 +149,12 @@ func GetTitle(urlStr string, resp *http.Response) string {
 
 			respTitle, err := client.Do(req)
 			if err != nil {
+				network.LogRequest(req.Method, titleURL, 0, err)
 				logger.Debugf("获取i18n JS文件出错: %v", err)
 				continue
 			}
 
+			network.LogRequest(req.Method, titleURL, respTitle.StatusCode, nil)
 			if respTitle.StatusCode == 200 {
 				bodyBytes, err := io.ReadAll(respTitle.Body)
 				_ = respTitle.Body.Close()
@@ -174,7 +178,28 @@ func GetTitle(urlStr string, resp *http.Response) string {
 		}
 	}
 
-	return title
+	// DOM与i18n中提取的标题同样需要清理，最后统一截断
+	return truncateTitle(cleanTitle(title))
+}
+
+// titleMaxLen 标题最大长度（按字符计），超出部分截断并追加省略号，0表示不限制
+var titleMaxLen = 200
+
+// SetTitleMaxLen 设置标题最大长度，0表示不限制
+func SetTitleMaxLen(n int) {
+	if n < 0 {
+		n = 0
+	}
+	titleMaxLen = n
+}
+
+// truncateTitle 按字符截断超长标题，截断后追加省略号
+func truncateTitle(title string) string {
+	if titleMaxLen <= 0 || utf8.RuneCountInString(title) <= titleMaxLen {
+		return title
+	}
+	runes := []rune(title)
+	return strings.TrimSpace(string(runes[:titleMaxLen])) + "..."
 }
 
 // cleanTitle 移除空白字符并清理标题字符串
@@ -182,11 +207,14 @@ func cleanTitle(title string) string {
 	// 先确保标题是UTF-8编码
 	title = common.Str2UTF8(title)
 
-	// 移除制表符、换行符和回车符
+	// 移除制表符、换行符和回车符，其余不可打印字符（控制字符、零宽字符等）直接删除
 	title = strings.Map(func(r rune) rune {
 		if r == '\r' || r == '\n' || r == '\t' {
 			return ' ' // 将这些字符替换为空格，而不是删除它们
 		}
+		if !network.IsPrintable(r) && !unicode.IsSpace(r) {
+			return -1
+		}
 		return r
 	}, title)
 
@@ -0,0 +1,56 @@
+package finger
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"换行与制表符替换为空格", "Admin\r\n\tConsole", "Admin Console"},
+		{"删除控制字符", "Jenkins\x00\x07 Dashboard", "Jenkins Dashboard"},
+		{"删除零宽字符", "Grafa\u200bna", "Grafana"},
+		{"合并连续空白", "  统一   登录  平台 ", "统一 登录 平台"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanTitle(tt.title); got != tt.want {
+				t.Errorf("cleanTitle(%q) = %q，期望 %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTitleMaxLen(t *testing.T) {
+	defer SetTitleMaxLen(200)
+
+	title := strings.Repeat("管理", 10) + "\u200b系统"
+	getTitle := func() string {
+		resp := &http.Response{
+			Header: http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader("<html><title>" + title + "</title></html>")),
+		}
+		return GetTitle("http://example.com", resp)
+	}
+
+	tests := []struct {
+		maxLen int
+		want   string
+	}{
+		{0, strings.Repeat("管理", 10) + "系统"},
+		{5, "管理管理管..."},
+		{100, strings.Repeat("管理", 10) + "系统"},
+	}
+	for _, tt := range tests {
+		SetTitleMaxLen(tt.maxLen)
+		if got := getTitle(); got != tt.want {
+			t.Errorf("--title-max-len=%d 时标题 = %q，期望 %q", tt.maxLen, got, tt.want)
+		}
+	}
+}
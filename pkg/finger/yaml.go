@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"xfirefly/pkg/utils/common"
 
@@ -16,15 +17,14 @@ import (
 // FingerPath 配置poc文件目录
 const FingerPath = "fingerprint"
 const (
-	HttpType = "http"
-	TcpType  = "tcp"
-	UdpType  = "udp"
-	SslType  = "ssl"
-	GoType   = "go"
+	HttpType  = "http"
+	Http2Type = "http2" // HTTP/2请求，http目标使用h2c先验知识方式，https目标通过TLS协商h2
+	TcpType   = "tcp"
+	UdpType   = "udp"
+	SslType   = "ssl"
+	GoType    = "go"
 )
 
-var order = 0
-
 type Finger struct {
 	Id         string        `yaml:"id"`         //  脚本名称
 	Transport  string        `yaml:"transport"`  // 传输方式，该字段用于指定发送数据包的协议，该字段用于指定发送数据包的协议:tcp、udp、http
@@ -58,7 +58,11 @@ type Rule struct {
 	StopIfMatch    bool          `yaml:"stop_if_match"`    // 匹配成功时，是否停止继续匹配
 	StopIfMismatch bool          `yaml:"stop_if_mismatch"` // 匹配失败时，是否停止继续匹配
 	BeforeSleep    int           `yaml:"before_sleep"`     // 匹配成功时，等待的时间
-	order          int           // 规则顺序
+	// Matchers 声明式匹配器，可替代或配合 expression 使用，两者同时存在时需同时满足
+	Matchers          []Matcher `yaml:"matchers"`
+	MatchersCondition string    `yaml:"matchers-condition"` // 多个匹配器之间的关系：or（默认）、and
+	// Extractors 声明式提取器，提取结果与 output 一样写入指纹结果
+	Extractors []Extractor `yaml:"extractors"`
 }
 
 // RuleRequest 请求结构体
@@ -68,13 +72,48 @@ type RuleRequest struct {
 	Data            string            `yaml:"data"`             // tcp/udp 发送的内容
 	DataType        string            `yaml:"data-type"`        // tcp/udp 发送的数据类型，默认字符串
 	ReadSize        int               `yaml:"read-size"`        // tcp/udp 读取内容的长度
-	ReadTimeout     int               `yaml:"read-timeout"`     // tcp/udp专用
+	ReadTimeout     int               `yaml:"read-timeout"`     // tcp/udp专用，单位秒
+	ReadUntil       string            `yaml:"read-until"`       // 仅 tcp 生效，读取至出现该分隔符为止，如 "\n" 或 "> "，用于读取服务banner；ssl 在发送数据后读取至连接关闭或空闲超时
 	Raw             string            `yaml:"raw"`              // raw 专用
 	Method          string            `yaml:"method"`           // http 请求方式
 	Path            string            `yaml:"path"`             // http 请求路径
 	Headers         map[string]string `yaml:"headers"`          // http 请求头
+	Cookies         map[string]string `yaml:"cookies"`          // http 请求携带的cookie，合并到Cookie请求头
 	Body            string            `yaml:"body"`             // http 请求体
 	FollowRedirects bool              `yaml:"follow_redirects"` // 是否跟随重定向，默认跟随重定向
+	headerOrder     []string          // 请求头在规则中的书写顺序
+}
+
+// UnmarshalYAML 解析请求内容，并记录请求头的书写顺序
+func (r *RuleRequest) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain RuleRequest
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	var ordered struct {
+		Headers yaml.MapSlice `yaml:"headers"`
+	}
+	if err := unmarshal(&ordered); err != nil {
+		return err
+	}
+	r.headerOrder = make([]string, 0, len(ordered.Headers))
+	for _, item := range ordered.Headers {
+		r.headerOrder = append(r.headerOrder, fmt.Sprint(item.Key))
+	}
+	return nil
+}
+
+// HeaderKeys 按规则中的书写顺序返回请求头名称，无顺序信息时按名称排序
+func (r RuleRequest) HeaderKeys() []string {
+	if len(r.headerOrder) == len(r.Headers) {
+		return r.headerOrder
+	}
+	keys := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // Info 以下开始是 信息部分
@@ -102,13 +141,16 @@ type Classification struct {
 
 // ruleAlias 类型
 type ruleAlias struct {
-	Request        RuleRequest   `yaml:"request"`          // 请求
-	Expression     string        `yaml:"expression"`       // 匹配规则
-	Expressions    []string      `yaml:"expressions"`      // 匹配规则
-	Output         yaml.MapSlice `yaml:"output"`           // 输出
-	StopIfMatch    bool          `yaml:"stop_if_match"`    // 匹配成功时，是否停止继续匹配
-	StopIfMismatch bool          `yaml:"stop_if_mismatch"` // 匹配失败时，是否停止继续匹配
-	BeforeSleep    int           `yaml:"before_sleep"`     // 匹配成功时，等待的时间
+	Request           RuleRequest   `yaml:"request"`            // 请求
+	Expression        string        `yaml:"expression"`         // 匹配规则
+	Expressions       []string      `yaml:"expressions"`        // 匹配规则
+	Output            yaml.MapSlice `yaml:"output"`             // 输出
+	StopIfMatch       bool          `yaml:"stop_if_match"`      // 匹配成功时，是否停止继续匹配
+	StopIfMismatch    bool          `yaml:"stop_if_mismatch"`   // 匹配失败时，是否停止继续匹配
+	BeforeSleep       int           `yaml:"before_sleep"`       // 匹配成功时，等待的时间
+	Matchers          []Matcher     `yaml:"matchers"`           // 声明式匹配器
+	MatchersCondition string        `yaml:"matchers-condition"` // 多个匹配器之间的关系
+	Extractors        []Extractor   `yaml:"extractors"`         // 声明式提取器
 }
 
 // Select 获取指定名字的yaml文件位置
@@ -224,7 +266,7 @@ func ReadDir(root string) ([]string, error) {
 func (finger *Finger) IsHTTPType() bool {
 	for _, rule := range finger.Rules {
 		reqType := rule.Value.Request.Type
-		if len(reqType) == 0 || reqType == HttpType {
+		if len(reqType) == 0 || reqType == HttpType || reqType == Http2Type {
 			return true
 		}
 	}
@@ -238,6 +280,11 @@ func (r *Rule) UnmarshalYAML(unmarshal func(any) error) error {
 	if err := unmarshal(&tmp); err != nil {
 		return err
 	}
+	for i, m := range tmp.Matchers {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("第 %d 个匹配器无效: %v", i+1, err)
+		}
+	}
 
 	r.Request = tmp.Request
 	r.Expression = tmp.Expression
@@ -246,30 +293,57 @@ func (r *Rule) UnmarshalYAML(unmarshal func(any) error) error {
 	r.StopIfMatch = tmp.StopIfMatch
 	r.StopIfMismatch = tmp.StopIfMismatch
 	r.BeforeSleep = tmp.BeforeSleep
-	r.order = order
-
-	order += 1
+	r.Matchers = tmp.Matchers
+	r.MatchersCondition = tmp.MatchersCondition
+	r.Extractors = tmp.Extractors
 	return nil
 }
 
-// UnmarshalYAML 解析yaml文件内容
+// UnmarshalYAML 解析yaml文件内容，按规则在文件中的书写顺序保存
+// 规则顺序取自 yaml.MapSlice 中的键顺序，解析过程不依赖任何包级状态，可并发调用
 func (m *RuleMapSlice) UnmarshalYAML(unmarshal func(any) error) error {
-	order = 0
-
-	tempMap := make(map[string]Rule, 1)
-	err := unmarshal(&tempMap)
-	if err != nil {
+	// 先按 MapSlice 解析获取规则的书写顺序
+	var keys yaml.MapSlice
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	// 再按 map 解析规则内容，嵌套的 output 等字段仍由 yaml.MapSlice 保序
+	rules := make(map[string]Rule, len(keys))
+	if err := unmarshal(&rules); err != nil {
 		return err
 	}
 
-	newRuleSlice := make([]RuleMap, len(tempMap))
-	for roleName, role := range tempMap {
-		newRuleSlice[role.order] = RuleMap{
-			Key:   roleName,
-			Value: role,
+	newRuleSlice := make([]RuleMap, 0, len(rules))
+	seen := make(map[string]struct{}, len(rules))
+	for _, item := range keys {
+		key := fmt.Sprint(item.Key)
+		// 规则名重复时 map 中保存的是最后一次定义，按首次出现的位置放置
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		rule, ok := rules[key]
+		if !ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		newRuleSlice = append(newRuleSlice, RuleMap{Key: key, Value: rule})
+	}
+
+	// expressions 中的表达式以 <规则键>_e0、<规则键>_e1... 注册结果，不能与规则键重名
+	for _, rule := range newRuleSlice {
+		for i := range rule.Value.Expressions {
+			name := ExpressionName(rule.Key, i)
+			if _, ok := seen[name]; ok {
+				return fmt.Errorf("规则 %s 的第 %d 个表达式名称 %s 与规则键重名", rule.Key, i+1, name)
+			}
 		}
 	}
 
 	*m = newRuleSlice
 	return nil
 }
+
+// ExpressionName 返回规则 expressions 中第 index 个表达式结果注册的函数名，如 r0_e0
+func ExpressionName(ruleKey string, index int) string {
+	return fmt.Sprintf("%s_e%d", ruleKey, index)
+}
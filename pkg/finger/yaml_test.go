@@ -0,0 +1,128 @@
+package finger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ruleKeys 返回指纹中规则名的顺序
+func ruleKeys(f *Finger) []string {
+	keys := make([]string, 0, len(f.Rules))
+	for _, rule := range f.Rules {
+		keys = append(keys, rule.Key)
+	}
+	return keys
+}
+
+func TestReadConcurrentRuleOrder(t *testing.T) {
+	dir := t.TempDir()
+	const files = 64
+	want := make([][]string, files)
+	paths := make([]string, files)
+	for i := 0; i < files; i++ {
+		// 每个文件的规则数量和名称不同，规则名不按字母序书写
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "id: finger-%d\ninfo:\n  name: finger-%d\nrules:\n", i, i)
+		for j := i%5 + 1; j > 0; j-- {
+			key := fmt.Sprintf("r%d_%d", j, i)
+			want[i] = append(want[i], key)
+			fmt.Fprintf(&builder, "  %s:\n    request:\n      method: GET\n      path: /%d\n    expression: response.status == 200\n", key, j)
+		}
+		builder.WriteString("expression: " + want[i][0] + "()\n")
+		paths[i] = filepath.Join(dir, fmt.Sprintf("finger-%d.yaml", i))
+		if err := os.WriteFile(paths[i], []byte(builder.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 多轮并发解析，配合 -race 检查解析过程不依赖共享状态
+	var wg sync.WaitGroup
+	for round := 0; round < 4; round++ {
+		for i := range paths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				f, err := Read(paths[i])
+				if err != nil {
+					t.Errorf("解析 %s 失败: %v", paths[i], err)
+					return
+				}
+				if got := ruleKeys(f); strings.Join(got, ",") != strings.Join(want[i], ",") {
+					t.Errorf("%s 规则顺序 = %v，期望 %v", paths[i], got, want[i])
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+}
+
+func TestRuleMapSliceDuplicateKeys(t *testing.T) {
+	// 规则名重复时不应越界，按首次出现的位置保存最后一次定义
+	content := `
+id: duplicate-rules
+info:
+  name: duplicate-rules
+rules:
+  r1:
+    request:
+      path: /first
+    expression: response.status == 200
+  r0:
+    request:
+      path: /zero
+    expression: response.status == 200
+  r1:
+    request:
+      path: /second
+    expression: response.status == 404
+expression: r0() && r1()
+`
+	path := filepath.Join(t.TempDir(), "duplicate.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if got := strings.Join(ruleKeys(f), ","); got != "r1,r0" {
+		t.Fatalf("规则顺序 = %s，期望 r1,r0", got)
+	}
+	if got := f.Rules[0].Value.Request.Path; got != "/second" {
+		t.Errorf("重复规则 r1 的路径 = %s，期望最后一次定义 /second", got)
+	}
+}
+
+func TestRuleMapSliceExpressionNameConflict(t *testing.T) {
+	// expressions 的结果以 <规则键>_e<序号> 注册，与规则键重名时加载失败
+	content := `
+id: expression-conflict
+info:
+  name: expression-conflict
+rules:
+  r0:
+    request:
+      path: /
+    expressions:
+      - response.status == 200
+  r0_e0:
+    request:
+      path: /
+    expression: response.status == 200
+expression: r0_e0()
+`
+	path := filepath.Join(t.TempDir(), "conflict.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "r0_e0") {
+		t.Errorf("表达式名称与规则键重名时应加载失败，实际错误: %v", err)
+	}
+	if got := ExpressionName("r1", 2); got != "r1_e2" {
+		t.Errorf("ExpressionName = %s，期望 r1_e2", got)
+	}
+}
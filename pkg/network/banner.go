@@ -0,0 +1,40 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// ReadUntil 读取数据直至出现分隔符、连接关闭、达到读取上限或超时，分隔符为空时等同于 Receive
+func (c *Client) ReadUntil(delimiter []byte) ([]byte, error) {
+	if len(delimiter) == 0 {
+		return c.Receive()
+	}
+	if c.conn == nil {
+		return nil, errors.New("connection is not established")
+	}
+
+	limit := c.readLimit()
+	chunk := make([]byte, min(DefaultReadSize, limit))
+	data := make([]byte, 0, len(chunk))
+	timeout := c.readTimeout()
+	for len(data) < limit {
+		_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+		n, err := c.conn.Read(chunk[:min(len(chunk), limit-len(data))])
+		// 分隔符可能跨越两次读取，从上次末尾回退分隔符长度开始查找
+		searchFrom := max(len(data)-len(delimiter)+1, 0)
+		data = append(data, chunk[:n]...)
+		if bytes.Contains(data[searchFrom:], delimiter) {
+			break
+		}
+		if err != nil {
+			if len(data) > 0 {
+				break
+			}
+			return nil, err
+		}
+		timeout = c.idleTimeout()
+	}
+	return data, nil
+}
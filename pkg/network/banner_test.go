@@ -0,0 +1,52 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReadUntil(t *testing.T) {
+	// 问候语分两次发送，分隔符跨越两次写入，之后保持连接不关闭
+	addr := serveTCP(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("220 mail.example ESMTP\r"))
+		time.Sleep(50 * time.Millisecond)
+		_, _ = conn.Write([]byte("\nextra"))
+		time.Sleep(3 * time.Second)
+	})
+
+	tests := []struct {
+		name      string
+		delimiter string
+		want      string
+		wantIdle  bool // 是否需要等待空闲超时
+	}{
+		{"读取至分隔符", "\r\n", "220 mail.example ESMTP\r\nextra", false},
+		{"分隔符未出现时读取至空闲超时", "> ", "220 mail.example ESMTP\r\nextra", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(addr, TcpOrUdpConfig{Network: "tcp", IdleTimeout: 500 * time.Millisecond})
+			if err != nil {
+				t.Fatalf("建立连接失败: %v", err)
+			}
+			defer client.Close()
+
+			start := time.Now()
+			data, err := client.ReadUntil([]byte(tt.delimiter))
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("读取数据 = %q，期望 %q", data, tt.want)
+			}
+			elapsed := time.Since(start)
+			if elapsed > 2*time.Second {
+				t.Errorf("读取耗时 %v，期望不等待连接关闭", elapsed)
+			}
+			if waited := elapsed >= 500*time.Millisecond; waited != tt.wantIdle {
+				t.Errorf("读取耗时 %v，是否等待空闲超时 = %v，期望 %v", elapsed, waited, tt.wantIdle)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// dnsCacheMaxEntries DNS缓存的最大条目数，写入时超出上限先清理过期条目
+const dnsCacheMaxEntries = 10000
+
+// DNS缓存配置
+var (
+	dnsCacheTTL   time.Duration               // DNS缓存有效期，0表示不缓存
+	dnsCache      = make(map[string]dnsEntry) // 主机名到解析结果的缓存
+	dnsCacheMutex sync.Mutex                  // 保护DNS缓存
+	dnsLookup     singleflight.Group          // 合并同一主机的并发解析请求
+	// lookupHost 实际执行DNS解析的函数
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+// dnsEntry DNS缓存条目
+type dnsEntry struct {
+	addrs   []string  // 解析得到的IP地址
+	expires time.Time // 过期时间
+}
+
+// SetDNSCache 设置DNS缓存有效期，0表示不缓存，每次连接都重新解析
+func SetDNSCache(ttl time.Duration) {
+	dnsCacheMutex.Lock()
+	dnsCacheTTL = ttl
+	dnsCache = make(map[string]dnsEntry)
+	dnsCacheMutex.Unlock()
+
+	// 清空transport缓存，使新的拨号配置生效
+	transportCache.Range(func(key, _ any) bool {
+		transportCache.Delete(key)
+		return true
+	})
+}
+
+// resolveHost 解析主机名，有效期内直接返回缓存结果
+func resolveHost(ctx context.Context, host string) ([]string, error) {
+	key := strings.ToLower(host)
+
+	dnsCacheMutex.Lock()
+	entry, ok := dnsCache[key]
+	dnsCacheMutex.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	// 同一主机的并发解析只发出一次查询，其余调用等待并共享结果
+	ch := dnsLookup.DoChan(key, func() (any, error) {
+		// 解析结果由多个调用共享，不随首个调用方的取消而中断
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultTimeout)
+		defer cancel()
+		addrs, err := lookupHost(lookupCtx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("解析主机 %s 未得到地址", host)
+		}
+
+		dnsCacheMutex.Lock()
+		storeDNSEntry(key, dnsEntry{addrs: addrs, expires: time.Now().Add(dnsCacheTTL)})
+		dnsCacheMutex.Unlock()
+		return addrs, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]string), nil
+	}
+}
+
+// storeDNSEntry 写入DNS缓存，达到上限时清理过期条目，仍然已满则随机淘汰一条，调用方需持有dnsCacheMutex
+func storeDNSEntry(key string, entry dnsEntry) {
+	if _, ok := dnsCache[key]; !ok && len(dnsCache) >= dnsCacheMaxEntries {
+		now := time.Now()
+		for k, e := range dnsCache {
+			if !now.Before(e.expires) {
+				delete(dnsCache, k)
+			}
+		}
+		for k := range dnsCache {
+			if len(dnsCache) < dnsCacheMaxEntries {
+				break
+			}
+			delete(dnsCache, k)
+		}
+	}
+	dnsCache[key] = entry
+}
+
+// baseDialContext 返回建立TCP连接的拨号函数，启用DNS缓存时使用带缓存的拨号函数
+func baseDialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dnsCacheTTL > 0 {
+		return cachedDialContext
+	}
+	return (&net.Dialer{Timeout: DefaultTimeout}).DialContext
+}
+
+// cachedDialContext 使用DNS缓存解析主机名后建立连接，依次尝试解析得到的地址
+func cachedDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := resolveHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// IsHostNotFound 判断主机名是否解析不存在(NXDOMAIN)，IP地址与其他解析错误均返回false
+func IsHostNotFound(host string) bool {
+	if host == "" || net.ParseIP(host) != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	_, err := lookupHost(ctx, host)
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
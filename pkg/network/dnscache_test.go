@@ -0,0 +1,115 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubLookupHost 替换实际的DNS解析函数，测试结束后恢复
+func stubLookupHost(t *testing.T, fn func(ctx context.Context, host string) ([]string, error)) {
+	t.Helper()
+	orig := lookupHost
+	lookupHost = fn
+	t.Cleanup(func() {
+		lookupHost = orig
+		SetDNSCache(0)
+	})
+}
+
+func TestResolveHostCoalescesConcurrentLookups(t *testing.T) {
+	SetDNSCache(time.Minute)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	stubLookupHost(t, func(ctx context.Context, host string) ([]string, error) {
+		calls.Add(1)
+		<-release
+		return []string{"127.0.0.1"}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addrs, err := resolveHost(context.Background(), "Example.com")
+			if err != nil || len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+				t.Errorf("resolveHost = %v, %v", addrs, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("并发解析同一主机时实际查询次数 = %d，期望 1", n)
+	}
+	if _, err := resolveHost(context.Background(), "example.com"); err != nil || calls.Load() != 1 {
+		t.Errorf("有效期内应直接使用缓存，查询次数 = %d, err = %v", calls.Load(), err)
+	}
+}
+
+func TestResolveHostHonorsCallerCancel(t *testing.T) {
+	SetDNSCache(time.Minute)
+	release := make(chan struct{})
+	stubLookupHost(t, func(ctx context.Context, host string) ([]string, error) {
+		<-release
+		return []string{"127.0.0.1"}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := resolveHost(ctx, "slow.example.com"); err != context.DeadlineExceeded {
+		t.Errorf("调用方超时后应返回 %v，实际 %v", context.DeadlineExceeded, err)
+	}
+
+	// 调用方取消不影响进行中的解析，结果仍会写入缓存
+	close(release)
+	if addrs, err := resolveHost(context.Background(), "slow.example.com"); err != nil || len(addrs) != 1 {
+		t.Errorf("resolveHost = %v, %v", addrs, err)
+	}
+}
+
+func TestDNSCacheBounded(t *testing.T) {
+	SetDNSCache(time.Minute)
+	stubLookupHost(t, func(ctx context.Context, host string) ([]string, error) {
+		return []string{"127.0.0.1"}, nil
+	})
+
+	for i := 0; i < dnsCacheMaxEntries+50; i++ {
+		if _, err := resolveHost(context.Background(), fmt.Sprintf("host%d.example.com", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dnsCacheMutex.Lock()
+	n := len(dnsCache)
+	dnsCacheMutex.Unlock()
+	if n > dnsCacheMaxEntries {
+		t.Errorf("DNS缓存条目数 = %d，超过上限 %d", n, dnsCacheMaxEntries)
+	}
+}
+
+func TestDNSCacheEvictsExpiredFirst(t *testing.T) {
+	SetDNSCache(time.Minute)
+	stubLookupHost(t, func(ctx context.Context, host string) ([]string, error) {
+		return []string{"127.0.0.1"}, nil
+	})
+
+	dnsCacheMutex.Lock()
+	for i := 0; i < dnsCacheMaxEntries; i++ {
+		dnsCache[fmt.Sprintf("old%d", i)] = dnsEntry{addrs: []string{"127.0.0.1"}, expires: time.Now().Add(-time.Second)}
+	}
+	dnsCache["live"] = dnsEntry{addrs: []string{"127.0.0.2"}, expires: time.Now().Add(time.Minute)}
+	storeDNSEntry("new", dnsEntry{addrs: []string{"127.0.0.3"}, expires: time.Now().Add(time.Minute)})
+	n := len(dnsCache)
+	_, live := dnsCache["live"]
+	dnsCacheMutex.Unlock()
+
+	if n != 2 || !live {
+		t.Errorf("写入时应清理全部过期条目并保留未过期条目，剩余 %d 条，live=%v", n, live)
+	}
+}
@@ -0,0 +1,108 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zan8in/retryablehttp"
+	"golang.org/x/net/http2"
+)
+
+// h2RoundTripper 按请求协议选择HTTP/2传输方式，重定向到其他协议时同样适用
+type h2RoundTripper struct {
+	cleartext *http2.Transport // http地址以先验知识(prior knowledge)方式发送明文HTTP/2(h2c)
+	tls       *http2.Transport // https地址通过TLS ALPN协商h2
+}
+
+func (rt *h2RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.URL.Scheme, "https") {
+		return rt.tls.RoundTrip(req)
+	}
+	return rt.cleartext.RoundTrip(req)
+}
+
+// h2Transport 返回发送HTTP/2请求的transport，配置代理时经CONNECT隧道或SOCKS5代理连接目标
+func h2Transport(proxyURL string) (http.RoundTripper, error) {
+	key := "h2:" + proxyURL
+	if cached, ok := transportCache.Load(key); ok {
+		return cached.(*h2RoundTripper), nil
+	}
+
+	var parsedProxy *url.URL
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址解析失败: %v", err)
+		}
+		parsedProxy = u
+	}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if parsedProxy != nil && !ShouldBypassProxy(addr) {
+			return dialViaProxy(ctx, parsedProxy, network, addr)
+		}
+		return baseDialContext()(ctx, network, addr)
+	}
+
+	rt := &h2RoundTripper{
+		cleartext: &http2.Transport{
+			AllowHTTP: true,
+			// 明文HTTP/2直接使用TCP连接，不进行TLS握手
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+		},
+		tls: &http2.Transport{
+			TLSClientConfig: tlsConfig,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				conn, err := dial(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				tlsConn := tls.Client(conn, cfg)
+				if err := tlsConn.HandshakeContext(ctx); err != nil {
+					_ = conn.Close()
+					return nil, err
+				}
+				if tlsConn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+					_ = tlsConn.Close()
+					return nil, fmt.Errorf("目标 %s 未协商HTTP/2协议", addr)
+				}
+				return tlsConn, nil
+			},
+		},
+	}
+	transportCache.Store(key, rt)
+	return rt, nil
+}
+
+// SendRequestH2C 发送HTTP/2请求，http地址使用明文HTTP/2(h2c)先验知识方式，https地址通过TLS协商h2，遵循代理配置
+func SendRequestH2C(ctx context.Context, Method string, UrlStr string, Body string, options OptionsRequest) (*http.Response, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
+	setDefaults(&options)
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, Method, UrlStr, Body)
+	if err != nil {
+		return nil, err
+	}
+	configureHeaders(req, options)
+
+	transport, err := h2Transport(options.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport:     transport,
+		Timeout:       options.Timeout,
+		CheckRedirect: createRedirectPolicy(options.FollowRedirects),
+	}
+	resp, err := client.Do(req.Request)
+	logResponse(req.Method, req.URL.String(), resp, err)
+	return resp, err
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// protoHandler 返回请求使用的协议与地址协议
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	_, _ = io.WriteString(w, r.Proto+" "+scheme)
+})
+
+// connectProxy 仅支持CONNECT方法的测试代理，记录收到的隧道目标地址
+type connectProxy struct {
+	mu      sync.Mutex
+	targets []string
+}
+
+func (p *connectProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		http.Error(w, "only CONNECT", http.StatusMethodNotAllowed)
+		return
+	}
+	p.mu.Lock()
+	p.targets = append(p.targets, r.Host)
+	p.mu.Unlock()
+
+	upstream, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		return
+	}
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		_ = upstream.Close()
+	}()
+	_, _ = io.Copy(conn, upstream)
+	_ = conn.Close()
+}
+
+func sendH2(t *testing.T, target, proxy string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := SendRequestH2C(ctx, http.MethodGet, target, "", OptionsRequest{Proxy: proxy, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("请求 %s 失败: %v", target, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestSendRequestH2CKeepsHTTPS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(protoHandler)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	if got := sendH2(t, srv.URL, ""); got != "HTTP/2.0 https" {
+		t.Errorf("https目标应通过TLS协商h2，实际 %q", got)
+	}
+}
+
+func TestSendRequestH2CUsesProxy(t *testing.T) {
+	plain := httptest.NewServer(h2c.NewHandler(protoHandler, &http2.Server{}))
+	defer plain.Close()
+	secure := httptest.NewUnstartedServer(protoHandler)
+	secure.EnableHTTP2 = true
+	secure.StartTLS()
+	defer secure.Close()
+
+	p := &connectProxy{}
+	proxySrv := httptest.NewServer(p)
+	defer proxySrv.Close()
+
+	if got := sendH2(t, plain.URL, proxySrv.URL); got != "HTTP/2.0 http" {
+		t.Errorf("经代理的h2c请求结果 = %q", got)
+	}
+	if got := sendH2(t, secure.URL, proxySrv.URL); got != "HTTP/2.0 https" {
+		t.Errorf("经代理的h2请求结果 = %q", got)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	want := []string{plain.Listener.Addr().String(), secure.Listener.Addr().String()}
+	if len(p.targets) != len(want) || p.targets[0] != want[0] || p.targets[1] != want[1] {
+		t.Errorf("代理收到的CONNECT目标 = %v，期望 %v", p.targets, want)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,13 +29,29 @@ var (
 	transportCache sync.Map              // 缓存Transport对象，避免重复创建
 )
 
+// 协议判断结果缓存
+var (
+	protocolCache      = make(map[string]protocolEntry) // 主机端口到协议判断结果的缓存
+	protocolCacheMutex sync.Mutex                       // 保护协议判断结果缓存
+	// probeProtocol 实际探测目标协议的函数
+	probeProtocol = checkAndReturnProtocol
+)
+
+// protocolEntry 协议判断结果缓存条目
+type protocolEntry struct {
+	prefix  string    // 协议前缀，http:// 或 https://
+	expires time.Time // 过期时间
+}
+
 // 全局客户端配置
 const (
-	MaxDefaultBody int64 = 512 * 1024       // 512KB
-	DefaultTimeout       = 10 * time.Second // 默认请求超时时间
-	HttpPrefix           = "http://"        // HTTP协议前缀
-	HttpsPrefix          = "https://"       // HTTPS协议前缀
-	maxRedirects         = 5                // 最大重定向次数
+	MaxDefaultBody   int64 = 512 * 1024       // 512KB
+	DefaultTimeout         = 10 * time.Second // 默认请求超时时间
+	HttpPrefix             = "http://"        // HTTP协议前缀
+	HttpsPrefix            = "https://"       // HTTPS协议前缀
+	maxRedirects           = 5                // 最大重定向次数
+	protocolCacheTTL       = 10 * time.Minute // 协议判断结果缓存有效期
+	protocolCacheMax       = 10000            // 协议判断结果缓存的最大条目数，写入时超出上限先清理过期条目
 )
 
 // OptionsRequest 请求配置参数结构体
@@ -41,6 +59,7 @@ type OptionsRequest struct {
 	Proxy              string            // 代理地址，格式：scheme://host:port
 	Timeout            time.Duration     // 请求超时时间（默认5秒）
 	Retries            int               // 最大重试次数（默认3次）
+	ExactRetries       bool              // 按 Retries 设置客户端最大重试次数（0表示不重试），未设置时使用客户端默认重试次数
 	FollowRedirects    bool              // 是否跟随重定向（默认true）
 	InsecureSkipVerify bool              // 是否跳过SSL证书验证（默认true）
 	CustomHeaders      map[string]string // 自定义请求头
@@ -57,27 +76,7 @@ func init() {
 // initGlobalClient 初始化全局客户端实例
 func initGlobalClient() {
 	// 设置全局默认的TLS配置
-	tlsConfig = &tls.Config{
-		InsecureSkipVerify: true,
-		MinVersion:         tls.VersionTLS10,
-		CipherSuites: []uint16{
-			tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
-			tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
-			tls.TLS_RSA_WITH_AES_128_CBC_SHA,
-			tls.TLS_RSA_WITH_AES_256_CBC_SHA,
-			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
-			tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
-			tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
-			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
-			tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
-			tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
-			tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
-			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
-		},
-	}
+	tlsConfig = newTLSConfig(tls.VersionTLS10, false)
 
 	opts := retryablehttp.DefaultOptionsSingle
 	opts.Timeout = DefaultTimeout
@@ -94,27 +93,55 @@ func initGlobalClient() {
 
 // NewRequestHttp 创建并发送HTTP请求
 func NewRequestHttp(urlStr string, options OptionsRequest) (*http.Response, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
 	setDefaults(&options)
 	if options.Proxy != "" {
 		logger.Debugf("使用代理：%s", options.Proxy)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
-	defer cancel()
 
 	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	configureHeaders(req, options)
 
 	client := configureClient(options)
 
-	return client.Do(req)
+	resp, err := client.Do(req)
+	logResponse(req.Method, req.URL.String(), resp, err)
+	if direct, ok := directFallback(options); ok && err != nil {
+		logger.Debugf("经代理请求 %s 失败，尝试直连: %v", urlStr, err)
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), direct.Timeout)
+		// 直连重试沿用原请求的方法与请求头
+		directReq, reqErr := retryablehttp.NewRequestWithContext(ctx, req.Method, urlStr, nil)
+		if reqErr != nil {
+			cancel()
+			return nil, reqErr
+		}
+		directReq.Header = req.Header.Clone()
+		resp, err = configureClient(direct).Do(directReq)
+		logResponse(directReq.Method, directReq.URL.String(), resp, err)
+	}
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	// 响应体读取完毕关闭时再释放请求的上下文
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
 }
 
 // SendRequestHttp yaml poc or 指纹 yaml 构建发送http请求
 func SendRequestHttp(ctx context.Context, Method string, UrlStr string, Body string, options OptionsRequest) (*http.Response, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
 	setDefaults(&options)
 	if options.Proxy != "" {
 		logger.Debugf("使用代理：%s", options.Proxy)
@@ -127,7 +154,60 @@ func SendRequestHttp(ctx context.Context, Method string, UrlStr string, Body str
 
 	client := configureClient(options)
 
-	return client.Do(req)
+	resp, err := client.Do(req)
+	logResponse(req.Method, req.URL.String(), resp, err)
+	// 调用方上下文已取消或超时时不再直连重试，直连请求同样受调用方上下文约束
+	if direct, ok := directFallback(options); ok && err != nil && ctx.Err() == nil {
+		logger.Debugf("经代理请求 %s 失败，尝试直连: %v", UrlStr, err)
+		directCtx, cancel := context.WithTimeout(ctx, direct.Timeout)
+		req, err = retryablehttp.NewRequestWithContext(directCtx, Method, UrlStr, Body)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		configureHeaders(req, direct)
+		resp, err = configureClient(direct).Do(req)
+		logResponse(req.Method, req.URL.String(), resp, err)
+		if err != nil {
+			cancel()
+			return resp, err
+		}
+		// 响应体读取完毕关闭时再释放直连请求的上下文
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	}
+	return resp, err
+}
+
+// cancelOnClose 关闭响应体时同时取消对应请求的上下文
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并取消上下文
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// httpsTimeoutMultiplier https目标的超时倍数，用于补偿TLS握手耗时
+var httpsTimeoutMultiplier = 1.0
+
+// SetHTTPSTimeoutMultiplier 设置https目标的超时倍数，小于等于0时按1处理
+func SetHTTPSTimeoutMultiplier(multiplier float64) {
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	httpsTimeoutMultiplier = multiplier
+}
+
+// ScaleTimeout 按目标协议返回实际使用的超时时间，https目标乘以超时倍数
+func ScaleTimeout(target string, timeout time.Duration) time.Duration {
+	if httpsTimeoutMultiplier == 1 || !strings.HasPrefix(strings.ToLower(target), HttpsPrefix) {
+		return timeout
+	}
+	return time.Duration(float64(timeout) * httpsTimeoutMultiplier)
 }
 
 // setDefaults 设置配置参数的默认值
@@ -136,7 +216,7 @@ func setDefaults(options *OptionsRequest) {
 		options.Timeout = 5 * time.Second
 	}
 
-	if options.Retries == 0 {
+	if options.Retries == 0 && !options.ExactRetries {
 		options.Retries = 2
 	}
 
@@ -144,9 +224,60 @@ func setDefaults(options *OptionsRequest) {
 	options.InsecureSkipVerify = true
 }
 
+// spoofIPMode X-Forwarded-For 伪造来源IP模式：空表示不添加，ipv4/ipv6 固定地址族，mixed 以IPv4为主偶尔使用IPv6
+var spoofIPMode string
+
+// SetSpoofIP 设置请求头中伪造来源IP的模式，支持 off、ipv4、ipv6、mixed
+func SetSpoofIP(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "", "off":
+		spoofIPMode = ""
+	case "ipv4", "ipv6", "mixed":
+		spoofIPMode = mode
+	default:
+		return fmt.Errorf("不支持的伪造IP模式: %s，仅支持 off、ipv4、ipv6、mixed", mode)
+	}
+	return nil
+}
+
+// randomSpoofIP 按伪造模式生成随机来源IP，未开启时返回空串
+func randomSpoofIP() string {
+	switch spoofIPMode {
+	case "ipv4":
+		return common.GetRandomIP()
+	case "ipv6":
+		return common.GetRandomIPv6()
+	case "mixed":
+		// 约四分之一的请求使用IPv6
+		if rand.Intn(4) == 0 {
+			return common.GetRandomIPv6()
+		}
+		return common.GetRandomIP()
+	}
+	return ""
+}
+
 // configureHeaders 配置请求头信息
 func configureHeaders(req *retryablehttp.Request, options OptionsRequest) {
 	// 设置通用请求头
+	for k, v := range DefaultRequestHeaders() {
+		req.Header.Set(k, v)
+	}
+
+	// 默认POST内容类型
+	if req.Method == http.MethodPost && req.Header.Get("Content-Type") == "" {
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	// 添加自定义headers
+	for key, value := range options.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+}
+
+// DefaultRequestHeaders 返回每次请求附带的通用请求头，包含随机 User-Agent 与按配置伪造的 X-Forwarded-For
+func DefaultRequestHeaders() map[string]string {
 	headers := map[string]string{
 		"User-Agent": common.RandomUA(),
 		//"Accept":          "application/x-shockwave-flash, image/gif, image/x-xbitmap, image/jpeg, image/pjpeg, application/vnd.ms-excel, application/vnd.ms-powerpoint, application/msword, */*",
@@ -158,19 +289,25 @@ func configureHeaders(req *retryablehttp.Request, options OptionsRequest) {
 		"Connection":    "close", // 确保每次请求后不保持连接
 	}
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	if ip := randomSpoofIP(); ip != "" {
+		headers["X-Forwarded-For"] = ip
 	}
+	return headers
+}
 
-	// 默认POST内容类型
-	if req.Method == http.MethodPost && req.Header.Get("Content-Type") == "" {
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+// ContentLength 获取响应头声明的 Content-Length，未声明或无法解析时返回 -1
+// 自动解压gzip时标准库会移除该响应头，此时同样返回 -1
+func ContentLength(resp *http.Response) int64 {
+	if resp == nil {
+		return -1
 	}
-
-	// 添加自定义headers
-	for key, value := range options.CustomHeaders {
-		req.Header.Set(key, value)
+	if resp.ContentLength >= 0 {
+		return resp.ContentLength
+	}
+	if n, err := strconv.ParseInt(strings.TrimSpace(resp.Header.Get("Content-Length")), 10, 64); err == nil && n >= 0 {
+		return n
 	}
+	return -1
 }
 
 // createTransport 创建传输层
@@ -190,6 +327,10 @@ func createTransport(proxyURL string) (*http.Transport, error) {
 			IdleConnTimeout:     90 * time.Second,
 			DisableKeepAlives:   true, // 禁用连接复用，避免"Unsolicited response"错误
 		}
+		// 未指定代理时按需使用环境变量代理
+		if useEnvProxy {
+			transport.Proxy = envProxyFunc
+		}
 	} else {
 		httpProxy, err := url.Parse(proxyURL)
 		if err != nil {
@@ -204,13 +345,24 @@ func createTransport(proxyURL string) (*http.Transport, error) {
 		}
 
 		transport = &http.Transport{
-			Proxy:               http.ProxyURL(httpProxy),
+			Proxy:               proxyFunc(httpProxy),
 			TLSClientConfig:     tlsConfig,
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 10,
 			IdleConnTimeout:     90 * time.Second,
 			DisableKeepAlives:   true, // 禁用连接复用，避免"Unsolicited response"错误
 		}
+
+		// HTTPS代理由拨号函数自行完成TLS握手，与目标站点的证书校验配置相互独立
+		if strings.EqualFold(httpProxy.Scheme, "https") {
+			transport.Proxy = proxyFunc(plainProxyURL(httpProxy))
+			transport.DialContext = proxyTLSDialContext(baseDialContext(), httpProxy)
+		}
+	}
+
+	// 启用DNS缓存时使用带缓存的拨号函数
+	if transport.DialContext == nil && dnsCacheTTL > 0 {
+		transport.DialContext = baseDialContext()
 	}
 
 	// 存入缓存
@@ -229,6 +381,10 @@ func configureClient(options OptionsRequest) *retryablehttp.Client {
 	// 创建新的客户端实例以避免修改全局设置
 	opts := retryablehttp.DefaultOptionsSingle
 	opts.Timeout = options.Timeout
+	// 仅显式指定时覆盖重试次数，其他请求沿用客户端默认值
+	if options.ExactRetries {
+		opts.RetryMax = options.Retries
+	}
 
 	// 创建新的客户端
 	client := retryablehttp.NewClient(opts)
@@ -261,11 +417,16 @@ func createRedirectPolicy(followRedirects bool) func(*http.Request, []*http.Requ
 			return http.ErrUseLastResponse // 禁止重定向
 		}
 
-		// 从之前的响应中获取Set-Cookie并添加到请求中
+		// 从之前的响应中获取Set-Cookie并添加到请求中，req.Response 为触发本次重定向的响应
 		if len(via) > 0 {
+			responses := make([]*http.Response, 0, len(via))
 			for _, prevReq := range via {
-				if prevReq.Response != nil && len(prevReq.Response.Header["Set-Cookie"]) > 0 {
-					for _, cookie := range prevReq.Response.Cookies() {
+				responses = append(responses, prevReq.Response)
+			}
+			responses = append(responses, req.Response)
+			for _, prevResp := range responses {
+				if prevResp != nil && len(prevResp.Header["Set-Cookie"]) > 0 {
+					for _, cookie := range prevResp.Cookies() {
 						req.AddCookie(cookie)
 					}
 				}
@@ -277,10 +438,34 @@ func createRedirectPolicy(followRedirects bool) func(*http.Request, []*http.Requ
 			return fmt.Errorf("达到最大重定向次数: %d", maxRedirects)
 		}
 
+		// 检测 A→B→A 形式的重定向循环，提前终止以免耗尽重定向次数
+		if isRedirectLoop(req, via) {
+			return fmt.Errorf("检测到重定向循环: %s", req.URL.String())
+		}
+
 		return nil
 	}
 }
 
+// isRedirectLoop 判断本次重定向目标是否已在重定向链中出现过
+// 上一跳响应设置了Cookie时允许重复访问，兼容先种Cookie再跳回原地址的登录流程
+func isRedirectLoop(req *http.Request, via []*http.Request) bool {
+	if len(via) == 0 {
+		return false
+	}
+	// req.Response 为触发本次重定向的响应
+	if req.Response != nil && len(req.Response.Header["Set-Cookie"]) > 0 {
+		return false
+	}
+	target := req.URL.String()
+	for _, prevReq := range via {
+		if prevReq.URL.String() == target {
+			return true
+		}
+	}
+	return false
+}
+
 // ReverseGet 发送GET请求并返回响应内容
 func ReverseGet(target string) ([]byte, error) {
 	if target == "" {
@@ -293,6 +478,9 @@ func ReverseGet(target string) ([]byte, error) {
 
 // simpleRetryHttpGet 简化版HTTP GET请求实现
 func simpleRetryHttpGet(target string, proxy string, timeout int32) ([]byte, int, error) {
+	if IsOffline() {
+		return nil, 0, ErrOffline
+	}
 	client := RetryClient
 	if client == nil {
 		initGlobalClient()
@@ -326,6 +514,7 @@ func simpleRetryHttpGet(target string, proxy string, timeout int32) ([]byte, int
 	req.Header.Set("Connection", "close")
 
 	resp, err := client.Do(req)
+	logResponse(req.Method, target, resp, err)
 	if err != nil {
 		if resp != nil {
 			defer func(Body io.ReadCloser) {
@@ -363,19 +552,75 @@ func CheckProtocol(host string, proxy string) (string, error) {
 		return "", err
 	}
 
+	// 同一主机端口的协议判断结果在有效期内复用，避免多路径目标重复探测
+	cacheKey := proxy + "|" + strings.ToLower(u.Host)
+	if prefix, ok := cachedProtocol(cacheKey); ok {
+		return prefix + host, nil
+	}
+
+	var result string
 	switch u.Port() {
 	case "80":
-		return checkAndReturnProtocol(HttpPrefix+host, proxy)
+		result, err = probeProtocol(HttpPrefix+host, proxy)
 	case "443":
-		return checkAndReturnProtocol(HttpsPrefix+host, proxy)
+		result, err = probeProtocol(HttpsPrefix+host, proxy)
 	default:
-		if result, err := checkAndReturnProtocol(HttpsPrefix+host, proxy); err == nil {
-			return result, nil
+		if result, err = probeProtocol(HttpsPrefix+host, proxy); err != nil {
+			result, err = probeProtocol(HttpPrefix+host, proxy)
 		}
-		return checkAndReturnProtocol(HttpPrefix+host, proxy)
 	}
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(result, HttpsPrefix) {
+		storeProtocol(cacheKey, HttpsPrefix)
+	} else {
+		storeProtocol(cacheKey, HttpPrefix)
+	}
+	return result, nil
 }
+
+// cachedProtocol 查询主机端口已确定的协议前缀
+func cachedProtocol(key string) (string, bool) {
+	protocolCacheMutex.Lock()
+	defer protocolCacheMutex.Unlock()
+	entry, ok := protocolCache[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expires) {
+		delete(protocolCache, key)
+		return "", false
+	}
+	return entry.prefix, true
+}
+
+// storeProtocol 缓存主机端口的协议判断结果，达到上限时清理过期条目，仍然已满则随机淘汰一条
+func storeProtocol(key, prefix string) {
+	protocolCacheMutex.Lock()
+	defer protocolCacheMutex.Unlock()
+	if _, ok := protocolCache[key]; !ok && len(protocolCache) >= protocolCacheMax {
+		now := time.Now()
+		for k, e := range protocolCache {
+			if now.After(e.expires) {
+				delete(protocolCache, k)
+			}
+		}
+		for k := range protocolCache {
+			if len(protocolCache) < protocolCacheMax {
+				break
+			}
+			delete(protocolCache, k)
+		}
+	}
+	protocolCache[key] = protocolEntry{prefix: prefix, expires: time.Now().Add(protocolCacheTTL)}
+}
+
 func CheckProtocolGet(target string, proxy string, timeout int) (string, error) {
+	if IsOffline() {
+		return "", ErrOffline
+	}
 	client := RetryClient
 	if client == nil {
 		initGlobalClient()
@@ -410,6 +655,7 @@ func CheckProtocolGet(target string, proxy string, timeout int) (string, error)
 	req.Header.Set("Connection", "close")
 
 	resp, err := client.Do(req)
+	logResponse(req.Method, target, resp, err)
 	if err != nil {
 		return "", err
 	}
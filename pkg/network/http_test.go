@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExactRetries(t *testing.T) {
+	// 每次连接直接断开，使请求失败并触发重试
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	for _, retries := range []int{0, 1} {
+		attempts.Store(0)
+		options := OptionsRequest{Timeout: 5 * time.Second, Retries: retries, ExactRetries: true}
+		resp, err := SendRequestHttp(context.Background(), http.MethodGet, srv.URL, "", options)
+		if err == nil {
+			_ = resp.Body.Close()
+			t.Fatal("连接被断开时请求应失败")
+		}
+		if got := attempts.Load(); got != int32(retries+1) {
+			t.Errorf("Retries=%d 时请求次数 = %d，期望 %d", retries, got, retries+1)
+		}
+	}
+}
+
+func TestSetDefaultsRetries(t *testing.T) {
+	options := OptionsRequest{}
+	setDefaults(&options)
+	if options.Retries != 2 {
+		t.Errorf("未指定重试次数时默认值 = %d，期望 2", options.Retries)
+	}
+	options = OptionsRequest{ExactRetries: true}
+	setDefaults(&options)
+	if options.Retries != 0 {
+		t.Errorf("ExactRetries 时 0 表示不重试，实际 %d", options.Retries)
+	}
+}
+
+// stubProbeProtocol 替换实际的协议探测函数并清空协议缓存，测试结束后恢复
+func stubProbeProtocol(t *testing.T, fn func(url string, proxy string) (string, error)) {
+	t.Helper()
+	orig := probeProtocol
+	probeProtocol = fn
+	resetProtocolCache := func() {
+		protocolCacheMutex.Lock()
+		protocolCache = make(map[string]protocolEntry)
+		protocolCacheMutex.Unlock()
+	}
+	resetProtocolCache()
+	t.Cleanup(func() {
+		probeProtocol = orig
+		resetProtocolCache()
+	})
+}
+
+func TestCheckProtocolProbesOncePerHostPort(t *testing.T) {
+	probes := make(map[string]int)
+	stubProbeProtocol(t, func(url string, proxy string) (string, error) {
+		probes[url]++
+		return url, nil
+	})
+
+	targets := []string{
+		"example.com:80/admin",
+		"example.com:80/login",
+		"EXAMPLE.com:80/api/v1",
+		"example.com:8080/a",
+		"example.com:8080/b",
+	}
+	for _, target := range targets {
+		if _, err := CheckProtocol(target, ""); err != nil {
+			t.Fatalf("CheckProtocol(%s) 失败: %v", target, err)
+		}
+	}
+	if len(probes) != 2 {
+		t.Errorf("应对每个主机端口各探测一次，实际探测 %v", probes)
+	}
+	for url, n := range probes {
+		if n != 1 {
+			t.Errorf("%s 被探测 %d 次，期望 1 次", url, n)
+		}
+	}
+	if got, _ := CheckProtocol("example.com:80/other", ""); got != "http://example.com:80/other" {
+		t.Errorf("命中缓存时应保留原目标路径，实际 %s", got)
+	}
+}
+
+func TestProtocolCacheBounded(t *testing.T) {
+	stubProbeProtocol(t, func(url string, proxy string) (string, error) {
+		return url, nil
+	})
+
+	for i := 0; i < protocolCacheMax+50; i++ {
+		if _, err := CheckProtocol(fmt.Sprintf("host%d.example.com:80", i), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	protocolCacheMutex.Lock()
+	n := len(protocolCache)
+	protocolCacheMutex.Unlock()
+	if n > protocolCacheMax {
+		t.Errorf("协议缓存条目数 = %d，超过上限 %d", n, protocolCacheMax)
+	}
+}
+
+func TestRedirectPolicyLoop(t *testing.T) {
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	// /a 与 /b 互相跳转
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+	// 未登录时先跳转种Cookie，再跳回首页
+	mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			http.Redirect(w, r, "/set-cookie", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("home"))
+	})
+	mux.HandleFunc("/set-cookie", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := &http.Client{CheckRedirect: createRedirectPolicy(true)}
+
+	resp, err := client.Get(srv.URL + "/a")
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("重定向循环时应返回错误")
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("循环请求次数 = %d，期望检测到循环后立即终止（2 次）", got)
+	}
+
+	resp, err = client.Get(srv.URL + "/home")
+	if err != nil {
+		t.Fatalf("种Cookie后跳回原地址不应视为循环: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/home" {
+		t.Errorf("最终响应 = %d %s，期望 200 /home", resp.StatusCode, resp.Request.URL.Path)
+	}
+}
+
+func TestScaleTimeout(t *testing.T) {
+	defer SetHTTPSTimeoutMultiplier(1)
+
+	tests := []struct {
+		name       string
+		multiplier float64
+		target     string
+		want       time.Duration
+	}{
+		{"https目标按倍数延长", 1.5, "https://example.com", 15 * time.Second},
+		{"协议不区分大小写", 1.5, "HTTPS://example.com", 15 * time.Second},
+		{"http目标不调整", 1.5, "http://example.com", 10 * time.Second},
+		{"未识别协议不调整", 1.5, "example.com:443", 10 * time.Second},
+		{"倍数为0按1处理", 0, "https://example.com", 10 * time.Second},
+		{"倍数为负数按1处理", -2, "https://example.com", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetHTTPSTimeoutMultiplier(tt.multiplier)
+			if got := ScaleTimeout(tt.target, 10*time.Second); got != tt.want {
+				t.Errorf("ScaleTimeout(%s) = %v，期望 %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRequestHeadersSpoofIP(t *testing.T) {
+	defer func() { _ = SetSpoofIP("off") }()
+
+	tests := []struct {
+		mode     string
+		wantIPv6 bool
+	}{
+		{"ipv4", false},
+		{"ipv6", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if err := SetSpoofIP(tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 20; i++ {
+				value := DefaultRequestHeaders()["X-Forwarded-For"]
+				ip := net.ParseIP(value)
+				if ip == nil || (ip.To4() == nil) != tt.wantIPv6 {
+					t.Fatalf("X-Forwarded-For = %q，期望IPv6 = %v", value, tt.wantIPv6)
+				}
+			}
+		})
+	}
+
+	if err := SetSpoofIP("off"); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := DefaultRequestHeaders()["X-Forwarded-For"]; ok {
+		t.Errorf("关闭伪造时不应携带 X-Forwarded-For，实际 %q", value)
+	}
+	if err := SetSpoofIP("ipv5"); err == nil {
+		t.Error("不支持的伪造模式应返回错误")
+	}
+}
@@ -2,6 +2,7 @@ package network
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"strings"
@@ -20,6 +21,8 @@ const (
 	DefaultReadTimeout  = 5 * time.Second
 	DefaultRetryDelay   = 2 * time.Second
 	DefaultReadSize     = 2048
+	DefaultMaxReadSize  = 1 << 20 // 未指定读取长度时单次接收的数据上限
+	DefaultIdleTimeout  = 500 * time.Millisecond
 	DefaultMaxRetries   = 3
 )
 
@@ -27,21 +30,29 @@ const (
 type TcpOrUdpConfig struct {
 	Network      string        // 网络类型，TCP 或 UDP
 	MaxRetries   int           // 最大重试次数
-	ReadSize     int           // 读取数据的缓冲区大小
+	ReadSize     int           // 读取数据的最大长度，0表示使用 DefaultMaxReadSize
 	DialTimeout  time.Duration // 连接超时时间
 	WriteTimeout time.Duration // 写入超时时间
 	ReadTimeout  time.Duration // 读取超时时间
+	IdleTimeout  time.Duration // 已收到数据后等待后续数据的空闲超时
 	RetryDelay   time.Duration // 重试延迟时间
 	ProxyURL     string        // 代理URL
 	IsLts        bool          // 是否发送LTS请求
 	ServerName   string        // ServerName对tls请求的配置
+	TLSVersion   uint16        // 固定使用的TLS版本，0表示使用默认最低版本并自动协商
 }
 
 // Client 客户端结构体
 type Client struct {
-	address string
-	conn    net.Conn
-	conf    TcpOrUdpConfig
+	address    string
+	conn       net.Conn
+	conf       TcpOrUdpConfig
+	lastPacket []byte // UDP最近一次发送的数据报，读取超时后在同一socket上重发
+}
+
+// isPacketNetwork 判断是否为无连接的数据报网络
+func isPacketNetwork(network string) bool {
+	return strings.HasPrefix(network, "udp")
 }
 
 // parseAddress 解析地址，确保包含端口号
@@ -71,6 +82,9 @@ func parseAddress(address string) string {
 
 // NewClient 创建新客户端
 func NewClient(address string, conf TcpOrUdpConfig) (*Client, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
 	var (
 		err  error
 		conn net.Conn
@@ -99,8 +113,14 @@ func NewClient(address string, conf TcpOrUdpConfig) (*Client, error) {
 	// 创建Dialer
 	var dialer proxy.Dialer = &net.Dialer{Timeout: conf.DialTimeout}
 
-	// 处理代理
-	if conf.ProxyURL != "" {
+	// 代理拨号器仅支持流式连接，UDP无法经代理发送，直接拒绝以免绕过代理直连目标
+	useProxy := conf.ProxyURL != "" && !ShouldBypassProxy(address)
+	if useProxy && isPacketNetwork(conf.Network) {
+		return nil, ErrUDPProxy
+	}
+
+	// 处理代理，命中绕过列表时直连
+	if useProxy {
 		proxyURL, err := url.Parse(conf.ProxyURL)
 		if err != nil {
 			return nil, fmt.Errorf("invalid proxy URL: %w", err)
@@ -118,11 +138,11 @@ func NewClient(address string, conf TcpOrUdpConfig) (*Client, error) {
 		if err == nil {
 			if conf.Network == "tcp" && conf.IsLts {
 				// 使用TLS
-				tlsConn := tls.Client(conn, &tls.Config{
-					InsecureSkipVerify: true,
-					ServerName:         conf.ServerName, // 动态配置 ServerName
-				})
+				tlsConn := tls.Client(conn, clientTLSConfig(conf))
+				// 握手同样受连接超时限制，避免对端不响应时永久阻塞
+				_ = conn.SetDeadline(time.Now().Add(conf.DialTimeout))
 				err = tlsConn.Handshake()
+				_ = conn.SetDeadline(time.Time{})
 				if err == nil {
 					conn = tlsConn
 					break
@@ -133,9 +153,18 @@ func NewClient(address string, conf TcpOrUdpConfig) (*Client, error) {
 				break
 			}
 		}
-		time.Sleep(conf.RetryDelay)
+		// 最后一次尝试失败后无需等待
+		if i < conf.MaxRetries-1 {
+			time.Sleep(conf.RetryDelay)
+		}
 	}
 
+	// 每次建立连接记录一条审计日志，仅读取banner的规则不会发送数据
+	network := conf.Network
+	if conf.IsLts {
+		network = "tls"
+	}
+	logConnRequest(network, address, err)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +180,15 @@ func (c *Client) Send(data []byte) error {
 
 	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
 	_, err := c.conn.Write(data)
+
+	// UDP无连接，写入失败直接返回，不进行重新拨号
+	if isPacketNetwork(c.network()) {
+		if err == nil {
+			c.lastPacket = append(c.lastPacket[:0], data...)
+		}
+		return err
+	}
+
 	if err != nil {
 		var ne net.Error
 		if errors.As(err, &ne) && ne.Timeout() {
@@ -161,19 +199,72 @@ func (c *Client) Send(data []byte) error {
 	return nil
 }
 
-// Receive 接收数据
+// Receive 接收数据，循环读取直至连接关闭、达到读取上限或空闲超时
 func (c *Client) Receive() ([]byte, error) {
 	if c.conn == nil {
 		return nil, errors.New("connection is not established")
 	}
+	if isPacketNetwork(c.network()) {
+		return c.receivePacket()
+	}
 
-	_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
+	limit := c.readLimit()
+	chunk := make([]byte, min(DefaultReadSize, limit))
+	data := make([]byte, 0, len(chunk))
+	timeout := c.readTimeout()
+	for len(data) < limit {
+		_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+		n, err := c.conn.Read(chunk[:min(len(chunk), limit-len(data))])
+		data = append(data, chunk[:n]...)
+		if err != nil {
+			// 已收到数据时，连接关闭或空闲超时均视为接收完成
+			if len(data) > 0 {
+				break
+			}
+			return nil, c.retryRead(chunk)
+		}
+		// 收到首段数据后改用较短的空闲超时等待后续数据
+		timeout = c.idleTimeout()
+	}
+	return data, nil
+}
+
+// receivePacket 在同一socket上读取一个UDP数据报，超时后重发最近一次的数据报再读取，直至达到最大尝试次数
+func (c *Client) receivePacket() ([]byte, error) {
 	buf := make([]byte, c.readSize())
-	n, err := c.conn.Read(buf)
-	if err != nil {
-		return nil, c.retryRead(buf)
+	var err error
+	for i := 0; i < c.maxRetries(); i++ {
+		if i > 0 && len(c.lastPacket) > 0 {
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
+			if _, err = c.conn.Write(c.lastPacket); err != nil {
+				return nil, err
+			}
+		}
+
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
+		var n int
+		n, err = c.conn.Read(buf)
+		if err == nil {
+			return buf[:n], nil
+		}
+		var ne net.Error
+		if !errors.As(err, &ne) || !ne.Timeout() {
+			return nil, err
+		}
 	}
-	return buf[:n], nil
+	return nil, fmt.Errorf("failed to receive udp data after %d attempts: %w", c.maxRetries(), err)
+}
+
+// PeerCertificates 返回TLS连接中对端提供的证书链，非TLS连接返回nil
+func (c *Client) PeerCertificates() []*x509.Certificate {
+	if c.conn == nil {
+		return nil
+	}
+	tlsConn, ok := c.conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	return tlsConn.ConnectionState().PeerCertificates
 }
 
 // Close 关闭连接
@@ -267,6 +358,22 @@ func (c *Client) readSize() int {
 	return DefaultReadSize
 }
 
+func (c *Client) readLimit() int {
+	if c.conf.ReadSize > 0 {
+		return c.conf.ReadSize
+	}
+	return DefaultMaxReadSize
+}
+
+func (c *Client) idleTimeout() time.Duration {
+	timeout := DefaultIdleTimeout
+	if c.conf.IdleTimeout != 0 {
+		timeout = c.conf.IdleTimeout
+	}
+	// 空闲超时不超过读取超时
+	return min(timeout, c.readTimeout())
+}
+
 // NewTcpClient 创建新的TCP客户端
 func NewTcpClient(address string, conf TcpOrUdpConfig) (*Client, error) {
 	conf.Network = "tcp"
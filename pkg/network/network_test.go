@@ -0,0 +1,154 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestUDPClientResendsOnTimeout(t *testing.T) {
+	// 丢弃第一个数据报，收到重发的数据报后回复
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	received := make(chan string, 4)
+	go func() {
+		buf := make([]byte, 64)
+		for i := 0; ; i++ {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			received <- string(buf[:n])
+			if i > 0 {
+				_, _ = conn.WriteTo([]byte("pong"), addr)
+			}
+		}
+	}()
+
+	client, err := NewClient(conn.LocalAddr().String(), TcpOrUdpConfig{
+		Network:     "udp",
+		ReadTimeout: 200 * time.Millisecond,
+		MaxRetries:  3,
+	})
+	if err != nil {
+		t.Fatalf("创建UDP客户端失败: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendUDP([]byte("ping")); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	data, err := client.RecvUdp()
+	if err != nil {
+		t.Fatalf("接收失败: %v", err)
+	}
+	if string(data) != "pong" {
+		t.Errorf("响应 = %q，期望 pong", data)
+	}
+	if len(received) != 2 || <-received != "ping" || <-received != "ping" {
+		t.Error("读取超时后应在同一连接上重发原数据报")
+	}
+}
+
+func TestUDPClientRejectsProxy(t *testing.T) {
+	_, err := NewClient("127.0.0.1:53", TcpOrUdpConfig{Network: "udp", ProxyURL: "socks5://127.0.0.1:1080"})
+	if !errors.Is(err, ErrUDPProxy) {
+		t.Errorf("配置代理时 err = %v，期望 ErrUDPProxy", err)
+	}
+
+	// 命中代理绕过列表时直连
+	SetNoProxy([]string{"127.0.0.1"})
+	defer SetNoProxy(nil)
+	client, err := NewClient("127.0.0.1:53", TcpOrUdpConfig{Network: "udp", ProxyURL: "socks5://127.0.0.1:1080"})
+	if err != nil {
+		t.Fatalf("绕过代理的UDP请求应直连: %v", err)
+	}
+	_ = client.Close()
+}
+
+// serveTCP 启动TCP服务，每个连接交给 handle 处理后关闭
+func serveTCP(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestTCPReceiveLoop(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(net.Conn)
+		conf   TcpOrUdpConfig
+		want   string
+	}{
+		{
+			name: "分段发送直到连接关闭",
+			handle: func(conn net.Conn) {
+				_, _ = conn.Write([]byte("HTTP/1.0 200 OK\r\n"))
+				time.Sleep(50 * time.Millisecond)
+				_, _ = conn.Write([]byte("Server: banner\r\n\r\n"))
+			},
+			want: "HTTP/1.0 200 OK\r\nServer: banner\r\n\r\n",
+		},
+		{
+			name: "达到读取上限后停止",
+			handle: func(conn net.Conn) {
+				_, _ = conn.Write([]byte("0123456789"))
+				time.Sleep(time.Second)
+			},
+			conf: TcpOrUdpConfig{ReadSize: 4},
+			want: "0123",
+		},
+		{
+			name: "空闲超时后返回已收到的数据",
+			handle: func(conn net.Conn) {
+				_, _ = conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+				time.Sleep(2 * time.Second)
+				_, _ = conn.Write([]byte("late"))
+			},
+			conf: TcpOrUdpConfig{IdleTimeout: 100 * time.Millisecond},
+			want: "SSH-2.0-OpenSSH\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Network = "tcp"
+			client, err := NewClient(serveTCP(t, tt.handle), tt.conf)
+			if err != nil {
+				t.Fatalf("建立连接失败: %v", err)
+			}
+			defer client.Close()
+
+			start := time.Now()
+			data, err := client.RecvTcp()
+			if err != nil {
+				t.Fatalf("接收失败: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("接收数据 = %q，期望 %q", data, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("接收耗时 %v，期望在连接关闭、达到上限或空闲超时后立即返回", elapsed)
+			}
+		})
+	}
+}
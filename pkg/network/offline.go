@@ -0,0 +1,25 @@
+package network
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrOffline 离线模式下发起网络请求时返回的错误
+var ErrOffline = errors.New("离线模式下禁止发起网络请求")
+
+// ErrUDPProxy 配置代理时发起UDP请求返回的错误，代理不支持转发UDP数据报
+var ErrUDPProxy = errors.New("配置代理时无法发送UDP请求")
+
+// offline 是否处于离线模式，被动识别时开启，所有请求入口直接返回 ErrOffline
+var offline atomic.Bool
+
+// SetOffline 设置是否禁止一切网络请求
+func SetOffline(enabled bool) {
+	offline.Store(enabled)
+}
+
+// IsOffline 是否处于离线模式
+func IsOffline() bool {
+	return offline.Load()
+}
@@ -0,0 +1,326 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"xfirefly/pkg/utils/common"
+
+	"golang.org/x/net/proxy"
+)
+
+// 代理绕过配置
+var (
+	noProxyHosts  []string     // 直连的主机名或域名后缀
+	noProxyNets   []*net.IPNet // 直连的网段
+	noProxyAll    bool         // 列表包含 "*" 时所有主机均直连
+	noProxyMutex  sync.RWMutex // 保护绕过列表
+	useEnvProxy   bool         // 未指定代理时是否读取 HTTP_PROXY/HTTPS_PROXY 环境变量
+	proxyFallback bool         // 经代理请求失败时是否直连重试一次
+
+	// proxyFromEnvironment 读取环境变量代理配置，测试中可替换
+	proxyFromEnvironment = http.ProxyFromEnvironment
+)
+
+// SetProxyFallback 设置经代理请求失败时是否直连重试一次
+func SetProxyFallback(enabled bool) {
+	proxyFallback = enabled
+}
+
+// directFallback 返回代理请求失败后用于直连重试的请求配置，未开启回退或未使用代理时返回false
+func directFallback(options OptionsRequest) (OptionsRequest, bool) {
+	if !proxyFallback || options.Proxy == "" {
+		return options, false
+	}
+	options.Proxy = ""
+	return options, true
+}
+
+// SetEnvProxy 设置未指定代理时是否使用环境变量中的代理配置
+func SetEnvProxy(enabled bool) {
+	useEnvProxy = enabled
+	transportCache.Range(func(key, _ any) bool {
+		transportCache.Delete(key)
+		return true
+	})
+}
+
+// SetNoProxy 设置不走代理的主机列表，支持主机名、域名后缀(.example.com / *.example.com)、IP与CIDR，语义与 NO_PROXY 一致
+func SetNoProxy(hosts []string) {
+	noProxyMutex.Lock()
+	noProxyHosts = noProxyHosts[:0]
+	noProxyNets = noProxyNets[:0]
+	noProxyAll = false
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		// 单独的 "*" 表示所有主机均不走代理，需在去除通配前缀前判断
+		if host == "*" {
+			noProxyAll = true
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(host); err == nil {
+			noProxyNets = append(noProxyNets, ipNet)
+			continue
+		}
+		noProxyHosts = append(noProxyHosts, strings.TrimPrefix(host, "*"))
+	}
+	noProxyMutex.Unlock()
+
+	// 绕过列表变化后，已缓存的transport需要重新创建
+	transportCache.Range(func(key, _ any) bool {
+		transportCache.Delete(key)
+		return true
+	})
+}
+
+// ShouldBypassProxy 判断目标地址是否应当直连，address 可带端口
+func ShouldBypassProxy(address string) bool {
+	host := strings.ToLower(address)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+
+	noProxyMutex.RLock()
+	defer noProxyMutex.RUnlock()
+
+	if noProxyAll {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, ipNet := range noProxyNets {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range noProxyHosts {
+		if pattern == host {
+			return true
+		}
+		// .example.com 匹配 example.com 及其子域名
+		if strings.HasPrefix(pattern, ".") {
+			if host == pattern[1:] || strings.HasSuffix(host, pattern) {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(host, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyFunc 返回带绕过判断的代理选择函数
+func proxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if ShouldBypassProxy(req.URL.Host) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// envProxyFunc 从环境变量获取代理，同时遵循 --no-proxy 绕过列表
+func envProxyFunc(req *http.Request) (*url.URL, error) {
+	if ShouldBypassProxy(req.URL.Host) {
+		return nil, nil
+	}
+	return proxyFromEnvironment(req)
+}
+
+// UsesProxy 判断访问目标地址时是否经过代理，包括 --proxy 指定的代理与启用的环境变量代理
+func UsesProxy(proxyURL string, target *url.URL) bool {
+	if ShouldBypassProxy(target.Host) {
+		return false
+	}
+	if proxyURL != "" {
+		return true
+	}
+	if !useEnvProxy {
+		return false
+	}
+	u, err := proxyFromEnvironment(&http.Request{URL: target})
+	return err == nil && u != nil
+}
+
+// parseProxyURL 解析并校验代理地址的协议与主机
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("代理地址解析失败: %v", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("代理地址缺少主机: %s", proxyURL)
+	}
+	return u, nil
+}
+
+// CheckProxy 校验代理地址格式并尝试连接代理服务器，不发送任何HTTP请求
+func CheckProxy(proxyURL string, timeout time.Duration) error {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return err
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := baseDialContext()(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接代理 %s 失败: %v", addr, err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+// TestProxy 通过代理请求探测地址，验证代理地址格式正确且可用，收到任意HTTP响应即视为代理可用
+func TestProxy(proxyURL string, probeURL string, timeout time.Duration) error {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return err
+	}
+
+	transport := &http.Transport{
+		Proxy:             http.ProxyURL(u),
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+	}
+	// HTTPS代理使用代理专用TLS配置，与 createTransport 保持一致
+	if strings.EqualFold(u.Scheme, "https") {
+		transport.Proxy = http.ProxyURL(plainProxyURL(u))
+		transport.DialContext = proxyTLSDialContext(baseDialContext(), u)
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, probeURL, nil)
+	if err != nil {
+		return fmt.Errorf("探测地址解析失败: %v", err)
+	}
+	req.Header.Set("User-Agent", common.RandomUA())
+	resp, err := client.Do(req)
+	logResponse(req.Method, probeURL, resp, err)
+	if err != nil {
+		return fmt.Errorf("通过代理请求 %s 失败: %v", probeURL, err)
+	}
+	_ = resp.Body.Close()
+	// 代理自身返回的 407 表示认证失败
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		return fmt.Errorf("代理认证失败: %s", resp.Status)
+	}
+	return nil
+}
+
+// dialViaProxy 经代理建立到目标地址的TCP隧道，http/https代理使用CONNECT方法，socks5代理使用SOCKS握手
+func dialViaProxy(ctx context.Context, proxyURL *url.URL, network, addr string) (net.Conn, error) {
+	switch strings.ToLower(proxyURL.Scheme) {
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(proxyURL, &net.Dialer{Timeout: DefaultTimeout})
+		if err != nil {
+			return nil, fmt.Errorf("创建代理客户端失败: %v", err)
+		}
+		if cd, ok := dialer.(proxy.ContextDialer); ok {
+			return cd.DialContext(ctx, network, addr)
+		}
+		return dialer.Dial(network, addr)
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %q", proxyURL.Scheme)
+	}
+
+	dial := baseDialContext()
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if strings.EqualFold(proxyURL.Scheme, "https") {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	if strings.EqualFold(proxyURL.Scheme, "https") {
+		dial = proxyTLSDialContext(dial, proxyURL)
+	}
+	conn, err := dial(ctx, network, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// CONNECT握手同样受请求上下文约束
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("发送CONNECT请求失败: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("读取CONNECT响应失败: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("代理拒绝建立隧道: %s", resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn 读取时优先返回CONNECT响应之后已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
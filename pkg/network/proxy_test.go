@@ -0,0 +1,213 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUsesProxy(t *testing.T) {
+	SetNoProxy([]string{".internal.example.com", "10.0.0.0/8"})
+	defer SetNoProxy(nil)
+
+	tests := []struct {
+		proxy  string
+		target string
+		want   bool
+	}{
+		{"http://127.0.0.1:8080", "http://example.com", true},
+		{"http://127.0.0.1:8080", "http://app.internal.example.com", false},
+		{"http://127.0.0.1:8080", "http://10.1.2.3:8080", false},
+		{"", "http://example.com", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.target)
+		if got := UsesProxy(tt.proxy, u); got != tt.want {
+			t.Errorf("UsesProxy(%q, %s) = %v，期望 %v", tt.proxy, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestProxyFallbackDirect(t *testing.T) {
+	// 占用后立即关闭的端口，连接代理必然失败
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadProxy := "http://" + ln.Addr().String()
+	_ = ln.Close()
+
+	const body = "direct response body"
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "fallback" {
+			http.Error(w, "missing header", http.StatusBadRequest)
+			return
+		}
+		// 响应体分两次发送，确保调用方读取响应体时请求仍在进行
+		_, _ = io.WriteString(w, body[:6])
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, body[6:])
+	}))
+	defer origin.Close()
+
+	SetProxyFallback(true)
+	defer SetProxyFallback(false)
+
+	options := OptionsRequest{
+		Proxy:         deadProxy,
+		Timeout:       5 * time.Second,
+		ExactRetries:  true,
+		CustomHeaders: map[string]string{"X-Test": "fallback"},
+	}
+	send := map[string]func() (*http.Response, error){
+		"NewRequestHttp": func() (*http.Response, error) {
+			return NewRequestHttp(origin.URL, options)
+		},
+		"SendRequestHttp": func() (*http.Response, error) {
+			return SendRequestHttp(context.Background(), http.MethodGet, origin.URL, "", options)
+		},
+	}
+	for name, fn := range send {
+		resp, err := fn()
+		if err != nil {
+			t.Errorf("%s 代理不可用时应直连成功，实际错误: %v", name, err)
+			continue
+		}
+		got, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Errorf("%s 读取直连响应体失败: %v", name, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK || string(got) != body {
+			t.Errorf("%s 直连响应 = %d %q，期望 200 %q", name, resp.StatusCode, got, body)
+		}
+	}
+}
+
+func TestProxyFallbackExpiredContext(t *testing.T) {
+	// 代理迟迟不响应，调用方上下文先于代理请求超时
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer proxy.Close()
+
+	var direct atomic.Int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		direct.Add(1)
+	}))
+	defer origin.Close()
+
+	SetProxyFallback(true)
+	defer SetProxyFallback(false)
+
+	options := OptionsRequest{Proxy: proxy.URL, Timeout: 5 * time.Second, ExactRetries: true}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	resp, err := SendRequestHttp(ctx, http.MethodGet, origin.URL, "", options)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("调用方上下文超时后请求应失败")
+	}
+	if got := direct.Load(); got != 0 {
+		t.Errorf("调用方上下文超时后仍直连重试了 %d 次", got)
+	}
+}
+
+func TestShouldBypassProxy(t *testing.T) {
+	defer SetNoProxy(nil)
+
+	tests := []struct {
+		noProxy []string
+		address string
+		want    bool
+	}{
+		// 精确主机名，大小写不敏感
+		{[]string{"localhost"}, "LOCALHOST:8080", true},
+		{[]string{"example.com"}, "api.example.com", true},
+		{[]string{"example.com"}, "badexample.com", false},
+		// .example.com 与 *.example.com 匹配自身及子域名
+		{[]string{".example.com"}, "example.com", true},
+		{[]string{"*.example.com"}, "a.b.example.com:443", true},
+		{[]string{"*.example.com"}, "example.org", false},
+		// IP与CIDR
+		{[]string{"192.168.1.10"}, "192.168.1.10:80", true},
+		{[]string{"192.168.0.0/16"}, "192.168.200.1", true},
+		{[]string{"192.168.0.0/16"}, "10.0.0.1", false},
+		{[]string{"::1/128"}, "[::1]:8080", true},
+		// 单独的 * 表示全部直连
+		{[]string{" * "}, "anything.example.net", true},
+		{nil, "example.com", false},
+	}
+	for _, tt := range tests {
+		SetNoProxy(tt.noProxy)
+		if got := ShouldBypassProxy(tt.address); got != tt.want {
+			t.Errorf("SetNoProxy(%q) 后 ShouldBypassProxy(%q) = %v，期望 %v", tt.noProxy, tt.address, got, tt.want)
+		}
+	}
+
+	// 代理选择函数对绕过的主机返回空代理
+	SetNoProxy([]string{"direct.example.com"})
+	proxyURL, _ := url.Parse("http://127.0.0.1:8080")
+	choose := proxyFunc(proxyURL)
+	for host, want := range map[string]*url.URL{"direct.example.com": nil, "proxied.example.com": proxyURL} {
+		got, err := choose(&http.Request{URL: &url.URL{Scheme: "http", Host: host}})
+		if err != nil || got != want {
+			t.Errorf("%s 的代理 = %v（%v），期望 %v", host, got, err, want)
+		}
+	}
+}
+
+func TestEnvProxy(t *testing.T) {
+	// 作为HTTP代理的测试服务，记录经代理请求的目标主机
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Host)
+		_, _ = io.WriteString(w, "via env proxy")
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	previous := proxyFromEnvironment
+	proxyFromEnvironment = func(*http.Request) (*url.URL, error) { return proxyURL, nil }
+	SetEnvProxy(true)
+	SetNoProxy([]string{"direct.invalid"})
+	defer func() {
+		proxyFromEnvironment = previous
+		SetEnvProxy(false)
+		SetNoProxy(nil)
+	}()
+
+	target, _ := url.Parse("http://env-proxy.invalid/")
+	if !UsesProxy("", target) {
+		t.Error("启用环境变量代理后应判定为经代理访问")
+	}
+	resp, err := SendRequestHttp(context.Background(), http.MethodGet, target.String(), "", OptionsRequest{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "via env proxy" || len(proxied) != 1 || proxied[0] != "env-proxy.invalid" {
+		t.Errorf("响应 = %q，经代理的主机 = %v，期望经环境变量代理访问 env-proxy.invalid", body, proxied)
+	}
+
+	// 绕过列表中的主机不使用环境变量代理
+	direct, _ := url.Parse("http://direct.invalid/")
+	if UsesProxy("", direct) {
+		t.Error("--no-proxy 中的主机不应经环境变量代理")
+	}
+
+	// 关闭后不再读取环境变量
+	SetEnvProxy(false)
+	if UsesProxy("", target) {
+		t.Error("未启用 --env-proxy 时不应使用环境变量代理")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"xfirefly/pkg/utils/proto"
 
 	"github.com/projectdiscovery/rawhttp"
+	"github.com/projectdiscovery/rawhttp/client"
 )
 
 var (
@@ -27,18 +28,53 @@ var (
 
 type RawHttp struct {
 	RawhttpClient *rawhttp.Client
+	Timeout       time.Duration // 本次请求的超时时间，为0时使用客户端创建时的超时
+}
+
+// rawHTTPProxy rawhttp客户端使用的代理地址，支持 http 与 socks5 代理
+var rawHTTPProxy string
+
+// SetRawHTTPProxy 设置rawhttp客户端使用的代理地址，需在首次调用 GetRawHTTP 之前设置
+func SetRawHTTPProxy(proxy string) {
+	rawHTTPProxy = proxy
 }
 
 func GetRawHTTP(timeout int) *rawhttp.Client {
 	if rawHttpClient == nil {
-		rawHttpOptions := rawhttp.DefaultOptions
+		rawHttpOptions := *rawhttp.DefaultOptions
 		rawHttpOptions.Timeout = time.Duration(timeout) * time.Second
-		rawHttpClient = rawhttp.NewClient(rawHttpOptions)
+		rawHttpOptions.Proxy = rawHTTPProxy
+		rawHttpClient = rawhttp.NewClient(&rawHttpOptions)
 	}
 	return rawHttpClient
 }
 
+// rawMode 是否将所有HTTP规则请求改为通过rawhttp按原始字节发送
+var rawMode bool
+
+// SetRawMode 设置原始请求模式，开启后请求头按规则中的书写顺序与大小写原样发送
+func SetRawMode(enabled bool) {
+	rawMode = enabled
+}
+
+// IsRawMode 是否处于原始请求模式
+func IsRawMode() bool {
+	return rawMode
+}
+
 func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string]any) error {
+	return r.rawHttpRequest(request, baseurl, false, true, variableMap)
+}
+
+// OrderedRawHttpRequest 按原始请求中的顺序与大小写逐行发送请求头，未包含 Host 头时在首行补充
+func (r *RawHttp) OrderedRawHttpRequest(request, baseurl string, followRedirects bool, variableMap map[string]any) error {
+	return r.rawHttpRequest(request, baseurl, true, followRedirects, variableMap)
+}
+
+func (r *RawHttp) rawHttpRequest(request, baseurl string, ordered bool, followRedirects bool, variableMap map[string]any) error {
+	if IsOffline() {
+		return ErrOffline
+	}
 	var err error
 	var resp *http.Response
 
@@ -52,7 +88,23 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 		return fmt.Errorf("parse Failed, %s", err.Error())
 	}
 
-	resp, err = r.RawhttpClient.DoRaw(rhttp.Method, baseurl, rhttp.Path, ExpandMapValues(rhttp.Headers), io.NopCloser(strings.NewReader(rhttp.Data)))
+	options := *r.RawhttpClient.Options
+	options.FollowRedirects = followRedirects
+	// 客户端为进程内共用实例，按请求覆盖超时
+	if r.Timeout > 0 {
+		options.Timeout = r.Timeout
+	}
+	// 命中代理绕过列表的目标直连
+	if u, err := url.Parse(baseurl); err == nil && ShouldBypassProxy(u.Host) {
+		options.Proxy = ""
+	}
+	if ordered {
+		options.CustomHeaders = orderedRawHeaders(rhttp.UnsafeHeaders, baseurl)
+		resp, err = r.RawhttpClient.DoRawWithOptions(rhttp.Method, baseurl, rhttp.Path, nil, io.NopCloser(strings.NewReader(rhttp.Data)), &options)
+	} else {
+		resp, err = r.RawhttpClient.DoRawWithOptions(rhttp.Method, baseurl, rhttp.Path, ExpandMapValues(rhttp.Headers), io.NopCloser(strings.NewReader(rhttp.Data)), &options)
+	}
+	logResponse(rhttp.Method, strings.TrimRight(baseurl, "/")+rhttp.Path, resp, err)
 	if err != nil {
 		//fmt.Println(err.Error())
 		return fmt.Errorf("doRaw Failed, %s", err.Error())
@@ -95,7 +147,8 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 	}
 	tempResultResponse.Headers = newheader2
 	tempResultResponse.ContentType = resp.Header.Get("Content-Type")
-	tempResultResponse.Body = respBody
+	tempResultResponse.ContentLength = ContentLength(resp)
+	tempResultResponse.SetBody(respBody)
 	tempResultResponse.Raw = []byte(string(dumpedResponseHeaders) + "\n" + string(respBody))
 	tempResultResponse.RawHeader = dumpedResponseHeaders
 	variableMap["response"] = tempResultResponse
@@ -125,6 +178,16 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 		}
 	}
 	tempResultRequest.Headers = newheader1
+	if cookie := newheader1["cookie"]; cookie != "" {
+		header := http.Header{}
+		header.Set("Cookie", cookie)
+		if cookies := (&http.Request{Header: header}).Cookies(); len(cookies) > 0 {
+			tempResultRequest.Cookies = make(map[string]string, len(cookies))
+			for _, c := range cookies {
+				tempResultRequest.Cookies[c.Name] = c.Value
+			}
+		}
+	}
 	tempResultRequest.Raw = rhttp.UnsafeRawBytes
 	if len(string(rhttp.UnsafeRawBytes)) > 0 {
 		rawSplit := strings.Split(string(rhttp.UnsafeRawBytes), "\n\n")
@@ -145,6 +208,20 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 	return err
 }
 
+// orderedRawHeaders 返回按原始顺序排列的请求头行，缺少 Host 头时根据 baseurl 补充
+func orderedRawHeaders(lines client.Headers, baseurl string) client.Headers {
+	for _, h := range lines {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(h.Key)), "host:") {
+			return lines
+		}
+	}
+	u, err := url.Parse(baseurl)
+	if err != nil || u.Host == "" {
+		return lines
+	}
+	return append(client.Headers{{Key: "Host", Value: u.Host}}, lines...)
+}
+
 func AssignVariableRaw(find string, variableMap map[string]any) string {
 	for k, v := range variableMap {
 		newstr := fmt.Sprintf("%v", v)
@@ -8,16 +8,80 @@
 package network
 
 import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 	"xfirefly/pkg/utils/proto"
 )
 
+// RawParse 将TCP/UDP请求与响应数据转换为规则可用的 request/response 变量
+// response.body、response.rawbody 与 response.raw 均为原始响应，另提供可打印形式 response.printable 与长度 response.length
+// body 与 rawbody 共用一份复制的响应数据，调用方之后修改 res 或 response.raw 不会影响 body
 func RawParse(nc *Client, data []byte, res []byte, variableMap map[string]any) error {
 	variableMap["request"] = &proto.Request{
 		Raw: []byte(nc.address + "\r\n" + string(data)),
 	}
-	variableMap["response"] = &proto.Response{
-		Raw: res,
+	response := &proto.Response{
+		Raw:       res,
+		Printable: Printable(res),
+		Length:    int64(len(res)),
 	}
+	response.SetBody(res)
+	variableMap["response"] = response
 	variableMap["fulltarget"] = nc.address
 	return nil
 }
+
+// IsPrintable 判断字符是否可打印，回车、换行与制表符视为可打印
+func IsPrintable(r rune) bool {
+	return r == '\r' || r == '\n' || r == '\t' || unicode.IsPrint(r)
+}
+
+// Printable 将字节流转换为可打印字符串，保留ASCII可打印字符与换行、制表符，其余字节替换为 "."
+func Printable(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if c < utf8.RuneSelf && IsPrintable(rune(c)) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// CertVariables 将对端证书转换为规则可用的 cert 变量，无证书时返回空映射
+// 包含字段：subject、subject_cn、issuer、issuer_cn、sans（逗号分隔）、serial、fingerprint_sha256、not_before、not_after
+func CertVariables(certs []*x509.Certificate) map[string]string {
+	vars := make(map[string]string, 9)
+	if len(certs) == 0 {
+		return vars
+	}
+	leaf := certs[0]
+	vars["subject"] = leaf.Subject.String()
+	vars["subject_cn"] = leaf.Subject.CommonName
+	vars["issuer"] = leaf.Issuer.String()
+	vars["issuer_cn"] = leaf.Issuer.CommonName
+
+	// 合并DNS与IP类型的SAN
+	sans := make([]string, 0, len(leaf.DNSNames)+len(leaf.IPAddresses))
+	sans = append(sans, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	vars["sans"] = strings.Join(sans, ",")
+
+	if leaf.SerialNumber != nil {
+		vars["serial"] = strings.ToUpper(leaf.SerialNumber.Text(16))
+	}
+	sum := sha256.Sum256(leaf.Raw)
+	vars["fingerprint_sha256"] = hex.EncodeToString(sum[:])
+	vars["not_before"] = leaf.NotBefore.UTC().Format(time.RFC3339)
+	vars["not_after"] = leaf.NotAfter.UTC().Format(time.RFC3339)
+	return vars
+}
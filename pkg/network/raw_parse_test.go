@@ -0,0 +1,55 @@
+package network
+
+import (
+	"testing"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestRawParseBodyIsolated(t *testing.T) {
+	res := []byte("SSH-2.0-OpenSSH_8.9\r\n")
+	variableMap := make(map[string]any)
+	if err := RawParse(&Client{address: "127.0.0.1:22"}, []byte("\r\n"), res, variableMap); err != nil {
+		t.Fatalf("RawParse 失败: %v", err)
+	}
+	response, ok := variableMap["response"].(*proto.Response)
+	if !ok {
+		t.Fatalf("response 类型为 %T，期望 *proto.Response", variableMap["response"])
+	}
+	want := string(res)
+
+	// 原地修改 raw 与调用方的缓冲区，body 与 rawbody 保持不变
+	response.Raw[0] = 'X'
+	res[1] = 'Y'
+	if string(response.Body) != want {
+		t.Errorf("修改 raw 后 body = %q，期望 %q", response.Body, want)
+	}
+	if string(response.Rawbody) != want {
+		t.Errorf("修改 raw 后 rawbody = %q，期望 %q", response.Rawbody, want)
+	}
+	if response.Length != int64(len(want)) {
+		t.Errorf("length = %d，期望 %d", response.Length, len(want))
+	}
+}
+
+func TestRawParsePrintable(t *testing.T) {
+	res := []byte("\x00\x01redis_version:7.2\r\n\tmode:standalone\xff")
+	variableMap := make(map[string]any)
+	if err := RawParse(&Client{address: "127.0.0.1:6379"}, []byte("INFO\r\n"), res, variableMap); err != nil {
+		t.Fatalf("RawParse 失败: %v", err)
+	}
+	response := variableMap["response"].(*proto.Response)
+
+	// 不可打印字节替换为 "."，保留换行与制表符
+	if want := "..redis_version:7.2\r\n\tmode:standalone."; response.Printable != want {
+		t.Errorf("printable = %q，期望 %q", response.Printable, want)
+	}
+	if response.Length != int64(len(res)) {
+		t.Errorf("length = %d，期望 %d", response.Length, len(res))
+	}
+	if string(response.Body) != string(res) {
+		t.Errorf("body = %q，期望原始响应 %q", response.Body, res)
+	}
+	if got := variableMap["fulltarget"]; got != "127.0.0.1:6379" {
+		t.Errorf("fulltarget = %v，期望 127.0.0.1:6379", got)
+	}
+}
@@ -0,0 +1,96 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 请求审计日志
+var (
+	requestLogFile   *os.File      // 审计日志文件
+	requestLogWriter *bufio.Writer // 带缓冲的写入器
+	requestLogMutex  sync.Mutex    // 保护审计日志写入
+)
+
+// InitRequestLog 初始化请求审计日志，以追加方式写入指定文件
+func InitRequestLog(path string) error {
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开请求日志文件失败: %v", err)
+	}
+
+	requestLogMutex.Lock()
+	defer requestLogMutex.Unlock()
+	if requestLogFile != nil {
+		_ = requestLogWriter.Flush()
+		_ = requestLogFile.Close()
+	}
+	requestLogFile = file
+	requestLogWriter = bufio.NewWriter(file)
+	return nil
+}
+
+// LogRequest 记录一次HTTP请求，格式为: 时间戳 方法 URL 状态码，请求失败时状态码记为 - 并附带错误信息
+func LogRequest(method string, url string, statusCode int, err error) {
+	requestLogMutex.Lock()
+	defer requestLogMutex.Unlock()
+	if requestLogWriter == nil {
+		return
+	}
+
+	status := strconv.Itoa(statusCode)
+	if err != nil {
+		status = "- " + err.Error()
+	}
+	_, _ = fmt.Fprintf(requestLogWriter, "%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), method, url, status)
+}
+
+// FlushRequestLog 将缓冲中的审计日志写入文件
+func FlushRequestLog() error {
+	requestLogMutex.Lock()
+	defer requestLogMutex.Unlock()
+	if requestLogWriter == nil {
+		return nil
+	}
+	return requestLogWriter.Flush()
+}
+
+// CloseRequestLog 刷新并关闭请求审计日志
+func CloseRequestLog() error {
+	requestLogMutex.Lock()
+	defer requestLogMutex.Unlock()
+	if requestLogFile == nil {
+		return nil
+	}
+	flushErr := requestLogWriter.Flush()
+	closeErr := requestLogFile.Close()
+	requestLogFile = nil
+	requestLogWriter = nil
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// logConnRequest 记录一次TCP/UDP连接，方法记为协议名，地址记为 协议://host:port，状态码记为0
+func logConnRequest(network string, address string, err error) {
+	LogRequest(strings.ToUpper(network), network+"://"+address, 0, err)
+}
+
+// logResponse 根据响应结果记录审计日志
+func logResponse(method string, url string, resp *http.Response, err error) {
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	LogRequest(method, url, statusCode, err)
+}
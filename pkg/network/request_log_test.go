@@ -0,0 +1,74 @@
+package network
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.log")
+	if err := InitRequestLog(path); err != nil {
+		t.Fatalf("初始化请求日志失败: %v", err)
+	}
+	defer func() { _ = CloseRequestLog() }()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+	resp, err := SendRequestHttp(context.Background(), http.MethodGet, srv.URL+"/probe", "", OptionsRequest{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// TCP连接记录协议与地址
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	client, err := NewClient(ln.Addr().String(), TcpOrUdpConfig{Network: "tcp", DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("建立TCP连接失败: %v", err)
+	}
+	_ = client.Close()
+
+	LogRequest(http.MethodGet, "http://unreachable.invalid/", 0, context.DeadlineExceeded)
+	if err := CloseRequestLog(); err != nil {
+		t.Fatalf("关闭请求日志失败: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := [][]string{
+		{"GET", srv.URL + "/probe", "418"},
+		{"TCP", "tcp://" + ln.Addr().String(), "0"},
+		{"GET", "http://unreachable.invalid/", "- " + context.DeadlineExceeded.Error()},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("日志行数 = %d，期望 %d:\n%s", len(lines), len(want), data)
+	}
+	for i, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			t.Fatalf("第 %d 行字段数 = %d，期望 4: %q", i, len(fields), line)
+		}
+		if _, err := time.Parse(time.RFC3339, fields[0]); err != nil {
+			t.Errorf("第 %d 行时间戳无效: %v", i, err)
+		}
+		if got := fields[1:]; strings.Join(got, " ") != strings.Join(want[i], " ") {
+			t.Errorf("第 %d 行 = %v，期望 %v", i, got, want[i])
+		}
+	}
+}
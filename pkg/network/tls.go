@@ -0,0 +1,195 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// tlsVersions TLS版本名称与版本号映射
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// legacyCipherSuites 默认使用的加密套件，包含部分老旧套件以兼容遗留设备
+var legacyCipherSuites = []uint16{
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// ParseTLSVersion 解析TLS版本名称，支持 1.0/1.1/1.2/1.3，也可带 tls 前缀，如 tls1.2
+func ParseTLSVersion(version string) (uint16, error) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "tls")
+	if name == "" {
+		return tls.VersionTLS10, nil
+	}
+	if v, ok := tlsVersions[name]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("不支持的TLS版本: %s，可选值为 1.0/1.1/1.2/1.3", version)
+}
+
+// 证书校验配置，目标站点与HTTPS代理相互独立
+var (
+	verifyTargetTLS bool // 是否校验目标站点证书，默认不校验
+	verifyProxyTLS  bool // 是否校验HTTPS代理证书，默认不校验
+)
+
+// SetTLSVerify 设置目标站点与HTTPS代理的证书校验方式，需在 SetTLSOptions 之前调用
+func SetTLSVerify(verifyTarget, verifyProxy bool) {
+	verifyTargetTLS = verifyTarget
+	verifyProxyTLS = verifyProxy
+	transportCache.Range(func(key, _ any) bool {
+		transportCache.Delete(key)
+		return true
+	})
+}
+
+// newTLSConfig 创建客户端TLS配置，defaultCiphers 为 true 时使用Go默认加密套件
+func newTLSConfig(minVersion uint16, defaultCiphers bool) *tls.Config {
+	conf := &tls.Config{
+		InsecureSkipVerify: !verifyTargetTLS,
+		MinVersion:         minVersion,
+	}
+	if !defaultCiphers {
+		conf.CipherSuites = legacyCipherSuites
+	}
+	return conf
+}
+
+// SetTLSOptions 设置HTTP请求使用的最低TLS版本与加密套件，并重建全局客户端与已缓存的transport
+func SetTLSOptions(minVersion string, defaultCiphers bool) error {
+	version, err := ParseTLSVersion(minVersion)
+	if err != nil {
+		return err
+	}
+	tlsConfig = newTLSConfig(version, defaultCiphers)
+
+	transport := &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+	}
+	RetryClient.HTTPClient.Transport = transport
+	RetryClient.HTTPClient2.Transport = transport
+
+	// 已缓存的transport仍持有旧配置，需要重新创建
+	transportCache.Range(func(key, _ any) bool {
+		transportCache.Delete(key)
+		return true
+	})
+	return nil
+}
+
+// clientTLSConfig 创建TCP客户端使用的TLS配置，指定 TLSVersion 时仅允许该版本并启用全部加密套件
+func clientTLSConfig(conf TcpOrUdpConfig) *tls.Config {
+	tc := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tlsConfig.MinVersion,
+		ServerName:         conf.ServerName, // 动态配置 ServerName
+	}
+	if conf.TLSVersion != 0 {
+		tc.MinVersion = conf.TLSVersion
+		tc.MaxVersion = conf.TLSVersion
+		tc.CipherSuites = allCipherSuites()
+	}
+	return tc
+}
+
+// allCipherSuites 返回Go支持的全部加密套件（含不安全套件），用于尽可能完成旧版本协议握手
+func allCipherSuites() []uint16 {
+	suites := make([]uint16, 0, 32)
+	for _, s := range tls.CipherSuites() {
+		suites = append(suites, s.ID)
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		suites = append(suites, s.ID)
+	}
+	return suites
+}
+
+// ProbeTLSVersions 依次使用 TLS1.0/1.1/1.2/1.3 与目标握手，返回目标接受的协议版本名称
+// SSLv3 已不被Go标准库支持，无法探测
+func ProbeTLSVersions(address string, serverName string, proxyURL string, timeout time.Duration) []string {
+	accepted := make([]string, 0, len(tlsVersions))
+	for _, name := range []string{"1.0", "1.1", "1.2", "1.3"} {
+		nc, err := NewClient(address, TcpOrUdpConfig{
+			Network:     "tcp",
+			MaxRetries:  1,
+			DialTimeout: timeout,
+			ProxyURL:    proxyURL,
+			IsLts:       true,
+			ServerName:  serverName,
+			TLSVersion:  tlsVersions[name],
+		})
+		if err != nil {
+			continue
+		}
+		_ = nc.Close()
+		accepted = append(accepted, "TLS"+name)
+	}
+	return accepted
+}
+
+// proxyTLSConfig 创建连接HTTPS代理使用的TLS配置
+func proxyTLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: !verifyProxyTLS,
+		ServerName:         serverName,
+		MinVersion:         tls.VersionTLS12,
+	}
+}
+
+// plainProxyURL 将HTTPS代理地址改写为http协议交给Transport按普通代理处理，TLS握手由 proxyTLSDialContext 完成
+// 未指定端口时补全默认的443端口，使Transport拨号的地址与HTTPS代理地址一致
+func plainProxyURL(proxyURL *url.URL) *url.URL {
+	plain := *proxyURL
+	plain.Scheme = "http"
+	if plain.Port() == "" {
+		plain.Host = net.JoinHostPort(plain.Hostname(), "443")
+	}
+	return &plain
+}
+
+// proxyTLSDialContext 返回拨号函数，连接HTTPS代理地址时使用代理专用TLS配置完成握手，其他地址直接拨号
+func proxyTLSDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error), proxyURL *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	port := proxyURL.Port()
+	if port == "" {
+		port = "443"
+	}
+	proxyAddr := net.JoinHostPort(proxyURL.Hostname(), port)
+	conf := proxyTLSConfig(proxyURL.Hostname())
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil || addr != proxyAddr {
+			return conn, err
+		}
+		tlsConn := tls.Client(conn, conf)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("代理TLS握手失败: %w", err)
+		}
+		return tlsConn, nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 	"xfirefly/pkg/finger"
 	"xfirefly/pkg/utils/proto"
 
@@ -13,6 +14,83 @@ import (
 	"github.com/schollz/progressbar/v3"
 )
 
+// lineTemplate 自定义控制台输出模板，为空时使用默认格式
+var lineTemplate *template.Template
+
+// templateFuncs 输出模板可用的辅助函数
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	// fingers 返回匹配到的指纹名称，以逗号分隔
+	"fingers": func(targetResult *TargetResult) string {
+		names := make([]string, 0, len(targetResult.Matches))
+		for _, match := range targetResult.Matches {
+			names = append(names, match.Finger.Info.Name)
+		}
+		return strings.Join(names, ",")
+	},
+}
+
+// ParseOutputTemplate 解析自定义输出模板，模板数据为 *TargetResult
+func ParseOutputTemplate(text string) (*template.Template, error) {
+	return template.New("output").Funcs(templateFuncs).Parse(text)
+}
+
+// SetOutputTemplate 设置控制台输出模板，传入空字符串时恢复默认格式
+func SetOutputTemplate(text string) error {
+	if text == "" {
+		lineTemplate = nil
+		return nil
+	}
+	tmpl, err := ParseOutputTemplate(text)
+	if err != nil {
+		return err
+	}
+	lineTemplate = tmpl
+	return nil
+}
+
+// SetRunID 设置输出记录附带的运行ID，传入空字符串时JSON与文本记录不输出，CSV运行ID列为空
+func SetRunID(id string) {
+	runID = id
+}
+
+// SetIncludeRaw 设置JSON输出中是否附带匹配指纹的原始请求与响应
+func SetIncludeRaw(request, response bool) {
+	includeRequest = request
+	includeResponse = response
+}
+
+// collectRawData 按指纹ID收集匹配指纹的原始请求或响应，未启用或没有数据时返回nil
+func collectRawData(matches []*FingerMatch, enabled bool, raw func(*FingerMatch) []byte) map[string]string {
+	if !enabled {
+		return nil
+	}
+	var data map[string]string
+	for _, match := range matches {
+		if match.Finger == nil {
+			continue
+		}
+		content := raw(match)
+		if len(content) == 0 {
+			continue
+		}
+		if data == nil {
+			data = make(map[string]string)
+		}
+		data[match.Finger.Id] = string(content)
+	}
+	return data
+}
+
+// renderTemplate 使用自定义模板渲染单条结果
+func renderTemplate(targetResult *TargetResult) (string, error) {
+	var builder strings.Builder
+	if err := lineTemplate.Execute(&builder, targetResult); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
 // CreateProgressBar 创建进度条
 func CreateProgressBar(total int) *progressbar.ProgressBar {
 	return progressbar.NewOptions64(
@@ -46,7 +124,7 @@ func GetOutputFormat(jsonOutput bool, outputPath string) string {
 		return "txt" // 默认为txt格式
 	}
 
-	ext := strings.ToLower(filepath.Ext(outputPath))
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(outputPath), ".gz")))
 	if ext == ".csv" {
 		return "csv"
 	}
@@ -91,6 +169,9 @@ func HandleMatchResults(targetResult *TargetResult, output string, sockOutput st
 	// 构建输出信息
 	baseInfoStr := fmt.Sprintf("URL：%s %s  标题：%s  Server：%s",
 		targetResult.URL, statusCodeStr, targetResult.Title, serverInfo)
+	if targetResult.RequiresAuth {
+		baseInfoStr += "  [需登录]"
+	}
 
 	// 构建技术栈信息（合并为一行）
 	var techInfoStr string
@@ -148,6 +229,15 @@ func HandleMatchResults(targetResult *TargetResult, output string, sockOutput st
 		outputMsg = fmt.Sprintf("%s %s", baseInfoStr, matchResultStr)
 	}
 
+	// 使用自定义模板时替换默认输出，渲染失败则回退默认格式
+	if lineTemplate != nil {
+		if rendered, err := renderTemplate(targetResult); err != nil {
+			logger.Debugf("输出模板渲染失败: %v", err)
+		} else {
+			outputMsg = rendered
+		}
+	}
+
 	// 输出结果
 	printResult(outputMsg)
 
@@ -167,8 +257,22 @@ func CreateWriteOptions(targetResult *TargetResult, outputPath string, format st
 	// 收集指纹信息
 	var IsMatch bool
 	fingerList := make([]*finger.Finger, 0, len(targetResult.Matches))
+	var extracted map[string]map[string]string
+	var matchedRules map[string][]string
 	for _, match := range targetResult.Matches {
 		fingerList = append(fingerList, match.Finger)
+		if len(match.Extracted) > 0 {
+			if extracted == nil {
+				extracted = make(map[string]map[string]string)
+			}
+			extracted[match.Finger.Id] = match.Extracted
+		}
+		if len(match.MatchedRules) > 0 {
+			if matchedRules == nil {
+				matchedRules = make(map[string][]string)
+			}
+			matchedRules[match.Finger.Id] = match.MatchedRules
+		}
 	}
 	if len(targetResult.Matches) > 0 {
 		IsMatch = true
@@ -176,15 +280,25 @@ func CreateWriteOptions(targetResult *TargetResult, outputPath string, format st
 
 	// 创建写入选项结构体
 	writeOpts := &WriteOptions{
-		Output:      outputPath,
-		Format:      format,
-		Target:      targetResult.URL,
-		Fingers:     fingerList,
-		StatusCode:  targetResult.StatusCode,
-		Title:       targetResult.Title,
-		ServerInfo:  targetResult.ServerInfo,
-		Wappalyzer:  targetResult.Wappalyzer,
-		FinalResult: IsMatch,
+		Output:       outputPath,
+		Format:       format,
+		Target:       targetResult.URL,
+		Fingers:      fingerList,
+		StatusCode:   targetResult.StatusCode,
+		Title:        targetResult.Title,
+		ServerInfo:   targetResult.ServerInfo,
+		Wappalyzer:   targetResult.Wappalyzer,
+		FinalResult:  IsMatch,
+		Extracted:    extracted,
+		MatchedRules: matchedRules,
+		Security:     targetResult.Security,
+		TLS:          targetResult.TLS,
+		RequiresAuth: targetResult.RequiresAuth,
+		IconHashes:   targetResult.IconHashes,
+		Duration:     targetResult.Duration,
+		RunID:        runID,
+		Requests:     collectRawData(targetResult.Matches, includeRequest, func(m *FingerMatch) []byte { return m.Request.GetRaw() }),
+		Responses:    collectRawData(targetResult.Matches, includeResponse, func(m *FingerMatch) []byte { return m.Response.GetRaw() }),
 	}
 
 	// 检查并设置响应头信息
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestHandleMatchResultsTemplate(t *testing.T) {
+	defer func() { _ = SetOutputTemplate("") }()
+
+	targetResult := &TargetResult{
+		URL:        "http://example.com",
+		StatusCode: 200,
+		Title:      "Example",
+		Matches: []*FingerMatch{
+			{Finger: &finger.Finger{Id: "nginx", Info: finger.Info{Name: "Nginx"}}, Result: true},
+			{Finger: &finger.Finger{Id: "php", Info: finger.Info{Name: "PHP"}}, Result: true},
+		},
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"自定义模板", "{{.URL}} {{.StatusCode}} [{{fingers .}}]", "http://example.com 200 [Nginx,PHP]"},
+		{"使用join", `{{join .IconHashes "|"}}{{.Title}}`, "Example"},
+		{"渲染失败回退默认格式", "{{.Missing}}", "URL：http://example.com"},
+		{"空模板使用默认格式", "", "URL：http://example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetOutputTemplate(tt.template); err != nil {
+				t.Fatalf("解析模板失败: %v", err)
+			}
+			var got string
+			HandleMatchResults(targetResult, "", "", func(msg string) { got = msg }, "", nil)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("输出 = %q，期望以 %q 开头", got, tt.want)
+			}
+		})
+	}
+
+	if err := SetOutputTemplate("{{.URL"); err == nil {
+		t.Error("语法错误的模板应返回错误")
+	}
+}
+
+func TestCreateWriteOptionsMatchedRules(t *testing.T) {
+	targetResult := &TargetResult{
+		URL: "http://example.com",
+		Matches: []*FingerMatch{
+			{Finger: &finger.Finger{Id: "nginx"}, Result: true, MatchedRules: []string{`r0: response.headers["server"].contains("nginx")`}},
+			{Finger: &finger.Finger{Id: "php"}, Result: true},
+		},
+	}
+	data, err := json.Marshal(NewJSONOutput(CreateWriteOptions(targetResult, "", "json", nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		MatchedRules map[string][]string `json:"matched_rules"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	// 按指纹ID分组，没有命中规则记录的指纹不输出
+	if len(out.MatchedRules) != 1 || len(out.MatchedRules["nginx"]) != 1 {
+		t.Errorf("matched_rules = %v，期望仅包含 nginx 的一条规则", out.MatchedRules)
+	}
+}
+
+func TestCreateWriteOptionsIncludeRaw(t *testing.T) {
+	defer SetIncludeRaw(false, false)
+	targetResult := &TargetResult{
+		URL: "http://example.com",
+		Matches: []*FingerMatch{
+			{
+				Finger:   &finger.Finger{Id: "nginx"},
+				Result:   true,
+				Request:  &proto.Request{Raw: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")},
+				Response: &proto.Response{Raw: []byte("HTTP/1.1 200 OK\r\nServer: nginx\r\n\r\n")},
+			},
+			// 已释放请求响应数据的指纹不输出
+			{Finger: &finger.Finger{Id: "php"}, Result: true},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		request       bool
+		response      bool
+		wantRequests  int
+		wantResponses int
+	}{
+		{"默认不附带", false, false, 0, 0},
+		{"仅附带请求", true, false, 1, 0},
+		{"同时附带请求与响应", true, true, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetIncludeRaw(tt.request, tt.response)
+			data, err := json.Marshal(NewJSONOutput(CreateWriteOptions(targetResult, "", "json", nil)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out struct {
+				Requests  map[string]string `json:"requests"`
+				Responses map[string]string `json:"responses"`
+			}
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Requests) != tt.wantRequests || len(out.Responses) != tt.wantResponses {
+				t.Fatalf("requests = %v，responses = %v，期望 %d 与 %d 条", out.Requests, out.Responses, tt.wantRequests, tt.wantResponses)
+			}
+			if tt.request && !strings.HasPrefix(out.Requests["nginx"], "GET / HTTP/1.1") {
+				t.Errorf("requests[nginx] = %q", out.Requests["nginx"])
+			}
+			if tt.response && !strings.Contains(out.Responses["nginx"], "Server: nginx") {
+				t.Errorf("responses[nginx] = %q", out.Responses["nginx"])
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"sync"
+)
+
+// dedupMaxEntries 去重记录的最大条数，超出后淘汰最早记录的哈希，避免长时间运行时内存无限增长
+const dedupMaxEntries = 100000
+
+// 输出去重配置，仅在单次运行内生效
+var (
+	dedupEnabled bool                        // 是否丢弃完全相同的输出记录
+	dedupSeen    = make(map[string]struct{}) // 已输出记录的哈希，按输出目标区分
+	dedupOrder   = make([]string, 0, 1024)   // 按写入顺序保存的哈希，用于淘汰最早的记录
+	dedupNext    int                         // 达到上限后下一个被淘汰的位置
+	dedupMutex   sync.Mutex                  // 保护 dedupSeen、dedupOrder 与 dedupNext
+)
+
+// SetOutputDedup 设置是否对输出记录去重，开启时清空已记录的哈希
+func SetOutputDedup(enabled bool) {
+	dedupMutex.Lock()
+	defer dedupMutex.Unlock()
+	dedupEnabled = enabled
+	dedupSeen = make(map[string]struct{})
+	dedupOrder = make([]string, 0, 1024)
+	dedupNext = 0
+}
+
+// isDuplicateOutput 判断记录是否已写入过指定输出目标，未写入时记录其哈希
+// 扫描耗时与运行ID每次都不同，不参与比较
+func isDuplicateOutput(sink string, record *JSONOutput) bool {
+	if !dedupEnabled || record == nil {
+		return false
+	}
+	content := *record
+	content.Duration = 0
+	content.RunID = ""
+	data, err := json.Marshal(&content)
+	if err != nil {
+		return false
+	}
+	sum := md5.Sum(data)
+	key := sink + ":" + string(sum[:])
+
+	dedupMutex.Lock()
+	defer dedupMutex.Unlock()
+	if _, ok := dedupSeen[key]; ok {
+		return true
+	}
+	dedupSeen[key] = struct{}{}
+	if len(dedupOrder) < dedupMaxEntries {
+		dedupOrder = append(dedupOrder, key)
+	} else {
+		delete(dedupSeen, dedupOrder[dedupNext])
+		dedupOrder[dedupNext] = key
+		dedupNext = (dedupNext + 1) % dedupMaxEntries
+	}
+	return false
+}
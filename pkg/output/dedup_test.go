@@ -0,0 +1,89 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsDuplicateOutputIgnoresTimingAndRunID(t *testing.T) {
+	SetOutputDedup(true)
+	defer SetOutputDedup(false)
+
+	first := &JSONOutput{URL: "http://example.com", StatusCode: 200, Duration: 120, RunID: "run-a"}
+	second := &JSONOutput{URL: "http://example.com", StatusCode: 200, Duration: 350, RunID: "run-b"}
+	if isDuplicateOutput("file", first) {
+		t.Fatal("首条记录不应被判定为重复")
+	}
+	if !isDuplicateOutput("file", second) {
+		t.Error("仅耗时与运行ID不同的记录应被判定为重复")
+	}
+	if isDuplicateOutput("sock", second) {
+		t.Error("不同输出目标应分别去重")
+	}
+	if isDuplicateOutput("file", &JSONOutput{URL: "http://example.com", StatusCode: 302}) {
+		t.Error("内容不同的记录不应被判定为重复")
+	}
+}
+
+func TestIsDuplicateOutputBounded(t *testing.T) {
+	SetOutputDedup(true)
+	defer SetOutputDedup(false)
+
+	for i := 0; i < dedupMaxEntries+10; i++ {
+		isDuplicateOutput("file", &JSONOutput{URL: fmt.Sprintf("http://example.com/%d", i)})
+	}
+	if len(dedupSeen) != dedupMaxEntries || len(dedupOrder) != dedupMaxEntries {
+		t.Fatalf("去重记录数 = %d/%d，期望不超过 %d", len(dedupSeen), len(dedupOrder), dedupMaxEntries)
+	}
+	// 最早的记录已被淘汰，最新的记录仍然保留
+	if isDuplicateOutput("file", &JSONOutput{URL: "http://example.com/0"}) {
+		t.Error("最早的记录应已被淘汰")
+	}
+	if !isDuplicateOutput("file", &JSONOutput{URL: fmt.Sprintf("http://example.com/%d", dedupMaxEntries+9)}) {
+		t.Error("最新的记录应仍被判定为重复")
+	}
+}
+
+func TestWriteFingerprintsDedup(t *testing.T) {
+	tests := []struct {
+		name        string
+		dedup       bool
+		wantRecords int
+	}{
+		{"开启去重", true, 2},
+		{"未开启去重", false, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetOutputDedup(tt.dedup)
+			defer SetOutputDedup(false)
+
+			path := filepath.Join(t.TempDir(), "result.json")
+			write := func(statusCode int32, duration time.Duration) {
+				opts := &WriteOptions{Output: path, Format: "json", Target: "http://example.com", StatusCode: statusCode, Duration: duration}
+				if err := WriteFingerprints(opts); err != nil {
+					t.Fatalf("写入结果失败: %v", err)
+				}
+			}
+			// 第二条仅耗时不同，第三条状态码不同
+			write(200, time.Second)
+			write(200, 2*time.Second)
+			write(302, time.Second)
+			if err := CloseFileOutput(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := strings.Count(string(data), `"url":`); records != tt.wantRecords {
+				t.Errorf("输出记录数 = %d，期望 %d:\n%s", records, tt.wantRecords, data)
+			}
+		})
+	}
+}
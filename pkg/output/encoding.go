@@ -0,0 +1,36 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// outputEncoding txt/csv输出文件使用的字符编码，json输出始终为UTF-8
+var outputEncoding = "utf8"
+
+// SetOutputEncoding 设置txt/csv输出文件的字符编码，支持 utf8 与 gbk
+func SetOutputEncoding(enc string) error {
+	enc = strings.ToLower(strings.TrimSpace(enc))
+	switch enc {
+	case "", "utf8", "utf-8":
+		outputEncoding = "utf8"
+	case "gbk":
+		outputEncoding = "gbk"
+	default:
+		return fmt.Errorf("不支持的输出编码: %s，仅支持 utf8、gbk", enc)
+	}
+	return nil
+}
+
+// encodedWriter 按配置的输出编码包装文本写入器，GBK无法表示的字符替换为占位符
+func encodedWriter(w io.Writer) io.Writer {
+	if outputEncoding != "gbk" {
+		return w
+	}
+	return transform.NewWriter(w, encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder()))
+}
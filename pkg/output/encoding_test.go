@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestSetOutputEncoding(t *testing.T) {
+	defer func() { _ = SetOutputEncoding("utf8") }()
+	for _, enc := range []string{"", "UTF-8", " gbk "} {
+		if err := SetOutputEncoding(enc); err != nil {
+			t.Errorf("SetOutputEncoding(%q) 返回错误: %v", enc, err)
+		}
+	}
+	if err := SetOutputEncoding("big5"); err == nil {
+		t.Error("不支持的编码应返回错误")
+	}
+}
+
+func TestWriteFingerprintsGBK(t *testing.T) {
+	if err := SetOutputEncoding("gbk"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetOutputEncoding("utf8") }()
+
+	for _, format := range []string{"csv", "txt"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "result."+format)
+			opts := &WriteOptions{Output: path, Format: format, Target: "http://example.com", StatusCode: 200, Title: "管理后台"}
+			if err := WriteFingerprints(opts); err != nil {
+				t.Fatalf("写入结果失败: %v", err)
+			}
+			if err := CloseFileOutput(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+				t.Error("GBK编码的文件不应写入UTF-8 BOM")
+			}
+			if utf8.Valid(data) {
+				t.Error("输出文件仍为UTF-8编码")
+			}
+			decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+			if err != nil {
+				t.Fatalf("按GBK解码失败: %v", err)
+			}
+			if !strings.Contains(string(decoded), "管理后台") {
+				t.Errorf("按GBK解码后未找到标题:\n%s", decoded)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package output
 
 import (
+	"compress/gzip"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"xfirefly/pkg/utils/proto"
 
 	"github.com/donnie4w/go-logger/logger"
@@ -28,15 +31,16 @@ func WriteHeader(format string) error {
 
 	if format == "csv" {
 		if csvWriter == nil {
-			csvWriter = csv.NewWriter(outputFile)
+			csvWriter = csv.NewWriter(encodedWriter(outputWriter))
 		}
 
-		// 写入扩展的CSV表头
-		if err := csvWriter.Write([]string{
+		// 写入扩展的CSV表头，运行ID列始终存在，未启用--output-append-id时为空，追加写入的多次扫描列数保持一致
+		header := []string{
 			"URL", "状态码", "标题", "服务器信息",
 			"Web服务器", "JS框架", "JS库", "Web框架", "编程语言",
-			"指纹ID", "指纹名称", "响应头", "匹配结果", "备注",
-		}); err != nil {
+			"指纹ID", "指纹名称", "响应头", "匹配结果", "备注", "运行ID",
+		}
+		if err := csvWriter.Write(header); err != nil {
 			return fmt.Errorf("写入CSV表头失败: %v", err)
 		}
 		csvWriter.Flush()
@@ -50,11 +54,12 @@ func WriteHeader(format string) error {
 			"指纹ID", "指纹名称", "响应头", "匹配结果", "备注")
 
 		// 写入表头和分隔线
-		if _, err := outputFile.WriteString(header); err != nil {
+		writer := encodedWriter(outputWriter)
+		if _, err := io.WriteString(writer, header); err != nil {
 			return fmt.Errorf("写入表头失败: %v", err)
 		}
 
-		if _, err := outputFile.WriteString(strings.Repeat("-", 300) + "\n"); err != nil {
+		if _, err := io.WriteString(writer, strings.Repeat("-", 300)+"\n"); err != nil {
 			return fmt.Errorf("写入分隔线失败: %v", err)
 		}
 	}
@@ -65,6 +70,8 @@ func WriteHeader(format string) error {
 
 // openOutputFile 打开或创建输出文件的通用函数
 func openOutputFile(output, format string) error {
+	output = OutputPath(output)
+
 	// 如果文件已经正确打开，直接返回
 	if outputFile != nil && outputFile.Name() == output {
 		return nil
@@ -75,8 +82,10 @@ func openOutputFile(output, format string) error {
 		if csvWriter != nil {
 			csvWriter.Flush()
 		}
+		closeGzipWriter()
 		_ = outputFile.Close()
 		outputFile = nil
+		outputWriter = nil
 		csvWriter = nil
 	}
 
@@ -98,18 +107,13 @@ func openOutputFile(output, format string) error {
 	var file *os.File
 	var err error
 
-	if format == "csv" && !fileExists {
-		// 对于新的CSV文件，先创建文件并写入UTF-8 BOM
+	writeBOM := format == "csv" && !fileExists && outputEncoding == "utf8"
+	if writeBOM {
+		// 对于新的UTF-8编码CSV文件，先创建文件再写入UTF-8 BOM
 		file, err = os.Create(output)
 		if err != nil {
 			return fmt.Errorf("创建输出文件失败: %v", err)
 		}
-
-		// 写入UTF-8 BOM标识 (EF BB BF)
-		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
-			file.Close()
-			return fmt.Errorf("写入UTF-8 BOM失败: %v", err)
-		}
 	} else {
 		// 非CSV文件或已存在的CSV文件，使用追加模式打开
 		file, err = os.OpenFile(output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -118,12 +122,30 @@ func openOutputFile(output, format string) error {
 		}
 	}
 
+	// 启用压缩时包装gzip写入器，追加写入时产生新的gzip成员，解压时会按顺序拼接
+	var writer io.Writer = file
+	if outputGzip {
+		gzipWriter = gzip.NewWriter(file)
+		writer = gzipWriter
+	}
+
+	// 写入UTF-8 BOM标识 (EF BB BF)，压缩时写入解压后的内容中
+	if writeBOM {
+		if _, err := writer.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			closeGzipWriter()
+			file.Close()
+			return fmt.Errorf("写入UTF-8 BOM失败: %v", err)
+		}
+	}
+
 	outputFile = file
+	outputWriter = writer
 	headerWritten = fileExists
+	startPeriodicFlush()
 
 	// 初始化CSV写入器
 	if format == "csv" {
-		csvWriter = csv.NewWriter(file)
+		csvWriter = csv.NewWriter(encodedWriter(writer))
 	}
 
 	// 如果是新文件，写入表头
@@ -151,6 +173,12 @@ func WriteFingerprints(opts *WriteOptions) error {
 		return err
 	}
 
+	// 丢弃与已写入记录完全相同的结果
+	if isDuplicateOutput("file", NewJSONOutput(opts)) {
+		logger.Debugf("结果 %s 与已写入记录重复，已跳过", opts.Target)
+		return nil
+	}
+
 	// 收集指纹信息并格式化
 	fingersCount := len(opts.Fingers)
 	fingerIDs := make([]string, 0, fingersCount)
@@ -183,6 +211,7 @@ func WriteFingerprints(opts *WriteOptions) error {
 	} else if opts.RespHeaders != "" {
 		headersStr = opts.RespHeaders
 	}
+	headersStr = redactRaw(headersStr)
 
 	// 提取Wappalyzer信息
 	webServers := "-"
@@ -225,18 +254,7 @@ func WriteFingerprints(opts *WriteOptions) error {
 	// 根据不同格式写入结果
 	if opts.Format == "json" {
 		// 构建JSON对象
-		jsonOutput := &JSONOutput{
-			URL:         opts.Target,
-			StatusCode:  opts.StatusCode,
-			Title:       opts.Title,
-			Server:      serverInfoStr,
-			FingerIDs:   fingerIDs,
-			FingerNames: fingerNames,
-			Headers:     headersStr,
-			Wappalyzer:  opts.Wappalyzer,
-			MatchResult: opts.FinalResult,
-			Remark:      remark,
-		}
+		jsonOutput := NewJSONOutput(opts)
 
 		// 序列化为JSON
 		jsonData, err := json.MarshalIndent(jsonOutput, "", "")
@@ -245,15 +263,15 @@ func WriteFingerprints(opts *WriteOptions) error {
 		}
 
 		// 写入JSON数据和换行符
-		if _, err := outputFile.Write(jsonData); err != nil {
+		if _, err := outputWriter.Write(jsonData); err != nil {
 			return fmt.Errorf("写入JSON数据失败: %v", err)
 		}
-		if _, err := outputFile.Write([]byte("\n")); err != nil {
+		if _, err := outputWriter.Write([]byte("\n")); err != nil {
 			return fmt.Errorf("写入换行符失败: %v", err)
 		}
 
 	} else if opts.Format == "csv" {
-		if err := csvWriter.Write([]string{
+		record := []string{
 			opts.Target,
 			fmt.Sprintf("%d", opts.StatusCode),
 			opts.Title,
@@ -268,7 +286,9 @@ func WriteFingerprints(opts *WriteOptions) error {
 			strings.ReplaceAll(headersStr, "\n", "\\n"), // CSV中换行符需要转义
 			fmt.Sprintf("%v", opts.FinalResult),
 			remark,
-		}); err != nil {
+			opts.RunID,
+		}
+		if err := csvWriter.Write(record); err != nil {
 			return fmt.Errorf("写入CSV记录失败: %v", err)
 		}
 		csvWriter.Flush()
@@ -299,13 +319,17 @@ func WriteFingerprints(opts *WriteOptions) error {
 		sb.WriteString(fmt.Sprintf("%v", opts.FinalResult))
 		sb.WriteString("\n备注: ")
 		sb.WriteString(remark)
+		if opts.RunID != "" {
+			sb.WriteString("\n运行ID: ")
+			sb.WriteString(opts.RunID)
+		}
 		sb.WriteString("\n响应头:\n")
 		sb.WriteString(headersStr)
 		sb.WriteString("\n")
 		sb.WriteString(strings.Repeat("-", 100))
 		sb.WriteString("\n")
 
-		if _, err := outputFile.WriteString(sb.String()); err != nil {
+		if _, err := io.WriteString(encodedWriter(outputWriter), sb.String()); err != nil {
 			return fmt.Errorf("写入结果失败: %v", err)
 		}
 	}
@@ -323,6 +347,63 @@ func WriteResultToFile(targetResult *TargetResult, outputs, format string, lastR
 	}
 }
 
+// Flush 将已写入的结果刷新到磁盘
+func Flush() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if outputFile == nil {
+		return nil
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+	}
+	if gzipWriter != nil {
+		if err := gzipWriter.Flush(); err != nil {
+			return err
+		}
+	}
+	return outputFile.Sync()
+}
+
+// SetFlushInterval 设置定期将输出文件刷新到磁盘的间隔，0表示不定期刷新
+func SetFlushInterval(interval time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	flushInterval = interval
+}
+
+// startPeriodicFlush 启动定期刷新协程，需在持有 mu 时调用
+func startPeriodicFlush() {
+	if flushInterval <= 0 || flushStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	flushStop = stop
+	go func(interval time.Duration) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := Flush(); err != nil {
+					logger.Debugf("定期刷新输出文件出错: %v", err)
+				}
+			}
+		}
+	}(flushInterval)
+}
+
+// stopPeriodicFlush 停止定期刷新协程，需在持有 mu 时调用
+func stopPeriodicFlush() {
+	if flushStop != nil {
+		close(flushStop)
+		flushStop = nil
+	}
+}
+
 // CloseFileOutput 关闭仅文件输出资源
 func CloseFileOutput() error {
 	mu.Lock()
@@ -333,8 +414,14 @@ func CloseFileOutput() error {
 		if csvWriter != nil {
 			csvWriter.Flush()
 		}
+		stopPeriodicFlush()
+		gzErr := closeGzipWriter()
 		err := outputFile.Close()
+		if gzErr != nil {
+			err = gzErr
+		}
 		outputFile = nil
+		outputWriter = nil
 		csvWriter = nil
 		headerWritten = false
 		return err
@@ -342,3 +429,28 @@ func CloseFileOutput() error {
 
 	return nil
 }
+
+// SetOutputGzip 设置是否以gzip压缩格式写入输出文件
+func SetOutputGzip(enable bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	outputGzip = enable
+}
+
+// OutputPath 返回实际写入的输出文件路径，启用gzip压缩时追加.gz扩展名
+func OutputPath(path string) string {
+	if !outputGzip || path == "" || strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return path
+	}
+	return path + ".gz"
+}
+
+// closeGzipWriter 关闭gzip写入器并写入压缩尾部，需在持有 mu 时调用
+func closeGzipWriter() error {
+	if gzipWriter == nil {
+		return nil
+	}
+	err := gzipWriter.Close()
+	gzipWriter = nil
+	return err
+}
@@ -0,0 +1,160 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGzipPrefix 解压gzip文件中已刷新到磁盘的部分，文件未关闭时忽略结尾不完整的错误
+func readGzipPrefix(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	content, _ := io.ReadAll(reader)
+	return string(content)
+}
+
+func TestSetFlushInterval(t *testing.T) {
+	// gzip写入器缓冲压缩数据，文件未关闭时只有刷新后的内容可读
+	SetOutputGzip(true)
+	defer SetOutputGzip(false)
+	SetFlushInterval(20 * time.Millisecond)
+	defer SetFlushInterval(0)
+
+	path := filepath.Join(t.TempDir(), "result.csv")
+	opts := &WriteOptions{Output: path, Format: "csv", Target: "http://flush.example.com", StatusCode: 200}
+	if err := WriteFingerprints(opts); err != nil {
+		t.Fatalf("写入结果失败: %v", err)
+	}
+	defer func() { _ = CloseFileOutput() }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(readGzipPrefix(t, path+".gz"), "http://flush.example.com") {
+		if time.Now().After(deadline) {
+			t.Fatal("文件未关闭时结果未被定期刷新到磁盘")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 关闭输出后停止定期刷新协程
+	if err := CloseFileOutput(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	stopped := flushStop == nil
+	mu.Unlock()
+	if !stopped {
+		t.Error("关闭输出文件后定期刷新协程未停止")
+	}
+}
+
+func TestWriteFingerprintsGzip(t *testing.T) {
+	SetOutputGzip(true)
+	defer SetOutputGzip(false)
+
+	path := filepath.Join(t.TempDir(), "result.csv")
+	if got := OutputPath(path); got != path+".gz" {
+		t.Fatalf("OutputPath = %s，期望追加 .gz", got)
+	}
+	if got := OutputPath(path + ".GZ"); got != path+".GZ" {
+		t.Errorf("已带 .gz 扩展名时 OutputPath = %s，期望不再追加", got)
+	}
+	if got := GetOutputFormat(false, path+".gz"); got != "csv" {
+		t.Errorf("GetOutputFormat(%s.gz) = %s，期望 csv", path, got)
+	}
+
+	// 两次打开文件追加写入，产生两个gzip成员
+	for _, target := range []string{"http://a.example.com", "http://b.example.com"} {
+		if err := WriteFingerprints(&WriteOptions{Output: path, Format: "csv", Target: target, StatusCode: 200}); err != nil {
+			t.Fatalf("写入结果失败: %v", err)
+		}
+		if err := CloseFileOutput(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("启用压缩时不应写入未压缩的文件")
+	}
+
+	file, err := os.Open(path + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("读取gzip文件失败: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("解压失败: %v", err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "\xef\xbb\xbfURL,") {
+		t.Errorf("解压后应以UTF-8 BOM与表头开头:\n%s", content)
+	}
+	if strings.Count(content, "URL,") != 1 || !strings.Contains(content, "http://a.example.com") || !strings.Contains(content, "http://b.example.com") {
+		t.Errorf("解压后内容应包含一次表头与两条记录:\n%s", content)
+	}
+}
+
+func TestWriteFingerprintsCSVRunID(t *testing.T) {
+	defer SetRunID("")
+	path := filepath.Join(t.TempDir(), "result.csv")
+
+	// 同一文件先后追加未启用与启用运行ID的两次扫描，列数保持一致
+	for _, run := range []struct{ id, target string }{
+		{"", "http://a.example.com"},
+		{"20240101120000-abc123", "http://b.example.com"},
+		{"20240101120000-abc123", "http://c.example.com"},
+	} {
+		SetRunID(run.id)
+		opts := CreateWriteOptions(&TargetResult{URL: run.target, StatusCode: 200}, path, "csv", nil)
+		if err := WriteFingerprints(opts); err != nil {
+			t.Fatalf("写入结果失败: %v", err)
+		}
+		if run.id == "" {
+			if err := CloseFileOutput(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := CloseFileOutput(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))).ReadAll()
+	if err != nil {
+		t.Fatalf("解析CSV失败: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("CSV记录 %d 行，期望表头与3条记录", len(records))
+	}
+	last := len(records[0]) - 1
+	if records[0][last] != "运行ID" {
+		t.Errorf("表头最后一列 = %q，期望 运行ID", records[0][last])
+	}
+	want := []string{"", "20240101120000-abc123", "20240101120000-abc123"}
+	for i, record := range records[1:] {
+		if record[last] != want[i] {
+			t.Errorf("第 %d 条记录运行ID = %q，期望 %q", i+1, record[last], want[i])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// redactMask 敏感请求头的值被替换为的掩码
+const redactMask = "******"
+
+// DefaultRedactHeaders 默认在输出中掩码的敏感请求/响应头
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// redactHeaders 输出时需要掩码值的头名称（规范化格式），为空时不掩码
+var redactHeaders = canonicalHeaderSet(DefaultRedactHeaders)
+
+// SetRedactHeaders 设置输出时需要掩码值的头名称，传入nil时使用默认列表，传入空列表时不掩码
+func SetRedactHeaders(names []string) {
+	if names == nil {
+		names = DefaultRedactHeaders
+	}
+	redactHeaders = canonicalHeaderSet(names)
+}
+
+// canonicalHeaderSet 将头名称列表转换为规范化名称集合，忽略空白项
+func canonicalHeaderSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[textproto.CanonicalMIMEHeaderKey(name)] = true
+		}
+	}
+	return set
+}
+
+// redactRaw 掩码原始HTTP报文头部中敏感头的值，报文体保持不变
+func redactRaw(raw string) string {
+	if raw == "" || len(redactHeaders) == 0 {
+		return raw
+	}
+
+	lines := strings.SplitAfter(raw, "\n")
+	for i, line := range lines {
+		content := strings.TrimRight(line, "\r\n")
+		// 空行之后为报文体，不再处理
+		if content == "" && i > 0 {
+			break
+		}
+		name, _, ok := strings.Cut(content, ":")
+		if !ok || !redactHeaders[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] {
+			continue
+		}
+		lines[i] = name + ": " + redactMask + line[len(content):]
+	}
+	return strings.Join(lines, "")
+}
+
+// redactRawMap 掩码按指纹ID分组的原始报文中的敏感头
+func redactRawMap(data map[string]string) map[string]string {
+	if len(data) == 0 || len(redactHeaders) == 0 {
+		return data
+	}
+	redacted := make(map[string]string, len(data))
+	for id, raw := range data {
+		redacted[id] = redactRaw(raw)
+	}
+	return redacted
+}
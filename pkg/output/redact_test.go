@@ -0,0 +1,86 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactRaw(t *testing.T) {
+	defer SetRedactHeaders(nil)
+
+	raw := "GET http://example.com:8080/ HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"authorization: Bearer secret-token\r\n" +
+		"Cookie: session=abc\r\n" +
+		"\r\n" +
+		"Cookie: kept-in-body"
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{
+			"默认列表不区分大小写",
+			nil,
+			"GET http://example.com:8080/ HTTP/1.1\r\nHost: example.com\r\nauthorization: ******\r\nCookie: ******\r\n\r\nCookie: kept-in-body",
+		},
+		{
+			"自定义列表",
+			[]string{" host ", ""},
+			"GET http://example.com:8080/ HTTP/1.1\r\nHost: ******\r\nauthorization: Bearer secret-token\r\nCookie: session=abc\r\n\r\nCookie: kept-in-body",
+		},
+		{"空列表不掩码", []string{}, raw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRedactHeaders(tt.headers)
+			if got := redactRaw(raw); got != tt.want {
+				t.Errorf("redactRaw = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFingerprintsRedact(t *testing.T) {
+	SetRedactHeaders(nil)
+	opts := &WriteOptions{
+		Target:      "http://example.com",
+		StatusCode:  200,
+		RespHeaders: "HTTP/1.1 200 OK\nSet-Cookie: token=secret\nServer: nginx\n",
+		Requests:    map[string]string{"nginx": "GET / HTTP/1.1\r\nX-Api-Key: secret\r\n\r\n"},
+	}
+
+	// JSON输出中的响应头与原始请求均被掩码
+	out := NewJSONOutput(opts)
+	if strings.Contains(out.Headers, "secret") || !strings.Contains(out.Headers, "Server: nginx") {
+		t.Errorf("headers = %q，期望掩码 Set-Cookie 并保留其余响应头", out.Headers)
+	}
+	if strings.Contains(out.Requests["nginx"], "secret") {
+		t.Errorf("requests[nginx] = %q，期望掩码 X-Api-Key", out.Requests["nginx"])
+	}
+
+	// 文件输出同样掩码
+	for _, format := range []string{"txt", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "result."+format)
+			fileOpts := *opts
+			fileOpts.Output, fileOpts.Format = path, format
+			if err := WriteFingerprints(&fileOpts); err != nil {
+				t.Fatalf("写入结果失败: %v", err)
+			}
+			if err := CloseFileOutput(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), "secret") || !strings.Contains(string(data), redactMask) {
+				t.Errorf("输出文件未掩码敏感响应头:\n%s", data)
+			}
+		})
+	}
+}
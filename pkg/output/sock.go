@@ -101,48 +101,10 @@ func WriteToSock(opts *WriteOptions) error {
 		return nil
 	}
 
-	// 收集指纹信息
-	fingersCount := len(opts.Fingers)
-	fingerIDs := make([]string, 0, fingersCount)
-	fingerNames := make([]string, 0, fingersCount)
-
-	for _, f := range opts.Fingers {
-		fingerIDs = append(fingerIDs, f.Id)
-		fingerNames = append(fingerNames, f.Info.Name)
-	}
-
-	// 使用传入的备注或生成默认备注
-	remark := opts.Remark
-	if remark == "" {
-		remark = fmt.Sprintf("发现%d个指纹", fingersCount)
-	}
-
-	// 处理服务器信息
-	serverInfoStr := ""
-	if opts.ServerInfo != nil {
-		serverInfoStr = opts.ServerInfo.ServerType
-	}
-
-	// 格式化响应头
-	headersStr := ""
-	if opts.Response != nil && opts.Response.RawHeader != nil {
-		headersStr = string(opts.Response.RawHeader)
-	} else if opts.RespHeaders != "" {
-		headersStr = opts.RespHeaders
-	}
-
-	// 构建JSON对象
-	jsonOutput := &JSONOutput{
-		URL:         opts.Target,
-		StatusCode:  opts.StatusCode,
-		Title:       opts.Title,
-		Server:      serverInfoStr,
-		FingerIDs:   fingerIDs,
-		FingerNames: fingerNames,
-		Headers:     headersStr,
-		Wappalyzer:  opts.Wappalyzer,
-		MatchResult: opts.FinalResult,
-		Remark:      remark,
+	// 构建JSON对象，丢弃与已发送记录完全相同的结果
+	jsonOutput := NewJSONOutput(opts)
+	if isDuplicateOutput("sock", jsonOutput) {
+		return nil
 	}
 
 	// 序列化为JSON
@@ -1,10 +1,14 @@
 package output
 
 import (
+	"compress/gzip"
 	"encoding/csv"
+	"fmt"
+	"io"
 	"net"
 	"os"
 	"sync"
+	"time"
 	"xfirefly/pkg/finger"
 	"xfirefly/pkg/types"
 	"xfirefly/pkg/utils/proto"
@@ -13,6 +17,9 @@ import (
 
 var (
 	outputFile      *os.File
+	outputWriter    io.Writer    // 结果写入器，启用gzip压缩时为gzipWriter，否则为outputFile
+	gzipWriter      *gzip.Writer // 启用gzip压缩时的压缩写入器
+	outputGzip      bool         // 是否以gzip压缩格式写入输出文件
 	csvWriter       *csv.Writer
 	sockFile        *os.File // socket文件句柄
 	mu              sync.Mutex
@@ -20,53 +27,141 @@ var (
 	sockListener    net.Listener
 	sockConnections = make(map[net.Conn]bool)
 	sockConnMutex   sync.Mutex
+	runID           string        // 当前运行ID，为空时不输出
+	flushInterval   time.Duration // 定期刷新输出文件的间隔，0表示不定期刷新
+	flushStop       chan struct{} // 关闭时通知定期刷新协程退出
+	includeRequest  bool          // JSON输出中是否附带匹配指纹的原始请求
+	includeResponse bool          // JSON输出中是否附带匹配指纹的原始响应
 )
 
 // WriteOptions 定义写入选项结构体，用于传递写入参数
 type WriteOptions struct {
-	Output      string                     // 输出文件路径
-	Format      string                     // 输出格式(csv/txt/json)
-	Target      string                     // 目标URL
-	Fingers     []*finger.Finger           // 指纹列表
-	StatusCode  int32                      // 状态码
-	Title       string                     // 页面标题
-	ServerInfo  *types.ServerInfo          // 服务器信息
-	RespHeaders string                     // 响应头
-	Response    *proto.Response            // 完整响应对象(可选)
-	Wappalyzer  *wappalyzer.TypeWappalyzer // 站点使用技术
-	FinalResult bool                       // 最终匹配结果
-	Remark      string                     // 备注(可选)
+	Output       string                       // 输出文件路径
+	Format       string                       // 输出格式(csv/txt/json)
+	Target       string                       // 目标URL
+	Fingers      []*finger.Finger             // 指纹列表
+	StatusCode   int32                        // 状态码
+	Title        string                       // 页面标题
+	ServerInfo   *types.ServerInfo            // 服务器信息
+	RespHeaders  string                       // 响应头
+	Response     *proto.Response              // 完整响应对象(可选)
+	Wappalyzer   *wappalyzer.TypeWappalyzer   // 站点使用技术
+	FinalResult  bool                         // 最终匹配结果
+	Remark       string                       // 备注(可选)
+	Extracted    map[string]map[string]string // 按指纹ID分组的提取变量
+	MatchedRules map[string][]string          // 按指纹ID分组的命中规则
+	Security     *types.SecurityHeaders       // 安全响应头分析结果
+	TLS          *types.TLSInfo               // TLS协议版本探测结果
+	RequiresAuth bool                         // 首页跳转到了登录/认证页面
+	IconHashes   []string                     // 首页候选icon的hash列表
+	Duration     time.Duration                // 目标扫描耗时
+	RunID        string                       // 运行ID，用于区分多次扫描的结果
+	Requests     map[string]string            // 按指纹ID分组的原始请求(可选)
+	Responses    map[string]string            // 按指纹ID分组的原始响应(可选)
 }
 
 // JSONOutput JSON格式输出结构体
 type JSONOutput struct {
-	URL         string                     `json:"url"`
-	StatusCode  int32                      `json:"status_code"`
-	Title       string                     `json:"title"`
-	Server      string                     `json:"server"`
-	FingerIDs   []string                   `json:"finger_ids,omitempty"`
-	FingerNames []string                   `json:"finger_names,omitempty"`
-	Headers     string                     `json:"headers,omitempty"`
-	Wappalyzer  *wappalyzer.TypeWappalyzer `json:"wappalyzer,omitempty"`
-	MatchResult bool                       `json:"match_result"`
-	Remark      string                     `json:"remark,omitempty"`
+	URL          string                       `json:"url"`
+	StatusCode   int32                        `json:"status_code"`
+	Title        string                       `json:"title"`
+	Server       string                       `json:"server"`
+	FingerIDs    []string                     `json:"finger_ids,omitempty"`
+	FingerNames  []string                     `json:"finger_names,omitempty"`
+	Headers      string                       `json:"headers,omitempty"`
+	Wappalyzer   *wappalyzer.TypeWappalyzer   `json:"wappalyzer,omitempty"`
+	MatchResult  bool                         `json:"match_result"`
+	Remark       string                       `json:"remark,omitempty"`
+	Extracted    map[string]map[string]string `json:"extracted,omitempty"`
+	MatchedRules map[string][]string          `json:"matched_rules,omitempty"`
+	Security     *types.SecurityHeaders       `json:"security_headers,omitempty"`
+	TLS          *types.TLSInfo               `json:"tls,omitempty"`
+	RequiresAuth bool                         `json:"requires_auth,omitempty"`
+	IconHashes   []string                     `json:"icon_hashes,omitempty"`
+	Duration     int64                        `json:"duration_ms,omitempty"` // 目标扫描耗时（毫秒）
+	RunID        string                       `json:"run_id,omitempty"`
+	Requests     map[string]string            `json:"requests,omitempty"`  // 按指纹ID分组的原始请求
+	Responses    map[string]string            `json:"responses,omitempty"` // 按指纹ID分组的原始响应
 }
 
 // TargetResult 存储每个目标的扫描结果
 type TargetResult struct {
-	URL        string                     // 目标地址
-	StatusCode int32                      // 状态码
-	Title      string                     // 站点标题
-	ServerInfo *types.ServerInfo          // server信息
-	Fingers    []*finger.Finger           // 匹配的指纹列表
-	Matches    []*FingerMatch             // 匹配详细信息
-	Wappalyzer *wappalyzer.TypeWappalyzer // 站点信息数据
+	URL          string                     // 目标地址
+	StatusCode   int32                      // 状态码
+	Title        string                     // 站点标题
+	ServerInfo   *types.ServerInfo          // server信息
+	Fingers      []*finger.Finger           // 匹配的指纹列表
+	Matches      []*FingerMatch             // 匹配详细信息
+	Wappalyzer   *wappalyzer.TypeWappalyzer // 站点信息数据
+	Security     *types.SecurityHeaders     // 安全响应头分析结果
+	TLS          *types.TLSInfo             // TLS协议版本探测结果
+	RequiresAuth bool                       // 首页跳转到了登录/认证页面
+	IconHashes   []string                   // 首页候选icon的hash列表
+	Duration     time.Duration              // 目标扫描耗时
 }
 
 // FingerMatch 存储每个匹配的指纹信息
 type FingerMatch struct {
-	Finger   *finger.Finger  // 指纹信息
-	Result   bool            // 识别结果
-	Request  *proto.Request  // 请求数据
-	Response *proto.Response // 响应数据
+	Finger       *finger.Finger    // 指纹信息
+	Result       bool              // 识别结果
+	Request      *proto.Request    // 请求数据
+	Response     *proto.Response   // 响应数据
+	Extracted    map[string]string // 规则output中提取的变量
+	MatchedRules []string          // 命中的规则，格式为 "规则名: 表达式"
+}
+
+// NewJSONOutput 根据写入选项构建JSON输出对象
+func NewJSONOutput(opts *WriteOptions) *JSONOutput {
+	// 收集指纹信息
+	fingersCount := len(opts.Fingers)
+	fingerIDs := make([]string, 0, fingersCount)
+	fingerNames := make([]string, 0, fingersCount)
+
+	for _, f := range opts.Fingers {
+		fingerIDs = append(fingerIDs, f.Id)
+		fingerNames = append(fingerNames, f.Info.Name)
+	}
+
+	// 使用传入的备注或生成默认备注
+	remark := opts.Remark
+	if remark == "" {
+		remark = fmt.Sprintf("发现%d个指纹", fingersCount)
+	}
+
+	// 处理服务器信息
+	serverInfoStr := ""
+	if opts.ServerInfo != nil {
+		serverInfoStr = opts.ServerInfo.ServerType
+	}
+
+	// 格式化响应头
+	headersStr := ""
+	if opts.Response != nil && opts.Response.RawHeader != nil {
+		headersStr = string(opts.Response.RawHeader)
+	} else if opts.RespHeaders != "" {
+		headersStr = opts.RespHeaders
+	}
+
+	return &JSONOutput{
+		URL:          opts.Target,
+		StatusCode:   opts.StatusCode,
+		Title:        opts.Title,
+		Server:       serverInfoStr,
+		FingerIDs:    fingerIDs,
+		FingerNames:  fingerNames,
+		Headers:      redactRaw(headersStr),
+		Wappalyzer:   opts.Wappalyzer,
+		MatchResult:  opts.FinalResult,
+		Remark:       remark,
+		Extracted:    opts.Extracted,
+		MatchedRules: opts.MatchedRules,
+		Security:     opts.Security,
+		TLS:          opts.TLS,
+		RequiresAuth: opts.RequiresAuth,
+		IconHashes:   opts.IconHashes,
+		Duration:     opts.Duration.Milliseconds(),
+		RunID:        opts.RunID,
+		Requests:     redactRawMap(opts.Requests),
+		Responses:    redactRawMap(opts.Responses),
+	}
 }
@@ -1,7 +1,9 @@
 package runner
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -9,6 +11,7 @@ import (
 	"xfirefly/pkg/finger"
 	"xfirefly/pkg/utils/common"
 	"xfirefly/pkg/utils/proto"
+	"xfirefly/pkg/wappalyzer"
 
 	"github.com/donnie4w/go-logger/logger"
 )
@@ -29,9 +32,23 @@ type CacheManager struct {
 	lastCleanup time.Time     // 上次清理时间
 }
 
+// BodyResultCache 按响应内容哈希缓存指纹识别结果，相同默认页面的目标直接复用
+type BodyResultCache struct {
+	entries map[string][]*FingerMatch
+	order   []string // 插入顺序，用于先进先出驱逐
+	mutex   sync.Mutex
+	maxSize int // 最大缓存条目数
+}
+
 // 全局缓存管理器
 var globalCacheManager *CacheManager
 
+// 全局响应内容结果缓存
+var globalBodyResultCache = &BodyResultCache{
+	entries: make(map[string][]*FingerMatch, 256),
+	maxSize: 1024,
+}
+
 // 初始化缓存管理器
 func init() {
 	globalCacheManager = &CacheManager{
@@ -108,13 +125,14 @@ func (cm *CacheManager) evictOldestEntries() {
 	}
 }
 
-// GenerateCacheKey 生成缓存键
-func GenerateCacheKey(target string, method string, followRedirects bool) string {
-	return common.MD5Hash(target + ":" + method + ":" + strconv.FormatBool(followRedirects))
+// GenerateCacheKey 生成缓存键，不同代理下请求得到的响应可能不同，需一并纳入
+// 带自定义请求头或cookie的规则请求不会写入或读取缓存，因此键中无需包含请求头
+func GenerateCacheKey(target string, method string, followRedirects bool, proxy string) string {
+	return common.MD5Hash(target + ":" + method + ":" + strconv.FormatBool(followRedirects) + ":" + proxy)
 }
 
 // ShouldUseCache 判断是否应该使用缓存，对于根路径的GET请求，可以重用缓存的请求和响应
-func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
+func ShouldUseCache(rule finger.RuleMap, target string, proxy string) (bool, CacheRequest) {
 	var caches CacheRequest
 	reqType := strings.ToLower(rule.Value.Request.Type)
 	method := strings.ToUpper(rule.Value.Request.Method)
@@ -124,8 +142,8 @@ func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
 		return false, caches
 	}
 
-	// 只允许GET或POST请求且header为空、body为空时使用缓存
-	isEmptyHeaders := len(rule.Value.Request.Headers) == 0
+	// 只允许GET或POST请求且header、cookie为空、body为空时使用缓存
+	isEmptyHeaders := len(rule.Value.Request.Headers) == 0 && len(rule.Value.Request.Cookies) == 0
 	isEmptyBody := rule.Value.Request.Body == ""
 	isGetOrPost := method == "GET" || method == "POST"
 
@@ -138,7 +156,7 @@ func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
 	}
 
 	urlStr := common.RemoveTrailingSlash(target)
-	cacheKey := GenerateCacheKey(urlStr, method, rule.Value.Request.FollowRedirects)
+	cacheKey := GenerateCacheKey(urlStr, method, rule.Value.Request.FollowRedirects, proxy)
 
 	logger.Debugf("缓存提取key：%s %s %s %t", cacheKey, urlStr, method, rule.Value.Request.FollowRedirects)
 
@@ -166,8 +184,8 @@ func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
 	return false, caches
 }
 
-// UpdateTargetCache 更新特定目标的请求响应缓存
-func UpdateTargetCache(variableMap map[string]any, target string, followRedirects bool) {
+// UpdateTargetCache 更新特定目标在指定代理下的请求响应缓存
+func UpdateTargetCache(variableMap map[string]any, target string, followRedirects bool, proxy string) {
 	var req *proto.Request
 	var resp *proto.Response
 
@@ -199,14 +217,14 @@ func UpdateTargetCache(variableMap map[string]any, target string, followRedirect
 	}
 
 	urlStr := common.RemoveTrailingSlash(target)
-	cacheKey := GenerateCacheKey(urlStr, method, followRedirects)
+	cacheKey := GenerateCacheKey(urlStr, method, followRedirects, proxy)
 
 	logger.Debug(fmt.Sprintf("请求缓存key：%s %s %s %t", cacheKey, urlStr, method, followRedirects))
 
 	// 创建缓存条目（对大字段进行截断，避免缓存过大）
 	const maxCacheSize = 1 << 20 // 1MB
-	if len(resp.Body) > maxCacheSize {
-		resp.Body = resp.Body[:maxCacheSize]
+	if len(resp.Rawbody) > maxCacheSize {
+		resp.SetBody(resp.Rawbody[:maxCacheSize])
 	}
 	if len(resp.Raw) > maxCacheSize {
 		resp.Raw = resp.Raw[:maxCacheSize]
@@ -238,8 +256,8 @@ func UpdateTargetCache(variableMap map[string]any, target string, followRedirect
 	globalCacheManager.cache[cacheKey] = cacheEntry
 }
 
-// ClearTargetURLCache 删除与特定URL相关的所有缓存，无论请求方法和跟随重定向设置如何
-func ClearTargetURLCache(target string) {
+// ClearTargetURLCache 删除与特定URL在指定代理下的所有缓存，无论请求方法和跟随重定向设置如何
+func ClearTargetURLCache(target string, proxy string) {
 	if target == "" {
 		return
 	}
@@ -255,7 +273,7 @@ func ClearTargetURLCache(target string) {
 
 	for _, method := range methods {
 		for _, redirect := range redirectOptions {
-			key := GenerateCacheKey(urlStr, method, redirect)
+			key := GenerateCacheKey(urlStr, method, redirect, proxy)
 			keysToDelete = append(keysToDelete, key)
 		}
 	}
@@ -282,9 +300,111 @@ func ClearAllCache() {
 	// 重新初始化缓存映射
 	globalCacheManager.cache = make(map[string]*CacheRequest, 2048)
 	globalCacheManager.mutex.Unlock()
+
+	globalBodyResultCache.mutex.Lock()
+	globalBodyResultCache.entries = make(map[string][]*FingerMatch, 256)
+	globalBodyResultCache.order = nil
+	globalBodyResultCache.mutex.Unlock()
 	logger.Debug("已清空所有缓存")
 }
 
+// GenerateBodyCacheKey 根据被动规则可读取的全部首页响应内容生成哈希键，响应体为空时返回空串
+// 包含状态码、响应头（含Set-Cookie）、Trailer、响应体、icon hash与Wappalyzer识别结果，
+// 仅排除每次请求都会变化的Date头，避免不同站点因部分内容相同而共享识别结果
+func GenerateBodyCacheKey(response *proto.Response, wapp *wappalyzer.TypeWappalyzer) string {
+	if response == nil || len(response.Body) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(strconv.Itoa(int(response.Status)))
+	builder.WriteString("\n")
+	writeSortedMap(&builder, response.Headers, "date")
+	writeSortedMap(&builder, response.Trailers, "")
+	writeSortedMap(&builder, response.Cert, "")
+	builder.WriteString(response.IconHash)
+	builder.WriteString("\n")
+	builder.WriteString(strings.Join(response.IconHashes, ","))
+	builder.WriteString("\n")
+	if wapp != nil {
+		if data, err := json.Marshal(wapp); err == nil {
+			builder.Write(data)
+		}
+	}
+	builder.WriteString("\n")
+	builder.Write(response.Body)
+	return common.SHA256Hash(builder.String())
+}
+
+// writeSortedMap 按键排序写入map内容，保证相同内容生成相同的哈希键，skip 为需要忽略的键
+func writeSortedMap(builder *strings.Builder, m map[string]string, skip string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != skip {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		builder.WriteString(k)
+		builder.WriteString(": ")
+		builder.WriteString(m[k])
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+}
+
+// copyFingerMatches 复制匹配结果，仅保留指纹、提取数据与命中规则，避免与输出阶段释放的请求响应共享
+func copyFingerMatches(matches []*FingerMatch) []*FingerMatch {
+	copied := make([]*FingerMatch, 0, len(matches))
+	for _, m := range matches {
+		if m == nil {
+			continue
+		}
+		copied = append(copied, &FingerMatch{
+			Finger:       m.Finger,
+			Result:       m.Result,
+			Extracted:    m.Extracted,
+			MatchedRules: m.MatchedRules,
+		})
+	}
+	return copied
+}
+
+// GetBodyCacheMatches 获取内容哈希对应的已识别结果
+func GetBodyCacheMatches(key string) ([]*FingerMatch, bool) {
+	if key == "" {
+		return nil, false
+	}
+	globalBodyResultCache.mutex.Lock()
+	defer globalBodyResultCache.mutex.Unlock()
+
+	matches, ok := globalBodyResultCache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return copyFingerMatches(matches), true
+}
+
+// StoreBodyCacheMatches 保存内容哈希对应的识别结果，超出容量时驱逐最早的条目
+func StoreBodyCacheMatches(key string, matches []*FingerMatch) {
+	if key == "" {
+		return
+	}
+	globalBodyResultCache.mutex.Lock()
+	defer globalBodyResultCache.mutex.Unlock()
+
+	if _, exists := globalBodyResultCache.entries[key]; !exists {
+		if len(globalBodyResultCache.order) >= globalBodyResultCache.maxSize {
+			oldest := globalBodyResultCache.order[0]
+			globalBodyResultCache.order = globalBodyResultCache.order[1:]
+			delete(globalBodyResultCache.entries, oldest)
+		}
+		globalBodyResultCache.order = append(globalBodyResultCache.order, key)
+	}
+	globalBodyResultCache.entries[key] = copyFingerMatches(matches)
+}
+
 // GetCacheStats 获取缓存统计信息
 func GetCacheStats() map[string]interface{} {
 	globalCacheManager.mutex.RLock()
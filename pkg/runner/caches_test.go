@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"testing"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestTargetCacheProxyIsolation(t *testing.T) {
+	const (
+		target = "http://cache-proxy.example/"
+		proxyA = "http://127.0.0.1:8080"
+		proxyB = "socks5://127.0.0.1:1080"
+	)
+	defer ClearTargetURLCache(target, proxyA)
+
+	resp := &proto.Response{Status: 200}
+	resp.SetBody([]byte("via proxy a"))
+	UpdateTargetCache(map[string]any{
+		"request":  &proto.Request{Method: "GET"},
+		"response": resp,
+	}, target, false, proxyA)
+
+	rule := finger.RuleMap{Key: "r0", Value: finger.Rule{Request: finger.RuleRequest{Method: "GET", Path: "/"}}}
+	if ok, cache := ShouldUseCache(rule, target, proxyA); !ok || string(cache.Response.Body) != "via proxy a" {
+		t.Errorf("相同代理应命中缓存，命中 = %v", ok)
+	}
+	if ok, _ := ShouldUseCache(rule, target, proxyB); ok {
+		t.Error("不同代理不应共用缓存")
+	}
+	if ok, _ := ShouldUseCache(rule, target, ""); ok {
+		t.Error("直连请求不应使用代理请求的缓存")
+	}
+
+	// 带自定义请求头的规则不读取缓存
+	withHeaders := rule
+	withHeaders.Value.Request.Headers = map[string]string{"X-Api-Version": "2"}
+	if ok, _ := ShouldUseCache(withHeaders, target, proxyA); ok {
+		t.Error("带自定义请求头的规则不应使用缓存")
+	}
+
+	// 仅清除指定代理下的缓存
+	ClearTargetURLCache(target, proxyB)
+	if ok, _ := ShouldUseCache(rule, target, proxyA); !ok {
+		t.Error("清除其它代理的缓存不应影响当前代理")
+	}
+	ClearTargetURLCache(target, proxyA)
+	if ok, _ := ShouldUseCache(rule, target, proxyA); ok {
+		t.Error("清除后不应命中缓存")
+	}
+}
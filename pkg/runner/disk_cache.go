@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils/common"
+	"xfirefly/pkg/utils/proto"
+	"xfirefly/pkg/wappalyzer"
+
+	"github.com/donnie4w/go-logger/logger"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// diskCacheTTL 磁盘缓存有效期
+const diskCacheTTL = 24 * time.Hour
+
+// diskCacheDir 基础信息持久化缓存目录，为空时不启用
+var diskCacheDir string
+
+// BaseInfoCacheEntry 持久化的目标基础信息缓存条目
+type BaseInfoCacheEntry struct {
+	URL          string                     `json:"url"`
+	Title        string                     `json:"title"`
+	Server       *types.ServerInfo          `json:"server"`
+	StatusCode   int32                      `json:"status_code"`
+	RequiresAuth bool                       `json:"requires_auth,omitempty"` // 首页跳转到了登录/认证页面
+	Wappalyzer   *wappalyzer.TypeWappalyzer `json:"wappalyzer"`
+	Cache        *CacheRequest              `json:"cache"`
+}
+
+// cacheRequestJSON CacheRequest 的JSON中间结构，请求响应以protojson编码
+type cacheRequestJSON struct {
+	Request   json.RawMessage `json:"request,omitempty"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// MarshalJSON 将缓存条目序列化为JSON，proto字段使用protojson编码
+func (c *CacheRequest) MarshalJSON() ([]byte, error) {
+	aux := cacheRequestJSON{Timestamp: c.Timestamp}
+	if c.Request != nil {
+		data, err := protojson.Marshal(c.Request)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求失败: %v", err)
+		}
+		aux.Request = data
+	}
+	if c.Response != nil {
+		// body 与 rawbody 内容相同，仅保存 body，加载时恢复 rawbody
+		resp := protobuf.Clone(c.Response).(*proto.Response)
+		resp.Rawbody = nil
+		data, err := protojson.Marshal(resp)
+		if err != nil {
+			return nil, fmt.Errorf("序列化响应失败: %v", err)
+		}
+		aux.Response = data
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON 从JSON反序列化缓存条目
+func (c *CacheRequest) UnmarshalJSON(data []byte) error {
+	var aux cacheRequestJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Timestamp = aux.Timestamp
+	c.Request = nil
+	c.Response = nil
+	if len(aux.Request) > 0 && string(aux.Request) != "null" {
+		c.Request = &proto.Request{}
+		if err := protojson.Unmarshal(aux.Request, c.Request); err != nil {
+			return fmt.Errorf("反序列化请求失败: %v", err)
+		}
+	}
+	if len(aux.Response) > 0 && string(aux.Response) != "null" {
+		c.Response = &proto.Response{}
+		if err := protojson.Unmarshal(aux.Response, c.Response); err != nil {
+			return fmt.Errorf("反序列化响应失败: %v", err)
+		}
+		// 仅保存了 body，反序列化得到的是独立副本，rawbody 直接与其共用
+		c.Response.Rawbody = c.Response.Body
+	}
+	return nil
+}
+
+// SetCacheDir 设置基础信息持久化缓存目录，目录不存在时自动创建，传入空串关闭持久化缓存
+func SetCacheDir(dir string) error {
+	if dir == "" {
+		diskCacheDir = ""
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	diskCacheDir = dir
+	return nil
+}
+
+// diskCachePath 根据目标与影响探测结果的参数生成缓存文件路径
+func diskCachePath(target string, config *ScanConfig) string {
+	key := common.MD5Hash(fmt.Sprintf("%s:%s:%s:%s:%t:%t", target, config.probeMethod(), config.Proxy, config.PathPrefix, config.IgnoreBody, config.NoWappalyzer))
+	return filepath.Join(diskCacheDir, key+".json")
+}
+
+// loadBaseInfoDiskCache 从磁盘缓存读取目标基础信息，未启用、不存在或已过期时返回 false
+func loadBaseInfoDiskCache(target string, config *ScanConfig) (*BaseInfoResponse, *proto.Request, *proto.Response, bool) {
+	if diskCacheDir == "" {
+		return nil, nil, nil, false
+	}
+	path := diskCachePath(target, config)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, false
+	}
+
+	var entry BaseInfoCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logger.Debugf("解析缓存文件 %s 失败: %v", path, err)
+		return nil, nil, nil, false
+	}
+	if entry.Cache == nil || entry.Cache.Request == nil || entry.Cache.Response == nil {
+		return nil, nil, nil, false
+	}
+	if time.Since(time.Unix(entry.Cache.Timestamp, 0)) > diskCacheTTL {
+		_ = os.Remove(path)
+		return nil, nil, nil, false
+	}
+	if entry.Server == nil {
+		entry.Server = types.EmptyServerInfo()
+	}
+
+	// 基于缓存的响应重建HTTP响应，供安全响应头等后续分析使用
+	resp := entry.Cache.Response
+	header := make(http.Header, len(resp.Headers))
+	for k, v := range resp.Headers {
+		header.Set(k, v)
+	}
+	httpResp := &http.Response{
+		StatusCode:    int(resp.Status),
+		Status:        http.StatusText(int(resp.Status)),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+	}
+
+	logger.Debugf("目标 %s 命中磁盘缓存", target)
+	return &BaseInfoResponse{
+		Url:          entry.URL,
+		Title:        entry.Title,
+		Server:       entry.Server,
+		StatusCode:   entry.StatusCode,
+		RequiresAuth: entry.RequiresAuth,
+		Response:     httpResp,
+		Wappalyzer:   entry.Wappalyzer,
+		BodyBytes:    resp.Body,
+	}, entry.Cache.Request, entry.Cache.Response, true
+}
+
+// storeBaseInfoDiskCache 将目标基础信息写入磁盘缓存
+func storeBaseInfoDiskCache(target string, config *ScanConfig, base *BaseInfoResponse, req *proto.Request, resp *proto.Response) {
+	if diskCacheDir == "" || base == nil || req == nil || resp == nil {
+		return
+	}
+	entry := &BaseInfoCacheEntry{
+		URL:          base.Url,
+		Title:        base.Title,
+		Server:       base.Server,
+		StatusCode:   base.StatusCode,
+		RequiresAuth: base.RequiresAuth,
+		Wappalyzer:   base.Wappalyzer,
+		Cache: &CacheRequest{
+			Request:   req,
+			Response:  resp,
+			Timestamp: time.Now().Unix(),
+		},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Debugf("序列化缓存条目失败: %v", err)
+		return
+	}
+
+	// 先写临时文件再重命名，避免并发读取到不完整的内容
+	path := diskCachePath(target, config)
+	tmpFile, err := os.CreateTemp(diskCacheDir, ".tmp-*")
+	if err != nil {
+		logger.Debugf("创建缓存文件失败: %v", err)
+		return
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		logger.Debugf("写入缓存文件失败: %v", err)
+		return
+	}
+	_ = tmpFile.Close()
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		logger.Debugf("保存缓存文件失败: %v", err)
+	}
+}
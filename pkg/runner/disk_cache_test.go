@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"xfirefly/pkg/utils/proto"
+)
+
+func TestCacheRequestJSONStoresBodyOnce(t *testing.T) {
+	resp := &proto.Response{Status: 200}
+	resp.SetBody([]byte("hello\xffworld"))
+	entry := &CacheRequest{Request: &proto.Request{Method: "GET"}, Response: resp, Timestamp: 1}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if strings.Contains(string(data), `"rawbody"`) {
+		t.Errorf("序列化结果重复保存了 rawbody: %s", data)
+	}
+	if string(resp.Rawbody) != "hello\xffworld" {
+		t.Errorf("序列化修改了原响应的 rawbody: %q", resp.Rawbody)
+	}
+
+	var loaded CacheRequest
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if string(loaded.Response.Body) != string(resp.Body) || string(loaded.Response.Rawbody) != string(resp.Body) {
+		t.Errorf("加载后 body = %q, rawbody = %q，期望 %q", loaded.Response.Body, loaded.Response.Rawbody, resp.Body)
+	}
+}
+
+func TestBaseInfoDiskCacheKeepsRequiresAuth(t *testing.T) {
+	if err := SetCacheDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetCacheDir("") }()
+
+	config := &ScanConfig{}
+	resp := &proto.Response{Status: 200}
+	resp.SetBody([]byte("login page"))
+	base := &BaseInfoResponse{Url: "http://example.com/", StatusCode: 200, RequiresAuth: true}
+	storeBaseInfoDiskCache("http://example.com/", config, base, &proto.Request{Method: "GET"}, resp)
+
+	loaded, _, _, ok := loadBaseInfoDiskCache("http://example.com/", config)
+	if !ok {
+		t.Fatal("未命中磁盘缓存")
+	}
+	if !loaded.RequiresAuth {
+		t.Error("磁盘缓存未保存 RequiresAuth")
+	}
+}
+
+func TestBaseInfoDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := SetCacheDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetCacheDir("") }()
+
+	const target = "http://example.com/"
+	config := &ScanConfig{}
+	resp := &proto.Response{Status: 200, Headers: map[string]string{"server": "nginx"}}
+	resp.SetBody([]byte("<title>cached</title>"))
+	base := &BaseInfoResponse{Url: target, Title: "cached", StatusCode: 200}
+	storeBaseInfoDiskCache(target, config, base, &proto.Request{Method: "GET"}, resp)
+
+	loaded, req, cachedResp, ok := loadBaseInfoDiskCache(target, config)
+	if !ok {
+		t.Fatal("未命中磁盘缓存")
+	}
+	if loaded.Title != "cached" || loaded.StatusCode != 200 || req.Method != "GET" {
+		t.Errorf("缓存的基础信息 = %+v，请求方法 = %s", loaded, req.Method)
+	}
+	if string(cachedResp.Body) != string(resp.Body) || loaded.Response.Header.Get("Server") != "nginx" {
+		t.Errorf("缓存的响应 body = %q，server = %q", cachedResp.Body, loaded.Response.Header.Get("Server"))
+	}
+
+	// 影响探测结果的参数不同时不共用缓存
+	if _, _, _, ok := loadBaseInfoDiskCache(target, &ScanConfig{Proxy: "http://127.0.0.1:8080"}); ok {
+		t.Error("代理不同时不应命中缓存")
+	}
+	if _, _, _, ok := loadBaseInfoDiskCache(target, &ScanConfig{IgnoreBody: true}); ok {
+		t.Error("忽略响应体时不应命中完整响应的缓存")
+	}
+	if _, _, _, ok := loadBaseInfoDiskCache(target, &ScanConfig{NoWappalyzer: true}); ok {
+		t.Error("禁用Wappalyzer时不应命中含站点技术识别结果的缓存")
+	}
+
+	// 过期的缓存被删除
+	path := diskCachePath(target, config)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry BaseInfoCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Cache.Timestamp = time.Now().Add(-diskCacheTTL - time.Minute).Unix()
+	if data, err = json.Marshal(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, ok := loadBaseInfoDiskCache(target, config); ok {
+		t.Error("过期的缓存不应命中")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("过期的缓存文件应被删除")
+	}
+
+	// 关闭持久化缓存后不再读写
+	_ = SetCacheDir("")
+	if _, _, _, ok := loadBaseInfoDiskCache(target, config); ok {
+		t.Error("关闭磁盘缓存后不应命中")
+	}
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+	"xfirefly/pkg/network"
+)
+
+// ErrorKind 目标扫描失败的错误类别
+type ErrorKind string
+
+// 错误类别
+const (
+	ErrorKindTimeout ErrorKind = "timeout" // 连接或读取超时
+	ErrorKindDNS     ErrorKind = "dns"     // 域名解析失败
+	ErrorKindRefused ErrorKind = "refused" // 连接被拒绝
+	ErrorKindReset   ErrorKind = "reset"   // 连接被重置或提前关闭
+	ErrorKindTLS     ErrorKind = "tls"     // TLS握手或证书错误
+	ErrorKindOffline ErrorKind = "offline" // 离线模式下禁止请求
+	ErrorKindOther   ErrorKind = "other"   // 其他错误
+)
+
+// ClassifyError 将扫描错误归类，优先按错误类型判断，错误链被格式化丢失时按错误信息判断
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	switch {
+	case errors.Is(err, network.ErrOffline):
+		return ErrorKindOffline
+	case errors.As(err, &dnsErr):
+		return ErrorKindDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorKindRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorKindReset
+	case errors.As(err, &certErr), errors.As(err, &hostErr):
+		return ErrorKindTLS
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "离线模式"):
+		return ErrorKindOffline
+	case strings.Contains(msg, "no such host"):
+		return ErrorKindDNS
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"):
+		return ErrorKindTimeout
+	case strings.Contains(msg, "connection refused"):
+		return ErrorKindRefused
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "eof"):
+		return ErrorKindReset
+	case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"):
+		return ErrorKindTLS
+	default:
+		return ErrorKindOther
+	}
+}
+
+// ScanError 一次扫描中失败目标的汇总，按错误类别计数
+type ScanError struct {
+	Total  int               // 扫描目标总数
+	Failed int               // 失败目标数
+	Kinds  map[ErrorKind]int // 各错误类别的失败目标数
+}
+
+// Error 实现error接口，输出失败汇总
+func (e *ScanError) Error() string {
+	kinds := make([]string, 0, len(e.Kinds))
+	for kind, count := range e.Kinds {
+		kinds = append(kinds, fmt.Sprintf("%s: %d", kind, count))
+	}
+	sort.Strings(kinds)
+	return fmt.Sprintf("%d/%d 个目标扫描失败（%s）", e.Failed, e.Total, strings.Join(kinds, ", "))
+}
+
+// Ratio 返回指定错误类别占目标总数的比例
+func (e *ScanError) Ratio(kind ErrorKind) float64 {
+	if e.Total == 0 {
+		return 0
+	}
+	return float64(e.Kinds[kind]) / float64(e.Total)
+}
+
+// newScanError 根据本次扫描目标的结果汇总失败目标，没有失败目标时返回nil
+// 仅统计 targets 中的目标，Runner 多次运行时不会计入之前的结果
+func newScanError(targets []string, results map[string]*TargetResult) *ScanError {
+	scanErr := &ScanError{Total: len(targets), Kinds: make(map[ErrorKind]int)}
+	for _, target := range targets {
+		result := results[target]
+		if result == nil || result.Err == nil {
+			continue
+		}
+		scanErr.Failed++
+		scanErr.Kinds[ClassifyError(result.Err)]++
+	}
+	if scanErr.Failed == 0 {
+		return nil
+	}
+	return scanErr
+}
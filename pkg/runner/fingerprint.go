@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/donnie4w/go-logger/logger"
 	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
 )
 
 // AllFinger 全局指纹数据
@@ -35,30 +38,41 @@ func GetAllFingerSnapshot() []*finger.Finger {
 
 // LoadFingerprints 加载指纹规则文件，支持从默认嵌入指纹库、指定目录或单个YAML文件加载
 func LoadFingerprints(options types.YamlFingerType) error {
-	// 指纹数据锁
-	allFingerMutex.Lock()
-	defer allFingerMutex.Unlock()
+	fingers, err := loadFingerprintSources(options)
+	if err != nil {
+		return err
+	}
 
-	// 清空现有指纹规则
-	AllFinger = AllFinger[:0]
+	// 所有来源均未加载到指纹时直接报错，避免扫描"成功"却匹配不到任何结果
+	if len(fingers) == 0 {
+		return fmt.Errorf("未加载到任何指纹规则，请检查内置指纹库或通过 -f/--finger-path 指定指纹")
+	}
 
+	// 指纹数据锁，仅在替换时持有
+	allFingerMutex.Lock()
+	AllFinger = fingers
+	allFingerMutex.Unlock()
+	return nil
+}
+
+// loadFingerprintSources 按优先级从指定文件、指定目录、当前目录fingerprint或内置指纹库加载指纹，不修改 AllFinger，调用方无需持有锁
+func loadFingerprintSources(options types.YamlFingerType) ([]*finger.Finger, error) {
 	// 加载单个指纹文件
 	if len(options.FingerYaml) != 0 {
 		logger.Infof("正在加载指纹文件：%s", options.FingerYaml)
 
 		for _, fyaml := range options.FingerYaml {
 			if !common.IsYamlFile(fyaml) {
-				return fmt.Errorf("%s 不是有效的yaml指纹文件", fyaml)
+				return nil, fmt.Errorf("%s 不是有效的yaml指纹文件", fyaml)
 			}
 
 			poc, err := finger.Read(fyaml)
 			if err != nil {
-				return fmt.Errorf("读取yaml指纹文件出错: %v", err)
+				return nil, fmt.Errorf("读取yaml指纹文件出错: %v", err)
 			}
 
 			if poc != nil {
-				AllFinger = append(AllFinger, poc)
-				return nil
+				return []*finger.Finger{poc}, nil
 			}
 		}
 	}
@@ -67,12 +81,7 @@ func LoadFingerprints(options types.YamlFingerType) error {
 	if options.FingerPath != "" {
 		logger.Infof("正在加载 %s 目录下的指纹文件", options.FingerPath)
 
-		fin, err := utils.GetCustomFingerYaml(options.FingerPath)
-		if err != nil {
-			return err
-		}
-		AllFinger = fin
-		return nil
+		return utils.GetCustomFingerYaml(options.FingerPath)
 
 		//return filepath.WalkDir(options.FingerPath, func(path string, d os.DirEntry, err error) error {
 		//	if err != nil {
@@ -94,12 +103,7 @@ func LoadFingerprints(options types.YamlFingerType) error {
 		logger.Info("发现fingerprint目录,正在验证目录下的指纹文件")
 		if common.ExistYamlFile(customFingerPath) {
 			logger.Info("自定义指纹库验证成功，正在尝试加载")
-			fin, err := utils.GetCustomFingerYaml(customFingerPath)
-			if err != nil {
-				return err
-			}
-			AllFinger = fin
-			return nil
+			return utils.GetCustomFingerYaml(customFingerPath)
 		} else {
 			logger.Warn("fingerprint目录下无有效指纹文件，将尝试加载内置指纹库")
 		}
@@ -109,15 +113,10 @@ func LoadFingerprints(options types.YamlFingerType) error {
 	if len(options.FingerYaml) == 0 && options.FingerPath == "" {
 		logger.Info("未指定指纹选项，将使用内置指纹库")
 		// 获取指纹规则
-		fin, err := utils.GetFingerYaml()
-		if err != nil {
-			return err
-		}
-		AllFinger = fin
-		return nil
+		return utils.GetFingerYaml()
 	}
 
-	return nil
+	return nil, nil
 }
 
 // GetFingerCount 获取指纹规则数量（线程安全）
@@ -127,8 +126,9 @@ func GetFingerCount() int {
 	return len(AllFinger)
 }
 
-// evaluateFingerprintWithCache 使用缓存的基础信息评估指纹规则，执行单个指纹的识别逻辑，包括发送请求和规则评估
-func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *BaseInfo, proxy string, timeout int, fingerActive bool) (*FingerMatch, error) {
+// evaluateFingerprintWithCache 使用缓存的基础信息评估指纹规则，执行单个指纹的识别逻辑，包括发送请求和规则评估，
+// ctx 取消或超时后中止正在发送的请求并返回 ctx 的错误
+func evaluateFingerprintWithCache(ctx context.Context, fg *finger.Finger, target string, baseInfo *BaseInfo, proxy string, timeout int, fingerActive bool) (*FingerMatch, error) {
 	customLib := cel2.NewCustomLib()
 
 	// 初始化变量映射
@@ -137,6 +137,8 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		Result: false, // 默认为false
 	}
 	varMap := make(map[string]any)
+	extracted := make(map[string]string)
+	matchedRules := make([]string, 0, len(fg.Rules))
 
 	logger.Debug(fmt.Sprintf("执行指纹识别：%s", fg.Id))
 
@@ -150,12 +152,16 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		Headers:     map[string]string{},
 		ContentType: "",
 		Body:        []byte{},
+		Rawbody:     []byte{},
 		Raw:         []byte{},
 		RawHeader:   []byte{},
 		Url:         &proto.UrlType{},
 		Latency:     0,
 	}
 
+	// 先注入目标变量，set中同名的自定义变量可覆盖
+	finger.SetTargetVariables(target, varMap)
+
 	// 处理预设规则
 	if len(fg.Set) > 0 {
 		finger.IsFuzzSet(fg.Set, varMap, customLib)
@@ -167,7 +173,12 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 	// TODO: 根据expression字段进行规则检测,以最小的规则数量进行匹配
 
 	// 评估规则
-	for _, rule := range fg.Rules {
+	exprNames := expressionNames(fg)
+	for i, rule := range fg.Rules {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		names := exprNames[i]
 		// 提前处理path
 		rule.Value.Request.Path = finger.SetVariableMap(strings.TrimSpace(rule.Value.Request.Path), varMap)
 		urlStr := common.ParseTarget(target, rule.Value.Request.Path)
@@ -178,25 +189,31 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 			if rule.Value.Request.Path != "" && rule.Value.Request.Path != "/" {
 				//logger.Debug("主动发包的规则键为：", rule.Key)
 				logger.Debug("发现主动指纹识别规则路径为：", rule.Value.Request.Path, " 已跳过")
-				customLib.WriteRuleFunctionsROptions(rule.Key, false)
+				writeRuleResults(customLib, rule.Key, names, false)
 				continue
 			}
 			// 判断请求方法不是GET
 			if rule.Value.Request.Method != "GET" {
 				logger.Debug("发现非默认请求方法：", rule.Value.Request.Method, " 已跳过")
-				customLib.WriteRuleFunctionsROptions(rule.Key, false)
+				writeRuleResults(customLib, rule.Key, names, false)
 				continue
 			}
 			// 判断请求头
 			if len(rule.Value.Request.Headers) != 0 {
 				logger.Debug("发现非默认请求头", rule.Value.Request.Headers, " 已跳过")
-				customLib.WriteRuleFunctionsROptions(rule.Key, false)
+				writeRuleResults(customLib, rule.Key, names, false)
+				continue
+			}
+			// 判断自定义cookie
+			if len(rule.Value.Request.Cookies) != 0 {
+				logger.Debug("发现自定义cookie", rule.Value.Request.Cookies, " 已跳过")
+				writeRuleResults(customLib, rule.Key, names, false)
 				continue
 			}
 
 		}
 		// 检查是否可以使用缓存
-		isCache, cache := ShouldUseCache(rule, urlStr)
+		isCache, cache := ShouldUseCache(rule, urlStr, proxy)
 		logger.Debugf("%s 规则 %s 是否使用缓存：%t", target, rule.Key, isCache)
 
 		if isCache && cache.Request != nil && cache.Response != nil {
@@ -204,19 +221,19 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 			varMap["response"] = cache.Response
 		} else {
 			// 发送新请求
-			newVarMap, err := finger.SendRequest(target, rule.Value.Request, rule.Value, varMap, proxy, timeout)
+			newVarMap, err := finger.SendRequest(ctx, target, rule.Value.Request, rule.Value, varMap, proxy, timeout)
 			if err != nil {
 				logger.Debugf("规则 %s 请求失败: %v", rule.Key, err)
-				customLib.WriteRuleFunctionsROptions(rule.Key, false)
+				writeRuleResults(customLib, rule.Key, names, false)
 				continue
 			}
 
 			// 更新变量映射
 			if len(newVarMap) > 0 {
 				varMap = newVarMap
-				// 只有头部和body为空的请求才缓存
-				if len(rule.Value.Request.Headers) == 0 {
-					UpdateTargetCache(varMap, urlStr, rule.Value.Request.FollowRedirects)
+				// 只有头部、cookie和body为空的请求才缓存
+				if len(rule.Value.Request.Headers) == 0 && len(rule.Value.Request.Cookies) == 0 {
+					UpdateTargetCache(varMap, urlStr, rule.Value.Request.FollowRedirects, proxy)
 				}
 			}
 		}
@@ -246,16 +263,15 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		logger.Debug("开始CEL表达式匹配")
 
 		// 执行规则评估
-		result, err := customLib.Evaluate(rule.Value.Expression, varMap)
+		ruleBool, ruleDesc, err := evaluateRule(rule, names, customLib, varMap)
 		if err != nil {
-			logger.Debugf("规则 %s CEL解析错误：%s", rule.Key, err.Error())
+			logger.Debugf("规则 %s 解析错误：%s", rule.Key, err.Error())
 			customLib.WriteRuleFunctionsROptions(rule.Key, false)
 		} else {
-			ruleBool := result.Value().(bool)
-			logger.Debugf("规则 %s 评估结果: %v", rule.Value.Expression, ruleBool)
-			// TODO：显示命中规则
+			logger.Debugf("规则 %s 评估结果: %v", ruleDesc, ruleBool)
 			if ruleBool {
-				logger.Infof("规则 %s 中的表达式 %s 命中", color.BlueString(rule.Key), color.BlueString(rule.Value.Expression))
+				matchedRules = append(matchedRules, rule.Key+": "+ruleDesc)
+				logger.Infof("规则 %s 中的表达式 %s 命中", color.BlueString(rule.Key), color.BlueString(ruleDesc))
 			}
 			customLib.WriteRuleFunctionsROptions(rule.Key, ruleBool)
 		}
@@ -263,6 +279,14 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		// 处理输出规则
 		if len(rule.Value.Output) > 0 {
 			finger.IsFuzzSet(rule.Value.Output, varMap, customLib)
+			collectOutputVariables(rule.Value.Output, varMap, extracted)
+		}
+
+		// 处理声明式提取器
+		if len(rule.Value.Extractors) > 0 {
+			for k, v := range finger.RunExtractors(rule.Key, rule.Value.Extractors, varMap, customLib) {
+				extracted[k] = v
+			}
 		}
 	}
 
@@ -282,6 +306,10 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		if resp, ok := varMap["response"].(*proto.Response); ok {
 			resultData.Response = resp
 		}
+		if len(extracted) > 0 {
+			resultData.Extracted = extracted
+		}
+		resultData.MatchedRules = matchedRules
 	}
 
 	logger.Debugf("最终规则 %s 评估结果: %v", fg.Expression, resultData.Result)
@@ -289,8 +317,191 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 	return resultData, nil
 }
 
+// evaluateRule 评估单条规则，expression 与 matchers 同时存在时需同时满足，返回结果与用于展示的规则描述
+// expressions 中每个表达式的结果按 names 注册为 r0_e0、r0_e1... 函数，可在本规则的 expression 与最终表达式中引用；
+// 规则未配置 expression 时，expressions 中任一表达式命中即视为表达式部分命中
+func evaluateRule(rule finger.RuleMap, names []string, customLib *cel2.CustomLib, varMap map[string]any) (bool, string, error) {
+	descs := make([]string, 0, len(rule.Value.Matchers)+1)
+	if len(rule.Value.Expressions) > 0 {
+		hits := make([]string, 0, len(rule.Value.Expressions))
+		for i, expr := range rule.Value.Expressions {
+			result, err := customLib.Evaluate(expr, varMap)
+			if err != nil {
+				logger.Debugf("规则 %s 中的表达式 %s 解析错误：%v", rule.Key, expr, err)
+			}
+			ok := err == nil && result.Value().(bool)
+			customLib.WriteRuleFunctionsROptions(names[i], ok)
+			if ok {
+				hits = append(hits, expr)
+			}
+		}
+		if rule.Value.Expression == "" {
+			if len(hits) == 0 {
+				return false, strings.Join(rule.Value.Expressions, " || "), nil
+			}
+			descs = append(descs, strings.Join(hits, " || "))
+		}
+	}
+	if rule.Value.Expression != "" || (len(rule.Value.Matchers) == 0 && len(rule.Value.Expressions) == 0) {
+		result, err := customLib.Evaluate(rule.Value.Expression, varMap)
+		if err != nil {
+			return false, rule.Value.Expression, err
+		}
+		if !result.Value().(bool) {
+			return false, rule.Value.Expression, nil
+		}
+		descs = append(descs, rule.Value.Expression)
+	}
+	if len(rule.Value.Matchers) > 0 {
+		for _, m := range rule.Value.Matchers {
+			descs = append(descs, m.String())
+		}
+		resp, _ := varMap["response"].(*proto.Response)
+		ok, err := finger.MatchRule(rule.Value.Matchers, rule.Value.MatchersCondition, resp)
+		if err != nil || !ok {
+			return false, strings.Join(descs, " && "), err
+		}
+	}
+	return true, strings.Join(descs, " && "), nil
+}
+
+// expressionNames 为各规则 expressions 中的表达式分配 <规则键>_e0、<规则键>_e1... 名称，重名已在加载指纹时校验
+func expressionNames(fg *finger.Finger) [][]string {
+	names := make([][]string, len(fg.Rules))
+	for i, rule := range fg.Rules {
+		names[i] = make([]string, len(rule.Value.Expressions))
+		for j := range rule.Value.Expressions {
+			names[i][j] = finger.ExpressionName(rule.Key, j)
+		}
+	}
+	return names
+}
+
+// writeRuleResults 注册规则及其 expressions 表达式的评估结果
+func writeRuleResults(customLib *cel2.CustomLib, ruleKey string, names []string, result bool) {
+	customLib.WriteRuleFunctionsROptions(ruleKey, result)
+	for _, name := range names {
+		customLib.WriteRuleFunctionsROptions(name, result)
+	}
+}
+
+// collectOutputVariables 收集规则output中定义的变量值，用于结果输出
+func collectOutputVariables(args yaml.MapSlice, varMap map[string]any, extracted map[string]string) {
+	for _, arg := range args {
+		key, ok := arg.Key.(string)
+		if !ok {
+			continue
+		}
+		switch value := varMap[key].(type) {
+		case nil:
+			continue
+		case map[string]string:
+			// submatch等函数返回分组映射，展开为 key.分组名
+			for k, v := range value {
+				extracted[key+"."+k] = v
+			}
+		default:
+			extracted[key] = fmt.Sprintf("%v", value)
+		}
+	}
+}
+
+// FingerListItem 指纹列表输出项
+type FingerListItem struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Severity string `json:"severity"`
+	Tags     string `json:"tags"`
+}
+
+// ListFingers 加载指纹并打印id、名称、等级与标签（指定--list-fingers参数时调用）
+func ListFingers(options types.YamlFingerType, jsonOutput bool) error {
+	if err := LoadFingerprints(options); err != nil {
+		return fmt.Errorf("加载指纹规则出错: %v", err)
+	}
+	fingers := GetAllFingerSnapshot()
+
+	items := make([]FingerListItem, 0, len(fingers))
+	for _, fg := range fingers {
+		items = append(items, FingerListItem{
+			Id:       fg.Id,
+			Name:     fg.Info.Name,
+			Severity: fg.Info.Severity,
+			Tags:     fg.Info.Tags,
+		})
+	}
+
+	// JSON格式输出
+	if jsonOutput {
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON序列化失败: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	// 表格格式输出
+	fmt.Println("+", strings.Repeat("-", 5), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 10), "+", strings.Repeat("-", 30))
+	fmt.Printf("| %-5s | %-40s | %-40s | %-8s | %-30s \n", "Index", "ID", "Name", "Severity", "Tags")
+	fmt.Println("+", strings.Repeat("-", 5), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 10), "+", strings.Repeat("-", 30))
+	for i, item := range items {
+		fmt.Printf("| %-5d | %-40s | %-40s | %-8s | %-30s \n", i+1, item.Id, item.Name, item.Severity, item.Tags)
+	}
+	fmt.Println("+", strings.Repeat("-", 5), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 42), "+", strings.Repeat("-", 10), "+", strings.Repeat("-", 30))
+	logger.Infof("共加载指纹 %d 个", len(items))
+	return nil
+}
+
+// PresetRuleItem 预配置指纹规则的JSON输出项
+type PresetRuleItem struct {
+	Key        string `json:"key"`
+	Type       string `json:"type,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Expression string `json:"expression"`
+}
+
+// PresetFingerItem 预配置指纹的JSON输出项
+type PresetFingerItem struct {
+	Id         string           `json:"id"`
+	Name       string           `json:"name"`
+	Severity   string           `json:"severity,omitempty"`
+	Tags       string           `json:"tags,omitempty"`
+	Transport  string           `json:"transport,omitempty"`
+	Expression string           `json:"expression"`
+	Rules      []PresetRuleItem `json:"rules"`
+}
+
+// presetFingerItems 将指纹转换为JSON输出项
+func presetFingerItems(fingers []*finger.Finger) []PresetFingerItem {
+	items := make([]PresetFingerItem, 0, len(fingers))
+	for _, fg := range fingers {
+		rules := make([]PresetRuleItem, 0, len(fg.Rules))
+		for _, rule := range fg.Rules {
+			rules = append(rules, PresetRuleItem{
+				Key:        rule.Key,
+				Type:       rule.Value.Request.Type,
+				Method:     rule.Value.Request.Method,
+				Path:       rule.Value.Request.Path,
+				Expression: rule.Value.Expression,
+			})
+		}
+		items = append(items, PresetFingerItem{
+			Id:         fg.Id,
+			Name:       fg.Info.Name,
+			Severity:   fg.Info.Severity,
+			Tags:       fg.Info.Tags,
+			Transport:  fg.Transport,
+			Expression: fg.Expression,
+			Rules:      rules,
+		})
+	}
+	return items
+}
+
 // 打印预配置（指定--print参数时调用）
-func PrintPresetFinger() error {
+func PrintPresetFinger(jsonOutput bool) error {
 	// 获取预配置指纹列表
 	presetFinger, err := utils.GetFingerYaml()
 	if err != nil {
@@ -298,6 +509,16 @@ func PrintPresetFinger() error {
 		return err
 	}
 
+	// JSON格式输出完整指纹信息，便于工具处理与版本对比
+	if jsonOutput {
+		data, err := json.MarshalIndent(presetFingerItems(presetFinger), "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON序列化失败: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
 	// 打印表头
 	fmt.Println("+", strings.Repeat("-", 5), "+", strings.Repeat("-", 42))
 	fmt.Printf("| %-5s | %-40s \n", "Index", "Name")
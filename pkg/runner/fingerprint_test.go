@@ -0,0 +1,350 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils"
+
+	"gopkg.in/yaml.v2"
+)
+
+// cookieFinger 仅携带自定义cookie的首页GET规则
+const cookieFinger = `
+id: cookie-only
+info:
+  name: cookie-only
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+      cookies:
+        rememberMe: "1"
+    expression: response.status == 200
+expression: r0()
+`
+
+func parseFinger(t *testing.T, content string) *finger.Finger {
+	t.Helper()
+	fg := &finger.Finger{}
+	if err := yaml.Unmarshal([]byte(content), fg); err != nil {
+		t.Fatalf("解析指纹失败: %v", err)
+	}
+	return fg
+}
+
+func TestPassiveModeSkipsCookieRules(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	fg := parseFinger(t, cookieFinger)
+	result, err := evaluateFingerprintWithCache(context.Background(), fg, srv.URL, &BaseInfo{StatusCode: 200}, "", 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Result {
+		t.Error("被动模式下跳过的规则不应匹配")
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("被动模式下携带自定义cookie的规则不应发送请求，实际发送 %d 次", n)
+	}
+}
+
+func TestCheckHeaderOnlyFingerRejectsCookies(t *testing.T) {
+	if checkHeaderOnlyFinger(parseFinger(t, cookieFinger)) {
+		t.Error("携带自定义cookie的指纹不应视为仅依赖首页响应头")
+	}
+}
+
+func TestLoadFingerprintsEmpty(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+
+	loaded := t.TempDir()
+	writeFinger(t, loaded, "loaded-finger")
+	if err := LoadFingerprints(types.YamlFingerType{FingerPath: loaded}); err != nil {
+		t.Fatalf("加载指纹失败: %v", err)
+	}
+
+	// 目录下没有指纹文件时报错，且保留已加载的指纹
+	if err := LoadFingerprints(types.YamlFingerType{FingerPath: t.TempDir()}); err == nil {
+		t.Error("未加载到任何指纹时应返回错误")
+	}
+	if got := GetAllFingerSnapshot(); len(got) != 1 || got[0].Id != "loaded-finger" {
+		t.Errorf("加载失败后指纹 = %v，期望保留 loaded-finger", got)
+	}
+}
+
+func TestEvaluateFingerprintMatchedRules(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("welcome"))
+	}))
+	defer srv.Close()
+
+	fg := parseFinger(t, `
+id: matched-rules
+info:
+  name: matched-rules
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.body.bcontains(b"welcome")
+  r1:
+    request:
+      method: GET
+      path: /admin
+    expression: response.status == 200
+expression: r0() || r1()
+`)
+	result, err := evaluateFingerprintWithCache(context.Background(), fg, srv.URL, &BaseInfo{StatusCode: 200}, "", 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Result {
+		t.Fatal("指纹应命中")
+	}
+	// 仅记录命中的规则
+	want := []string{`r0: response.body.bcontains(b"welcome")`}
+	if len(result.MatchedRules) != 1 || result.MatchedRules[0] != want[0] {
+		t.Errorf("命中规则 = %q，期望 %q", result.MatchedRules, want)
+	}
+	if copied := copyFingerMatches([]*FingerMatch{result}); len(copied[0].MatchedRules) != 1 {
+		t.Error("缓存的匹配结果应保留命中规则")
+	}
+}
+
+func TestEvaluateFingerprintExpressions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<title>Grafana</title>"))
+	}))
+	defer srv.Close()
+
+	const content = `
+id: named-expressions
+info:
+  name: named-expressions
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expressions:
+      - response.body.bcontains(b"Kibana")
+      - response.body.bcontains(b"Grafana")
+  r1:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 404
+expression: %s
+`
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		// 规则键沿用常见的 r0、r1，表达式结果以 r0_e0、r0_e1 引用，互不冲突
+		{"r0_e0() || r0_e1()", true},
+		{"r0_e0() || r1()", false},
+		{"r0_e1() && !r1()", true},
+		{"r0()", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			fg := parseFinger(t, fmt.Sprintf(content, tt.expression))
+			result, err := evaluateFingerprintWithCache(context.Background(), fg, srv.URL, &BaseInfo{StatusCode: 200}, "", 5, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Result != tt.want {
+				t.Errorf("最终表达式 %s 的结果 = %v，期望 %v", tt.expression, result.Result, tt.want)
+			}
+		})
+	}
+}
+
+// captureStdout 执行 fn 并返回其写入标准输出的内容
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fnErr := fn()
+	os.Stdout = previous
+	_ = w.Close()
+	out := <-done
+	_ = r.Close()
+	if fnErr != nil {
+		t.Fatalf("执行失败: %v", fnErr)
+	}
+	return string(out)
+}
+
+func TestListFingers(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+
+	dir := t.TempDir()
+	for _, fg := range []struct{ id, name, severity, tags string }{
+		{"list-nginx", "Nginx", "info", "web,server"},
+		{"list-weblogic", "WebLogic Console", "high", "java,middleware"},
+	} {
+		content := fmt.Sprintf(`
+id: %s
+info:
+  name: %s
+  severity: %s
+  tags: %s
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200
+expression: r0()
+`, fg.id, fg.name, fg.severity, fg.tags)
+		if err := os.WriteFile(filepath.Join(dir, fg.id+".yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	options := types.YamlFingerType{FingerPath: dir}
+
+	var items []FingerListItem
+	out := captureStdout(t, func() error { return ListFingers(options, true) })
+	if err := json.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("解析JSON输出失败: %v\n%s", err, out)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+	want := []FingerListItem{
+		{Id: "list-nginx", Name: "Nginx", Severity: "info", Tags: "web,server"},
+		{Id: "list-weblogic", Name: "WebLogic Console", Severity: "high", Tags: "java,middleware"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("指纹列表 = %+v，期望 %+v", items, want)
+	}
+
+	// 表格输出同样列出每个指纹
+	table := captureStdout(t, func() error { return ListFingers(options, false) })
+	for _, item := range want {
+		for _, field := range []string{item.Id, item.Name, item.Severity, item.Tags} {
+			if !strings.Contains(table, field) {
+				t.Errorf("表格输出缺少 %q：\n%s", field, table)
+			}
+		}
+	}
+}
+
+func TestPrintPresetFingerJSON(t *testing.T) {
+	preset, err := utils.GetFingerYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() error { return PrintPresetFinger(true) })
+	// 内置指纹库为空时同样输出合法的JSON数组而非 null
+	var items []PresetFingerItem
+	if err := json.Unmarshal([]byte(out), &items); err != nil || items == nil {
+		t.Fatalf("解析JSON输出失败: %v\n%s", err, out)
+	}
+	if len(items) != len(preset) {
+		t.Fatalf("输出指纹 %d 个，期望 %d 个", len(items), len(preset))
+	}
+	for i, fg := range preset {
+		item := items[i]
+		if item.Id != fg.Id || item.Name != fg.Info.Name || item.Expression != fg.Expression {
+			t.Errorf("第 %d 个指纹 = %+v，期望 id=%s name=%s expression=%s", i, item, fg.Id, fg.Info.Name, fg.Expression)
+		}
+		if len(item.Rules) != len(fg.Rules) {
+			t.Errorf("指纹 %s 输出规则 %d 条，期望 %d 条", fg.Id, len(item.Rules), len(fg.Rules))
+			continue
+		}
+		for j, rule := range fg.Rules {
+			if item.Rules[j].Key != rule.Key || item.Rules[j].Expression != rule.Value.Expression {
+				t.Errorf("指纹 %s 第 %d 条规则 = %+v，期望 key=%s", fg.Id, j, item.Rules[j], rule.Key)
+			}
+		}
+	}
+}
+
+func TestPresetFingerItems(t *testing.T) {
+	fg := parseFinger(t, `
+id: preset-tomcat
+info:
+  name: Apache Tomcat
+  severity: info
+  tags: java,middleware
+transport: http
+rules:
+  r0:
+    request:
+      method: GET
+      path: /manager/html
+    expression: response.status == 401
+  r1:
+    request:
+      type: tcp
+      host: "{{Hostname}}"
+    expression: response.raw.bcontains(b"AJP")
+expression: r0() || r1()
+`)
+	data, err := json.Marshal(presetFingerItems([]*finger.Finger{fg}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []PresetFingerItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		t.Fatalf("解析JSON输出失败: %v", err)
+	}
+	want := []PresetFingerItem{{
+		Id:         "preset-tomcat",
+		Name:       "Apache Tomcat",
+		Severity:   "info",
+		Tags:       "java,middleware",
+		Transport:  "http",
+		Expression: "r0() || r1()",
+		Rules: []PresetRuleItem{
+			{Key: "r0", Method: "GET", Path: "/manager/html", Expression: "response.status == 401"},
+			{Key: "r1", Type: "tcp", Expression: `response.raw.bcontains(b"AJP")`},
+		},
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("JSON输出项 = %+v，期望 %+v", items, want)
+	}
+}
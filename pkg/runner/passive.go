@@ -0,0 +1,363 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/network"
+	"xfirefly/pkg/output"
+	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils/common"
+	"xfirefly/pkg/utils/proto"
+
+	"github.com/donnie4w/go-logger/logger"
+)
+
+// capturedEntry 离线读取的一组请求/响应
+type capturedEntry struct {
+	URL      *url.URL
+	Method   string
+	Response *http.Response
+	Body     []byte
+}
+
+// harFile HAR文件中指纹识别需要的字段
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string      `json:"method"`
+				URL     string      `json:"url"`
+				Headers []harHeader `json:"headers"`
+			} `json:"request"`
+			Response struct {
+				Status      int         `json:"status"`
+				StatusText  string      `json:"statusText"`
+				HTTPVersion string      `json:"httpVersion"`
+				Headers     []harHeader `json:"headers"`
+				Content     struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harHeader HAR文件中的请求头/响应头
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// loadCapturedEntries 读取目录下的HAR文件（.har）与原始请求响应转储文件，按文件名顺序返回
+func loadCapturedEntries(dir string) ([]*capturedEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取被动识别目录失败: %v", err)
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	entries := make([]*capturedEntry, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warnf("读取文件 %s 失败: %v", path, err)
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(name), ".har") {
+			harEntries, err := parseHAR(data)
+			if err != nil {
+				logger.Warnf("解析HAR文件 %s 失败: %v", path, err)
+				continue
+			}
+			entries = append(entries, harEntries...)
+			continue
+		}
+		entry, err := parseRawDump(data)
+		if err != nil {
+			logger.Warnf("解析请求响应文件 %s 失败: %v", path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseHAR 解析HAR文件中的所有请求/响应
+func parseHAR(data []byte) ([]*capturedEntry, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, err
+	}
+	entries := make([]*capturedEntry, 0, len(har.Log.Entries))
+	for _, e := range har.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || u.Host == "" {
+			logger.Debugf("跳过无效的HAR请求地址: %s", e.Request.URL)
+			continue
+		}
+		body := []byte(e.Response.Content.Text)
+		if e.Response.Content.Encoding == "base64" {
+			if decoded, err := base64.StdEncoding.DecodeString(e.Response.Content.Text); err == nil {
+				body = decoded
+			}
+		}
+		resp := &http.Response{
+			Status:     strconv.Itoa(e.Response.Status) + " " + e.Response.StatusText,
+			StatusCode: e.Response.Status,
+			Proto:      e.Response.HTTPVersion,
+			Header:     make(http.Header, len(e.Response.Headers)),
+		}
+		resp.ProtoMajor, resp.ProtoMinor, _ = http.ParseHTTPVersion(e.Response.HTTPVersion)
+		for _, h := range e.Response.Headers {
+			// HTTP/2抓包中的伪首部不属于响应头
+			if strings.HasPrefix(h.Name, ":") {
+				continue
+			}
+			resp.Header.Add(h.Name, h.Value)
+		}
+		reqHeader := make(http.Header, len(e.Request.Headers))
+		for _, h := range e.Request.Headers {
+			if strings.HasPrefix(h.Name, ":") {
+				continue
+			}
+			reqHeader.Add(h.Name, h.Value)
+		}
+		method := strings.ToUpper(e.Request.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		resp.Request = &http.Request{Method: method, URL: u, Host: u.Host, Header: reqHeader}
+		entries = append(entries, &capturedEntry{URL: u, Method: method, Response: resp, Body: body})
+	}
+	return entries, nil
+}
+
+// parseRawDump 解析原始转储文件，文件内容为原始HTTP请求后紧跟原始HTTP响应
+func parseRawDump(data []byte) (*capturedEntry, error) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, fmt.Errorf("缺少原始请求: %v", err)
+	}
+	// 请求体不参与识别，读取后丢弃以定位响应起始位置
+	_, _ = io.Copy(io.Discard, req.Body)
+
+	u := req.URL
+	if u.Host == "" {
+		if req.Host == "" {
+			return nil, fmt.Errorf("请求缺少Host头")
+		}
+		u = &url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	req.URL = u
+
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("缺少原始响应: %v", err)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
+	// 转储文件可能被截断，保留已读取的部分
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("读取响应体失败: %v", err)
+	}
+	_ = resp.Body.Close()
+	return &capturedEntry{URL: u, Method: req.Method, Response: resp, Body: body}, nil
+}
+
+// entryURL 返回请求地址，不含查询参数与片段
+func (e *capturedEntry) entryURL() string {
+	return e.URL.Scheme + "://" + e.URL.Host + e.URL.EscapedPath()
+}
+
+// groupCapturedEntries 按 scheme://host 分组，保持首次出现的顺序
+func groupCapturedEntries(entries []*capturedEntry) ([]string, map[string][]*capturedEntry) {
+	order := make([]string, 0)
+	groups := make(map[string][]*capturedEntry)
+	for _, e := range entries {
+		key := e.URL.Scheme + "://" + e.URL.Host
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+	return order, groups
+}
+
+// processCapturedTarget 基于已捕获的响应对单个站点执行指纹识别，不发起任何网络请求
+func processCapturedTarget(target string, entries []*capturedEntry, config *ScanConfig) *TargetResult {
+	targetResult := &TargetResult{
+		URL:     target,
+		Server:  types.EmptyServerInfo(),
+		Matches: make([]*FingerMatch, 0, 10),
+	}
+
+	// 首页响应作为基础信息，缺失时使用第一条记录
+	home := entries[0]
+	for _, e := range entries {
+		if e.URL.Path == "" || e.URL.Path == "/" {
+			home = e
+			break
+		}
+	}
+
+	// 预先写入缓存，规则请求的路径命中缓存时直接使用捕获的响应
+	cachedURLs := make([]string, 0, len(entries))
+	var homeRequest *proto.Request
+	var homeResponse *proto.Response
+	for _, e := range entries {
+		e.Response.Body = io.NopCloser(bytes.NewReader(e.Body))
+		protoResp := finger.BuildProtoResponse(e.Response, common.Str2UTF8(string(e.Body)), 0, config.Proxy)
+		protoReq := finger.BuildProtoRequest(e.Response, e.Method, "", e.URL.RequestURI())
+		variableMap := map[string]any{"request": protoReq, "response": protoResp}
+		entryURL := e.entryURL()
+		UpdateTargetCache(variableMap, entryURL, false, config.Proxy)
+		UpdateTargetCache(variableMap, entryURL, true, config.Proxy)
+		cachedURLs = append(cachedURLs, entryURL)
+		if e == home {
+			homeRequest, homeResponse = protoReq, protoResp
+		}
+	}
+	defer func() {
+		for _, u := range cachedURLs {
+			ClearTargetURLCache(u, config.Proxy)
+		}
+	}()
+
+	home.Response.Body = io.NopCloser(bytes.NewReader(home.Body))
+	targetResult.StatusCode = int32(home.Response.StatusCode)
+	targetResult.Title = finger.GetTitle(home.entryURL(), home.Response)
+	targetResult.Server = finger.GetServerInfoFromResponse(home.Response)
+	targetResult.Security = finger.GetSecurityHeaders(home.Response.Header)
+	if config.Wappalyzer != nil {
+		if wappData, err := config.Wappalyzer.Analyze(home.Response.Header, home.Body); err == nil {
+			targetResult.Wappalyzer = wappData
+		}
+	}
+	targetResult.LastRequest = homeRequest
+	targetResult.LastResponse = homeResponse
+	targetResult.IconHashes = homeResponse.GetIconHashes()
+
+	if GetFingerCount() == 0 {
+		return targetResult
+	}
+
+	baseInfo := &BaseInfo{
+		Title:      targetResult.Title,
+		Server:     targetResult.Server,
+		StatusCode: targetResult.StatusCode,
+	}
+	targetResult.Matches = runFingerDetection(target, baseInfo, config)
+	return targetResult
+}
+
+// RunPassive 被动识别模式，仅读取目录中已捕获的请求响应执行指纹识别，全程不发起网络请求
+func (r *Runner) RunPassive(options *types.CmdOptionsType) error {
+	if !r.isRunning.CompareAndSwap(false, true) {
+		return fmt.Errorf("扫描器已在运行中")
+	}
+	defer r.isRunning.Store(false)
+
+	// 禁止一切网络请求，未命中捕获数据的规则直接判定为不匹配
+	network.SetOffline(true)
+	defer network.SetOffline(false)
+	// 结束后恢复favicon设置，避免影响同一Runner后续的主动扫描
+	faviconDisabled := finger.IsFaviconDisabled()
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(faviconDisabled)
+
+	entries, err := loadCapturedEntries(options.Passive)
+	if err != nil {
+		return err
+	}
+	targets, groups := groupCapturedEntries(entries)
+	if len(targets) == 0 {
+		return fmt.Errorf("目录 %s 中未找到有效的请求响应数据", options.Passive)
+	}
+	logger.Info(fmt.Sprintf("读取请求响应 %d 条，共 %d 个站点", len(entries), len(targets)))
+
+	output.SetRunID(r.RunID)
+	output.SetOutputDedup(options.OutputDedup)
+
+	if r.Config.OutputFile != "" {
+		if err := output.InitOutput(r.Config.OutputFile, r.Config.OutputFormat); err != nil {
+			return fmt.Errorf("初始化输出文件失败: %v", err)
+		}
+		logger.Info(fmt.Sprintf("日志输出文件：%s", output.OutputPath(r.Config.OutputFile)))
+		defer func() {
+			_ = output.Close()
+		}()
+	}
+
+	if err := output.SetOutputTemplate(options.OutputTemplate); err != nil {
+		return fmt.Errorf("输出模板解析失败: %v", err)
+	}
+
+	if r.Config.SockOutputFile != "" {
+		if err := output.InitSockOutput(r.Config.SockOutputFile); err != nil {
+			return fmt.Errorf("初始化socket输出文件失败: %v", err)
+		}
+		logger.Info(fmt.Sprintf("Socket输出文件：%s", r.Config.SockOutputFile))
+	}
+
+	if err := LoadFingerprints(options.FingerOptions); err != nil {
+		return fmt.Errorf("加载指纹规则出错: %v", err)
+	}
+	logger.Info(fmt.Sprintf("加载指纹数量：%v个", GetFingerCount()))
+
+	// 捕获数据中可能包含非首页路径，按主动模式评估全部规则，由缓存决定是否命中
+	active := r.Config.Active
+	r.Config.Active = true
+	defer func() { r.Config.Active = active }()
+	if !IsRulePoolInitialized() {
+		if err := InitGlobalRulePool(r.Config.FingerWorkerCount, true); err != nil {
+			return err
+		}
+	}
+	defer ReleaseRulePool()
+
+	printResult := func(msg string) {
+		fmt.Println(msg)
+	}
+	for _, target := range targets {
+		targetResult := processCapturedTarget(target, groups[target], r.Config)
+		handleMatchResults(targetResult, options, printResult, r.Config.OutputFormat)
+		if !r.Config.KeepRaw {
+			releaseRawData(targetResult)
+		}
+		r.mutex.Lock()
+		r.Results[target] = targetResult
+		r.mutex.Unlock()
+	}
+
+	ClearAllCache()
+
+	r.mutex.RLock()
+	printSummary(targets, r.Results)
+	r.mutex.RUnlock()
+
+	return nil
+}
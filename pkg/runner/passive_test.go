@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/types"
+)
+
+func TestRunPassive(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+
+	fingerDir := t.TempDir()
+	fingerYaml := `
+id: passive-marker
+info:
+  name: passive-marker
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200 && response.body.bcontains(b"passive-marker")
+expression: r0()
+`
+	if err := os.WriteFile(filepath.Join(fingerDir, "passive-marker.yaml"), []byte(fingerYaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureDir := t.TempDir()
+	body := "<html><title>Passive</title><body>passive-marker</body></html>"
+	dump := "GET / HTTP/1.1\r\nHost: passive.example.com\r\n\r\n" +
+		"HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nContent-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+	if err := os.WriteFile(filepath.Join(captureDir, "home.txt"), []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := &types.CmdOptionsType{
+		Passive:       captureDir,
+		FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:       5,
+	}
+	r, err := NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	if err := r.RunPassive(options); err != nil {
+		t.Fatalf("被动识别失败: %v", err)
+	}
+
+	result := r.Results["http://passive.example.com"]
+	if result == nil {
+		t.Fatalf("未得到目标结果，实际结果 %v", r.Results)
+	}
+	if result.Title != "Passive" {
+		t.Errorf("标题 = %q，期望 Passive", result.Title)
+	}
+	matched := false
+	for _, m := range result.Matches {
+		if m.Result && m.Finger.Id == "passive-marker" {
+			matched = true
+		}
+	}
+	if !matched {
+		t.Error("捕获的响应应命中 passive-marker 指纹")
+	}
+
+	// 被动模式结束后恢复Runner配置与favicon设置
+	if r.Config.Active {
+		t.Error("被动模式结束后应恢复 Active 配置")
+	}
+	if finger.IsFaviconDisabled() {
+		t.Error("被动模式结束后应恢复favicon抓取")
+	}
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"xfirefly/pkg/types"
+
+	"github.com/donnie4w/go-logger/logger"
+)
+
+// ReloadFingerprints 重新加载指纹规则并原子替换 AllFinger，加载失败或结果为空时保留原有指纹
+func ReloadFingerprints(options types.YamlFingerType) error {
+	// 读取指纹文件期间不持有锁，扫描协程可继续使用旧指纹
+	fingers, err := loadFingerprintSources(options)
+	if err != nil {
+		return err
+	}
+	if len(fingers) == 0 {
+		return fmt.Errorf("重新加载后未获取到任何指纹规则，保留原有指纹")
+	}
+
+	allFingerMutex.Lock()
+	AllFinger = fingers
+	allFingerMutex.Unlock()
+
+	// 指纹对象已更换，清理基于指纹对象的判断缓存
+	headerOnlyCache.Range(func(key, _ any) bool {
+		headerOnlyCache.Delete(key)
+		return true
+	})
+	return nil
+}
+
+// WatchFingerprintReload 监听 SIGHUP 信号，收到后重新加载指纹规则，返回停止监听的函数
+func WatchFingerprintReload(options types.YamlFingerType) func() {
+	signalChan := make(chan os.Signal, 1)
+	stopChan := make(chan struct{})
+	signal.Notify(signalChan, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signalChan:
+				logger.Info("收到SIGHUP信号，正在重新加载指纹规则")
+				if err := ReloadFingerprints(options); err != nil {
+					logger.Errorf("重新加载指纹规则失败: %v", err)
+					continue
+				}
+				logger.Infof("指纹规则重新加载完成，当前指纹数量：%d个", GetFingerCount())
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signalChan)
+		close(stopChan)
+	}
+}
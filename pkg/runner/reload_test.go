@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"xfirefly/pkg/types"
+)
+
+// writeFinger 在目录中写入一个仅匹配首页状态码的指纹文件
+func writeFinger(t *testing.T, dir, id string) {
+	t.Helper()
+	content := fmt.Sprintf(`
+id: %s
+info:
+  name: %s
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200
+expression: r0()
+`, id, id)
+	if err := os.WriteFile(filepath.Join(dir, id+".yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadFingerprints(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+
+	dir := t.TempDir()
+	writeFinger(t, dir, "finger-old")
+	options := types.YamlFingerType{FingerPath: dir}
+	if err := LoadFingerprints(options); err != nil {
+		t.Fatalf("加载指纹失败: %v", err)
+	}
+
+	// 重新加载期间持续读取指纹，配合 -race 检查并发读写
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = GetFingerCount()
+				_ = GetAllFingerSnapshot()
+			}
+		}
+	}()
+
+	writeFinger(t, dir, "finger-new")
+	err := ReloadFingerprints(options)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("重新加载指纹失败: %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, fg := range GetAllFingerSnapshot() {
+		ids[fg.Id] = true
+	}
+	if len(ids) != 2 || !ids["finger-old"] || !ids["finger-new"] {
+		t.Errorf("重新加载后指纹 = %v，期望包含 finger-old 与 finger-new", ids)
+	}
+
+	// 目录中已无指纹时保留原有指纹
+	for _, id := range []string{"finger-old", "finger-new"} {
+		_ = os.Remove(filepath.Join(dir, id+".yaml"))
+	}
+	if err := ReloadFingerprints(options); err == nil {
+		t.Error("重新加载结果为空时应返回错误")
+	}
+	if got := GetFingerCount(); got != 2 {
+		t.Errorf("重新加载失败后指纹数量 = %d，期望保留 2 个", got)
+	}
+}
@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+	cel2 "xfirefly/pkg/cel"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/network"
+	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils/proto"
+)
+
+// ReplayResult 单个原始请求重放的结果，用于JSON输出
+type ReplayResult struct {
+	Target     string `json:"target"`
+	Request    string `json:"request"`
+	Response   string `json:"response"`
+	StatusCode int32  `json:"status_code"`
+	Latency    int64  `json:"latency"`
+	Expression string `json:"expression,omitempty"`
+	Result     any    `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReplayRequest 读取原始请求文件并发送到 target，打印请求、响应与CEL表达式评估结果（指定--replay-request参数时调用）
+// 代理、TLS与伪造来源IP等网络参数与扫描时保持一致
+func ReplayRequest(target string, options *types.CmdOptionsType) error {
+	data, err := os.ReadFile(options.ReplayRequest)
+	if err != nil {
+		return fmt.Errorf("读取原始请求文件失败: %v", err)
+	}
+	// rawhttp客户端仅支持 http 与 socks5 代理
+	if strings.HasPrefix(strings.ToLower(options.Proxy), "https://") {
+		return fmt.Errorf("原始请求重放仅支持 http 与 socks5 代理: %s", options.Proxy)
+	}
+	if err := configureNetwork(options); err != nil {
+		return err
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = int(network.DefaultTimeout.Seconds())
+	}
+	expression, jsonOutput := options.ReplayExpr, options.JSONOutput
+
+	// 规范化目标地址，原始请求按站点根地址发送
+	if checkedURL, err := network.CheckProtocol(target, options.Proxy); err == nil && checkedURL != "" {
+		target = checkedURL
+	}
+
+	// 注入目标主机相关变量，原始请求中可引用 {{host}}、{{hostname}} 等
+	variableMap := make(map[string]any)
+	finger.SetTargetVariables(target, variableMap)
+	rt := network.RawHttp{RawhttpClient: network.GetRawHTTP(timeout)}
+	request := fillDefaultHeaders(string(data), network.DefaultRequestHeaders())
+	if err := rt.RawHttpRequest(request, target, variableMap); err != nil {
+		return fmt.Errorf("发送原始请求失败: %v", err)
+	}
+
+	result := &ReplayResult{Target: target, Expression: expression}
+	if req, ok := variableMap["request"].(*proto.Request); ok && req != nil {
+		result.Request = string(req.GetRaw())
+	}
+	if resp, ok := variableMap["response"].(*proto.Response); ok && resp != nil {
+		result.Response = string(resp.GetRaw())
+		result.StatusCode = resp.GetStatus()
+		result.Latency = resp.GetLatency()
+	}
+
+	// 评估CEL表达式
+	if expression != "" {
+		val, err := cel2.NewCustomLib().Evaluate(expression, variableMap)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Result = val.Value()
+		}
+	}
+
+	// JSON格式输出
+	if jsonOutput {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON序列化失败: %v", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Println(strings.Repeat("=", 30), "请求", strings.Repeat("=", 30))
+	fmt.Println(result.Request)
+	fmt.Println(strings.Repeat("=", 30), "响应", strings.Repeat("=", 30))
+	fmt.Printf("状态码: %d  延迟: %dms\n", result.StatusCode, result.Latency)
+	fmt.Println(result.Response)
+	if expression != "" {
+		fmt.Println(strings.Repeat("=", 30), "表达式", strings.Repeat("=", 30))
+		fmt.Println(expression)
+		if result.Error != "" {
+			fmt.Printf("评估出错: %s\n", result.Error)
+		} else {
+			fmt.Printf("评估结果: %v\n", result.Result)
+		}
+	}
+	return nil
+}
+
+// fillDefaultHeaders 为原始请求补充缺失的默认请求头（含伪造来源IP），原始请求中已有的同名请求头保持不变
+func fillDefaultHeaders(raw string, defaults map[string]string) string {
+	head, rest := raw, ""
+	if i := strings.Index(raw, "\n\n"); i >= 0 {
+		head, rest = raw[:i], raw[i:]
+	}
+	if i := strings.Index(raw, "\r\n\r\n"); i >= 0 && i < len(head) {
+		head, rest = raw[:i], raw[i:]
+	}
+	newline := "\n"
+	if strings.Contains(head, "\r\n") {
+		newline = "\r\n"
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(head, "\n")[1:] {
+		if name, _, ok := strings.Cut(line, ":"); ok {
+			present[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		if !present[textproto.CanonicalMIMEHeaderKey(k)] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(head, "\r\n"))
+	for _, k := range keys {
+		b.WriteString(newline + k + ": " + defaults[k])
+	}
+	b.WriteString(rest)
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"xfirefly/pkg/network"
+	"xfirefly/pkg/types"
+)
+
+func TestFillDefaultHeaders(t *testing.T) {
+	defaults := map[string]string{"User-Agent": "default-ua", "Accept": "*/*", "X-Forwarded-For": "1.2.3.4"}
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{
+			"GET / HTTP/1.1\nHost: example.com\nuser-agent: custom\n\n",
+			"GET / HTTP/1.1\nHost: example.com\nuser-agent: custom\nAccept: */*\nX-Forwarded-For: 1.2.3.4\n\n",
+		},
+		{
+			"POST /a HTTP/1.1\r\nHost: example.com\r\nAccept: text/html\r\n\r\nname=a\n\nb",
+			"POST /a HTTP/1.1\r\nHost: example.com\r\nAccept: text/html\r\nUser-Agent: default-ua\r\nX-Forwarded-For: 1.2.3.4\r\n\r\nname=a\n\nb",
+		},
+		{
+			"GET / HTTP/1.1\nHost: example.com",
+			"GET / HTTP/1.1\nHost: example.com\nAccept: */*\nUser-Agent: default-ua\nX-Forwarded-For: 1.2.3.4",
+		},
+	}
+	for _, tt := range tests {
+		if got := fillDefaultHeaders(tt.raw, defaults); got != tt.want {
+			t.Errorf("fillDefaultHeaders(%q) = %q，期望 %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestReplayRequestUsesProxy(t *testing.T) {
+	var gotXFF atomic.Value
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotXFF.Store(r.Header.Get("X-Forwarded-For"))
+	}))
+	defer target.Close()
+
+	// 仅支持CONNECT方法的代理，记录隧道建立次数
+	var tunnels atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT", http.StatusMethodNotAllowed)
+			return
+		}
+		tunnels.Add(1)
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			_ = upstream.Close()
+			return
+		}
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		_ = conn.Close()
+	}))
+	defer proxy.Close()
+
+	file := filepath.Join(t.TempDir(), "req.txt")
+	if err := os.WriteFile(file, []byte("GET /replay HTTP/1.1\nHost: {{hostname}}\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	options := &types.CmdOptionsType{
+		ReplayRequest: file,
+		Proxy:         proxy.URL,
+		SpoofIP:       "ipv4",
+		Timeout:       5,
+		JSONOutput:    true,
+		MinTLS:        "1.0",
+	}
+	defer func() { _ = network.SetSpoofIP("off") }()
+	if err := ReplayRequest(target.URL, options); err != nil {
+		t.Fatalf("ReplayRequest 失败: %v", err)
+	}
+	if tunnels.Load() == 0 {
+		t.Error("原始请求应经代理发送")
+	}
+	if xff, _ := gotXFF.Load().(string); xff == "" {
+		t.Error("开启 --spoof-ip 后原始请求应携带 X-Forwarded-For")
+	}
+}
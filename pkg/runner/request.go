@@ -5,7 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 	"xfirefly/pkg/finger"
@@ -19,7 +22,7 @@ import (
 )
 
 // initializeCache 基于基础信息构建初始 Request/Response，避免重复读取响应体
-func initializeCache(base *BaseInfoResponse, proxy string) (*proto.Response, *proto.Request) {
+func initializeCache(base *BaseInfoResponse, proxy string, method string) (*proto.Response, *proto.Request) {
 	if base == nil || base.Response == nil {
 		return nil, nil
 	}
@@ -42,12 +45,95 @@ func initializeCache(base *BaseInfoResponse, proxy string) (*proto.Response, *pr
 
 	// 构建响应/请求对象
 	initialResponse := finger.BuildProtoResponse(httpResp, utf8RespBody, 0, proxy)
-	initialRequest := finger.BuildProtoRequest(httpResp, "GET", "", "/")
+	initialRequest := finger.BuildProtoRequest(httpResp, method, "", "/")
 	return initialResponse, initialRequest
 }
 
+// loginPathRegex 常见登录/认证页面的URL路径特征
+var loginPathRegex = regexp.MustCompile(`(?i)(^|[/_.-])(login|logon|signin|sign-in|sign_in|auth|authenticate|sso|cas|passport|oauth2?)([/_.?=-]|$)`)
+
+// isLoginRedirect 判断请求是否跳转到了登录/认证页面，跳转后的最终URL与原始目标不同且路径命中登录特征时返回 true
+func isLoginRedirect(target string, resp *http.Response) bool {
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return false
+	}
+	finalURL := resp.Request.URL
+	origin, err := url.Parse(target)
+	if err != nil || (origin.Host == finalURL.Host && origin.Path == finalURL.Path && origin.RawQuery == finalURL.RawQuery) {
+		return false
+	}
+	// 仅匹配路径，查询参数中的 redirect=/login 等不代表跳转到了登录页
+	return loginPathRegex.MatchString(finalURL.Path)
+}
+
+// discardBody 不读取响应体直接关闭连接，并替换为空响应体供后续流程使用
+// 不使用 Range 请求，避免服务器返回206导致基于状态码的指纹误判
+func discardBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(nil))
+	resp.ContentLength = 0
+}
+
+// minProbeBodySize 响应体有效内容小于该长度时视为空响应体
+const minProbeBodySize = 16
+
+// defaultProbeRetries 基础信息探测默认失败重试次数
+const defaultProbeRetries = 3
+
+// retryOnEmptyBody 当探测返回200但响应体为空时重试一次，重试失败则沿用原响应
+func retryOnEmptyBody(target string, method string, options network.OptionsRequest, resp *http.Response) *http.Response {
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		return resp
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
+	if err != nil {
+		data = []byte{}
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if len(bytes.TrimSpace(data)) >= minProbeBodySize {
+		return resp
+	}
+
+	logger.Debugf("目标 %s 返回空响应体，重试一次", target)
+	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	defer cancel()
+	retryResp, err := network.SendRequestHttp(ctx, method, target, "", options)
+	if err != nil || retryResp == nil {
+		logger.Debugf("目标 %s 重试请求失败: %v", target, err)
+		return resp
+	}
+	// 读取重试响应体，避免上下文取消后无法读取
+	retryData, err := io.ReadAll(io.LimitReader(retryResp.Body, network.MaxDefaultBody))
+	if err != nil {
+		retryData = []byte{}
+	}
+	_ = retryResp.Body.Close()
+	retryResp.Body = io.NopCloser(bytes.NewReader(retryData))
+	return retryResp
+}
+
+// defaultRequestTimeout 未设置超时时间时基础信息探测等请求使用的默认超时
+const defaultRequestTimeout = 5 * time.Second
+
+// requestTimeout 返回配置的请求超时时间，未设置（小于等于0）时使用默认超时
+func requestTimeout(config *ScanConfig) time.Duration {
+	if config.Timeout <= 0 {
+		return defaultRequestTimeout
+	}
+	return time.Duration(config.Timeout) * time.Second
+}
+
 // GetBaseInfo 获取目标的基础信息并返回 BaseInfoResponse 结构体
-func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
+func GetBaseInfo(target string, config *ScanConfig) (*BaseInfoResponse, error) {
+	if config == nil {
+		config = &ScanConfig{ProbeRetries: defaultProbeRetries}
+	}
+	proxy := config.Proxy
+
 	// 检查并规范化URL协议
 	if checkedURL, err := network.CheckProtocol(target, proxy); err == nil && checkedURL != "" {
 		target = checkedURL
@@ -57,17 +143,19 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
 		target = "https://" + target
 	}
-	// 设置超时时间
-	timeoutDuration := time.Duration(timeout) * time.Second
-	if timeout <= 0 {
-		timeoutDuration = 5 * time.Second
+	// 协议确定后追加统一路径
+	if config.PathPrefix != "" {
+		target = common.ParseTarget(target, config.PathPrefix)
 	}
+	// 设置超时时间，https目标按倍数延长超时
+	timeoutDuration := network.ScaleTimeout(target, requestTimeout(config))
 
 	// 创建请求选项
 	options := network.OptionsRequest{
 		Proxy:              proxy,
 		Timeout:            timeoutDuration,
-		Retries:            3,
+		Retries:            config.probeRetries(),
+		ExactRetries:       true,
 		FollowRedirects:    true,
 		InsecureSkipVerify: true,
 	}
@@ -76,7 +164,7 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
 	defer cancel()
 
-	resp, err := network.SendRequestHttp(ctx, "GET", target, "", options)
+	resp, err := network.SendRequestHttp(ctx, config.probeMethod(), target, "", options)
 	if err != nil {
 		return &BaseInfoResponse{
 			Url:        target,
@@ -89,6 +177,13 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 		}, fmt.Errorf("发送请求失败: %v", err)
 	}
 
+	// 响应体为空时按需重试一次，忽略响应体时无需重试
+	if config.IgnoreBody {
+		discardBody(resp)
+	} else if config.RetryOnEmptyBody {
+		resp = retryOnEmptyBody(target, config.probeMethod(), options, resp)
+	}
+
 	// 提取基本信息
 	// 获取响应状态码
 	statusCode := int32(resp.StatusCode)
@@ -96,22 +191,52 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	title := finger.GetTitle(target, resp)
 	// 获取服务器信息，包含原始服务器信息、服务器类型和版本
 	serverInfo := finger.GetServerInfoFromResponse(resp)
+	// 需在重置请求URL之前判断，此时 resp.Request 为跳转后的最终请求
+	requiresAuth := isLoginRedirect(target, resp)
+	if requiresAuth {
+		logger.Debugf("目标 %s 跳转至登录页 %s", target, resp.Request.URL.String())
+	}
 	newURL, _ := url.Parse(target)
 	if resp.Request != nil {
 		resp.Request.URL = newURL
 	}
 
-	// 初始化wappalyzer对象
-	wapp, err := wappalyzer.NewWappalyzer()
-	if err != nil {
+	// 禁用Wappalyzer时仅读取响应体供指纹识别复用，跳过站点技术分析
+	if config.NoWappalyzer {
+		data, err := io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
+		if err != nil {
+			logger.Debugf("读取响应体出错: %v", err)
+			data = []byte{}
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return &BaseInfoResponse{
+			Url:          target,
+			Title:        title,
+			RequiresAuth: requiresAuth,
+			Server:       serverInfo,
+			StatusCode:   statusCode,
+			Response:     resp,
+			Wappalyzer:   nil,
+			BodyBytes:    data,
+		}, nil
+	}
+
+	// 优先复用Runner初始化时创建的共享实例，避免每个目标重复加载指纹库
+	wapp := config.Wappalyzer
+	var wappErr error
+	if wapp == nil {
+		wapp, wappErr = wappalyzer.NewWappalyzer()
+	}
+	if wappErr != nil {
 		// 即使获取站点技术信息失败，仍然返回基本信息
 		return &BaseInfoResponse{
-			Url:        target,
-			Title:      title,
-			Server:     serverInfo,
-			StatusCode: statusCode,
-			Response:   resp,
-			Wappalyzer: nil,
+			Url:          target,
+			Title:        title,
+			RequiresAuth: requiresAuth,
+			Server:       serverInfo,
+			StatusCode:   statusCode,
+			Response:     resp,
+			Wappalyzer:   nil,
 		}, nil
 	}
 	// 读取响应体一次并保存，后续复用（限制大小，避免大包体导致内存暴涨）
@@ -128,12 +253,13 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	if err != nil {
 		// 即使获取Wappalyzer数据失败，仍然返回基本信息
 		return &BaseInfoResponse{
-			Url:        target,
-			Title:      title,
-			Server:     serverInfo,
-			StatusCode: statusCode,
-			Response:   resp,
-			Wappalyzer: nil,
+			Url:          target,
+			Title:        title,
+			RequiresAuth: requiresAuth,
+			Server:       serverInfo,
+			StatusCode:   statusCode,
+			Response:     resp,
+			Wappalyzer:   nil,
 		}, nil
 	}
 
@@ -143,12 +269,73 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	// &{[] [] [] [] [] [] [] [] [] [] []}
 
 	return &BaseInfoResponse{
-		Url:        target,
-		Title:      title,
-		Server:     serverInfo,
-		StatusCode: statusCode,
-		Response:   resp,
-		Wappalyzer: wappData,
-		BodyBytes:  data,
+		Url:          target,
+		Title:        title,
+		RequiresAuth: requiresAuth,
+		Server:       serverInfo,
+		StatusCode:   statusCode,
+		Response:     resp,
+		Wappalyzer:   wappData,
+		BodyBytes:    data,
 	}, nil
 }
+
+// probeTLS 探测HTTPS目标接受的TLS协议版本，非HTTPS目标返回nil
+func probeTLS(target string, config *ScanConfig) *types.TLSInfo {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	versions := network.ProbeTLSVersions(net.JoinHostPort(u.Hostname(), port), u.Hostname(), config.Proxy, requestTimeout(config))
+	info := &types.TLSInfo{Versions: versions}
+	for _, v := range versions {
+		if v == "TLS1.0" || v == "TLS1.1" {
+			info.Weak = append(info.Weak, v)
+		}
+	}
+	if len(info.Weak) > 0 {
+		logger.Warnf("目标 %s 接受已废弃的TLS协议版本: %s", target, strings.Join(info.Weak, ","))
+	}
+	return info
+}
+
+// wwwFallbackTarget 目标域名解析不存在时返回www/非www形式的备用目标，备用域名同样不存在或目标为IP时返回false
+// 经代理访问时域名由代理解析，本地解析结果不可信，同样返回false
+func wwwFallbackTarget(target, proxy string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("//" + target)
+		if err != nil || u.Host == "" {
+			return "", false
+		}
+	}
+	if network.UsesProxy(proxy, u) {
+		return "", false
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") || !network.IsHostNotFound(host) {
+		return "", false
+	}
+
+	altHost := "www." + host
+	if strings.HasPrefix(strings.ToLower(host), "www.") {
+		altHost = host[len("www."):]
+	}
+	if !strings.Contains(altHost, ".") || network.IsHostNotFound(altHost) {
+		return "", false
+	}
+
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(altHost, port)
+	} else {
+		u.Host = altHost
+	}
+	if !strings.Contains(target, "://") {
+		return strings.TrimPrefix(u.String(), "//"), true
+	}
+	return u.String(), true
+}
@@ -0,0 +1,280 @@
+package runner
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"xfirefly/pkg/wappalyzer"
+)
+
+func TestIsLoginRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		final  string
+		want   bool
+	}{
+		{"http://example.com/", "http://example.com/login", true},
+		{"http://example.com/", "http://example.com/cas/login?service=x", true},
+		{"http://example.com/", "http://example.com/user/sign-in", true},
+		// 查询参数中出现登录特征不视为跳转到登录页
+		{"http://example.com/", "http://example.com/home?redirect=/login", false},
+		{"http://example.com/", "http://example.com/index?from=auth", false},
+		// 未发生跳转
+		{"http://example.com/login", "http://example.com/login", false},
+		{"http://example.com/", "http://example.com/dashboard", false},
+	}
+	for _, tt := range tests {
+		finalURL, _ := url.Parse(tt.final)
+		resp := &http.Response{Request: &http.Request{URL: finalURL}}
+		if got := isLoginRedirect(tt.target, resp); got != tt.want {
+			t.Errorf("isLoginRedirect(%s -> %s) = %v，期望 %v", tt.target, tt.final, got, tt.want)
+		}
+	}
+}
+
+func TestWWWFallbackTargetSkippedWithProxy(t *testing.T) {
+	// 经代理访问时不依据本地DNS解析结果改用www域名
+	for _, target := range []string{"http://example.invalid", "example.invalid:8080"} {
+		if alt, ok := wwwFallbackTarget(target, "http://127.0.0.1:8080"); ok {
+			t.Errorf("配置代理时 wwwFallbackTarget(%s) 不应返回备用目标，实际 %s", target, alt)
+		}
+	}
+}
+
+func TestProbeRetries(t *testing.T) {
+	var nilConfig *ScanConfig
+	if got := nilConfig.probeRetries(); got != defaultProbeRetries {
+		t.Errorf("未配置扫描参数时重试次数 = %d，期望 %d", got, defaultProbeRetries)
+	}
+	for _, n := range []int{0, 1, 5} {
+		if got := (&ScanConfig{ProbeRetries: n}).probeRetries(); got != n {
+			t.Errorf("ProbeRetries=%d 时重试次数 = %d", n, got)
+		}
+	}
+}
+
+func TestGetBaseInfoRetryOnEmptyBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		retry     bool
+		wantTitle string
+		wantHits  int64
+	}{
+		{"开启重试", true, "Loaded", 2},
+		{"未开启重试", false, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 首次请求返回空响应体，之后返回完整页面
+			var hits int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt64(&hits, 1) == 1 {
+					return
+				}
+				_, _ = io.WriteString(w, "<html><title>Loaded</title><body>app shell rendered</body></html>")
+			}))
+			defer srv.Close()
+
+			info, err := GetBaseInfo(srv.URL, &ScanConfig{Timeout: 5, RetryOnEmptyBody: tt.retry})
+			if err != nil {
+				t.Fatalf("GetBaseInfo 失败: %v", err)
+			}
+			if info.Title != tt.wantTitle {
+				t.Errorf("标题 = %q，期望 %q", info.Title, tt.wantTitle)
+			}
+			if got := atomic.LoadInt64(&hits); got != tt.wantHits {
+				t.Errorf("请求次数 = %d，期望 %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestProbeTLS(t *testing.T) {
+	// 接受 TLS1.1 与 TLS1.2 的站点
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS11, MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// 超时时间为0时使用默认超时
+	for _, timeout := range []int{5, 0} {
+		info := probeTLS(srv.URL, &ScanConfig{Timeout: timeout})
+		if info == nil {
+			t.Fatal("HTTPS目标应返回TLS探测结果")
+		}
+		if got := strings.Join(info.Versions, ","); got != "TLS1.1,TLS1.2" {
+			t.Errorf("Timeout=%d 时接受的协议版本 = %s，期望 TLS1.1,TLS1.2", timeout, got)
+		}
+		if got := strings.Join(info.Weak, ","); got != "TLS1.1" {
+			t.Errorf("Timeout=%d 时弱协议版本 = %s，期望 TLS1.1", timeout, got)
+		}
+	}
+
+	if info := probeTLS("http://example.com", &ScanConfig{Timeout: 5}); info != nil {
+		t.Errorf("非HTTPS目标不应探测TLS版本，实际 %+v", info)
+	}
+}
+
+func TestGetBaseInfoIgnoreBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.20.1")
+		_, _ = io.WriteString(w, "<html><title>Ignored</title><body>large body</body></html>")
+	}))
+	defer srv.Close()
+
+	info, err := GetBaseInfo(srv.URL, &ScanConfig{Timeout: 5, IgnoreBody: true})
+	if err != nil {
+		t.Fatalf("GetBaseInfo 失败: %v", err)
+	}
+	// 仅保留状态码与响应头
+	if info.StatusCode != http.StatusOK || info.Server == nil || info.Server.OriginalServer != "nginx/1.20.1" {
+		t.Errorf("状态码 = %d，Server = %+v，期望 200 与 nginx/1.20.1", info.StatusCode, info.Server)
+	}
+	if info.Title != "" || len(info.BodyBytes) != 0 {
+		t.Errorf("标题 = %q，响应体 = %q，期望为空", info.Title, info.BodyBytes)
+	}
+}
+
+func TestGetBaseInfoNoWappalyzer(t *testing.T) {
+	const body = "<html><title>PHP</title><body>app</body></html>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "PHP/7.4")
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		noWappalyzer bool
+	}{
+		{"启用Wappalyzer", false},
+		{"禁用Wappalyzer", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetBaseInfo(srv.URL, &ScanConfig{Timeout: 5, NoWappalyzer: tt.noWappalyzer})
+			if err != nil {
+				t.Fatalf("GetBaseInfo 失败: %v", err)
+			}
+			if got := info.Wappalyzer == nil; got != tt.noWappalyzer {
+				t.Errorf("Wappalyzer 结果为空 = %v，期望 %v", got, tt.noWappalyzer)
+			}
+			// 禁用Wappalyzer时仍读取响应体供指纹识别复用
+			if info.Title != "PHP" || string(info.BodyBytes) != body {
+				t.Errorf("标题 = %q，响应体 = %q", info.Title, info.BodyBytes)
+			}
+		})
+	}
+}
+
+func TestGetBaseInfoProbeMethod(t *testing.T) {
+	var lastMethod atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod.Store(r.Method)
+		_, _ = io.WriteString(w, "<html><title>Probe</title></html>")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		probeMethod string
+		want        string
+	}{
+		{"未指定时默认GET", "", http.MethodGet},
+		{"HEAD探测", http.MethodHead, http.MethodHead},
+		{"POST探测", http.MethodPost, http.MethodPost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastMethod.Store("")
+			info, err := GetBaseInfo(srv.URL, &ScanConfig{Timeout: 5, ProbeMethod: tt.probeMethod})
+			if err != nil {
+				t.Fatalf("GetBaseInfo 失败: %v", err)
+			}
+			if info.StatusCode != http.StatusOK {
+				t.Errorf("状态码 = %d，期望 200", info.StatusCode)
+			}
+			if got := lastMethod.Load(); got != tt.want {
+				t.Errorf("服务端收到的请求方法 = %v，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBaseInfoPathPrefix(t *testing.T) {
+	var probedPath atomic.Value
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 协议识别使用HEAD请求，仅记录基础信息探测的GET请求
+		if r.Method == http.MethodGet {
+			probedPath.Store(r.URL.Path)
+		}
+		_, _ = io.WriteString(w, "<html><title>App</title></html>")
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+
+	tests := []struct {
+		name       string
+		target     string
+		wantScheme string
+		wantPath   string
+	}{
+		{"无协议目标识别协议后追加", strings.TrimPrefix(tlsSrv.URL, "https://"), "https://", "/app/"},
+		{"带协议目标去除末尾斜杠后追加", srv.URL + "/", "http://", "/app/"},
+		{"追加到已有路径之后", srv.URL + "/base", "http://", "/base/app/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probedPath.Store("")
+			info, err := GetBaseInfo(tt.target, &ScanConfig{Timeout: 5, PathPrefix: "/app/"})
+			if err != nil {
+				t.Fatalf("GetBaseInfo 失败: %v", err)
+			}
+			if got := probedPath.Load(); got != tt.wantPath {
+				t.Errorf("请求路径 = %v，期望 %s", got, tt.wantPath)
+			}
+			if !strings.HasPrefix(info.Url, tt.wantScheme) || !strings.HasSuffix(info.Url, tt.wantPath) {
+				t.Errorf("基础信息地址 = %s，期望以 %s 开头并以 %s 结尾", info.Url, tt.wantScheme, tt.wantPath)
+			}
+		})
+	}
+}
+
+// BenchmarkGetBaseInfoWappalyzer 对比复用共享Wappalyzer实例与每次探测单独创建实例的开销
+func BenchmarkGetBaseInfoWappalyzer(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.20.1")
+		w.Header().Set("X-Powered-By", "PHP/7.4")
+		_, _ = io.WriteString(w, "<html><title>Bench</title><body>app</body></html>")
+	}))
+	defer srv.Close()
+
+	shared, err := wappalyzer.NewWappalyzer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarks := []struct {
+		name   string
+		config *ScanConfig
+	}{
+		{"共享实例", &ScanConfig{Timeout: 5, Wappalyzer: shared}},
+		{"每次创建", &ScanConfig{Timeout: 5}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := GetBaseInfo(srv.URL, bm.config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
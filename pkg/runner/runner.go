@@ -2,11 +2,17 @@ package runner
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/network"
 	"xfirefly/pkg/output"
 	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils/common"
+	"xfirefly/pkg/wappalyzer"
 
 	"github.com/donnie4w/go-logger/logger"
 )
@@ -25,10 +31,19 @@ type Runner struct {
 	Results   map[string]*TargetResult // 扫描结果
 	mutex     sync.RWMutex             // 读写锁保护Results
 	isRunning atomic.Bool              // 运行状态标志
+	RunID     string                   // 运行ID，启用--output-append-id时生成
+	summary   *ScanError               // 最近一次扫描的失败汇总，受mutex保护
+
+	stopReload func() // 停止指纹热加载监听，服务模式下使用
 }
 
-// NewRunner 创建一个新的扫描运行器
-func NewRunner(options *types.CmdOptionsType) *Runner {
+// NewScanConfig 校验并规范化命令行参数，生成扫描配置
+// 线程数等可修正的参数会被钳制到合法范围，无法修正的参数返回描述性错误
+func NewScanConfig(options *types.CmdOptionsType) (*ScanConfig, error) {
+	if options == nil {
+		return nil, fmt.Errorf("扫描参数不能为空")
+	}
+
 	// 设置URL并发参数，通过参数获取线程数，参数小于0时使用程序默认
 	urlWorkerCount := options.Threads
 	if urlWorkerCount <= 0 {
@@ -53,21 +68,147 @@ func NewRunner(options *types.CmdOptionsType) *Runner {
 		ruleWorkerCount = MaxRuleWorkers
 	}
 
+	// 超时时间不能为负数，0表示使用默认值
+	if options.Timeout < 0 {
+		return nil, fmt.Errorf("超时时间不能为负数: %d", options.Timeout)
+	}
+
+	// 探测重试次数不能为负数，0表示不重试
+	if options.ProbeRetries < 0 {
+		return nil, fmt.Errorf("探测重试次数不能为负数: %d", options.ProbeRetries)
+	}
+
+	// https超时倍数不能为负数，0表示不调整
+	if options.HTTPSTimeoutFactor < 0 {
+		return nil, fmt.Errorf("https超时倍数不能为负数: %g", options.HTTPSTimeoutFactor)
+	}
+
+	// 指纹评估超时不能为负数，0表示不限制
+	if options.FingerprintTimeout < 0 {
+		return nil, fmt.Errorf("指纹评估超时时间不能为负数: %d", options.FingerprintTimeout)
+	}
+
+	// 输出刷新间隔不能为负数，0表示不定期刷新
+	if options.OutputFlushInterval < 0 {
+		return nil, fmt.Errorf("输出刷新间隔不能为负数: %d", options.OutputFlushInterval)
+	}
+
+	// CDN判断依赖Wappalyzer识别结果，禁用Wappalyzer时无法生效
+	if options.ExcludeCDN && !options.Wappalyzer {
+		return nil, fmt.Errorf("--exclude-cdn 依赖Wappalyzer识别CDN，不能与 --wappalyzer=false 同时使用")
+	}
+
+	// DNS缓存有效期不能为负数，0表示不缓存
+	if options.DNSCacheTTL < 0 {
+		return nil, fmt.Errorf("DNS缓存有效期不能为负数: %d", options.DNSCacheTTL)
+	}
+
+	// 分批大小不能为负数，0表示不分批
+	if options.ChunkSize < 0 {
+		return nil, fmt.Errorf("分批大小不能为负数: %d", options.ChunkSize)
+	}
+
+	// 探测请求方法只能由字母组成
+	probeMethod := strings.ToUpper(strings.TrimSpace(options.ProbeMethod))
+	for _, c := range probeMethod {
+		if c < 'A' || c > 'Z' {
+			return nil, fmt.Errorf("探测请求方法不合法: %s", options.ProbeMethod)
+		}
+	}
+
+	// 规范化目标路径前缀，统一以/开头且不以/结尾
+	pathPrefix := strings.Trim(strings.TrimSpace(options.PathPrefix), "/")
+	if pathPrefix != "" {
+		pathPrefix = "/" + pathPrefix
+	}
+
 	// 确定输出格式
 	// 通过传入的参数
 	outputFormat := output.GetOutputFormat(options.JSONOutput, options.Output)
 
 	// 创建配置
 	config := &ScanConfig{
-		Proxy:             options.Proxy,
-		Timeout:           options.Timeout,
-		URLWorkerCount:    urlWorkerCount,
-		FingerWorkerCount: ruleWorkerCount,
-		OutputFormat:      outputFormat,
-		OutputFile:        options.Output,
-		SockOutputFile:    options.SockOutput,
+		Proxy:              options.Proxy,
+		Timeout:            options.Timeout,
+		URLWorkerCount:     urlWorkerCount,
+		FingerWorkerCount:  ruleWorkerCount,
+		OutputFormat:       outputFormat,
+		OutputFile:         options.Output,
+		SockOutputFile:     options.SockOutput,
+		ExcludeCDN:         options.ExcludeCDN,
+		ProbeMethod:        probeMethod,
+		ProbeRetries:       options.ProbeRetries,
+		Active:             options.Active,
+		RetryOnEmptyBody:   options.RetryOnEmptyBody,
+		Ordered:            options.Ordered,
+		HeaderOnlyMatch:    options.HeaderOnlyMatch || options.IgnoreBody,
+		IgnoreBody:         options.IgnoreBody,
+		TryWWW:             options.TryWWW,
+		NoWappalyzer:       !options.Wappalyzer,
+		TLSProbe:           options.TLSProbe,
+		ChunkSize:          options.ChunkSize,
+		PathPrefix:         pathPrefix,
+		RandomizeTargets:   options.RandomizeTargets,
+		Seed:               options.Seed,
+		KeepRaw:            options.KeepRaw,
+		StopAtFirstMatch:   options.StopAtFirstMatch,
+		FingerprintTimeout: time.Duration(options.FingerprintTimeout) * time.Second,
+	}
+
+	return config, nil
+}
+
+// NewRunner 创建一个新的扫描运行器
+func NewRunner(options *types.CmdOptionsType) (*Runner, error) {
+	// 校验参数并生成扫描配置
+	config, err := NewScanConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// 原始请求模式，rawhttp客户端仅支持 http 与 socks5 代理
+	network.SetRawMode(options.RawMode)
+	if options.RawMode && strings.HasPrefix(strings.ToLower(options.Proxy), "https://") {
+		return nil, fmt.Errorf("原始请求模式仅支持 http 与 socks5 代理: %s", options.Proxy)
+	}
+
+	// 设置代理、DNS缓存、TLS等网络请求参数
+	if err := configureNetwork(options); err != nil {
+		return nil, err
+	}
+
+	// 创建共享的Wappalyzer实例，分析过程只读，可供所有URL协程复用
+	if config.NoWappalyzer {
+		logger.Info("已禁用Wappalyzer站点技术识别")
+	} else if wapp, err := wappalyzer.NewWappalyzer(); err != nil {
+		logger.Warnf("初始化Wappalyzer失败，将在探测时单独创建: %v", err)
+	} else {
+		config.Wappalyzer = wapp
+	}
+
+	// 设置txt/csv输出文件的字符编码
+	if err := output.SetOutputEncoding(options.OutputEncoding); err != nil {
+		return nil, err
+	}
+	output.SetFlushInterval(time.Duration(options.OutputFlushInterval) * time.Second)
+	output.SetOutputGzip(options.OutputGzip)
+	output.SetIncludeRaw(options.OutputIncludeRequest, options.OutputIncludeResponse)
+	output.SetRedactHeaders(options.RedactHeaders)
+
+	// 设置基础信息持久化缓存目录
+	if err := SetCacheDir(options.CacheDir); err != nil {
+		return nil, err
 	}
 
+	// 设置是否禁用favicon抓取
+	finger.SetFaviconDisabled(options.NoFavicon)
+	finger.SetFaviconFallbackPaths(options.FaviconPaths)
+	finger.SetIconURLOverride(options.IconURL)
+	finger.SetAllIconHashes(options.AllIcons)
+
+	// 设置标题最大长度
+	finger.SetTitleMaxLen(options.TitleMaxLen)
+
 	// 创建Runner实例
 	runner := &Runner{
 		Config:  config, // 扫描配置
@@ -75,10 +216,52 @@ func NewRunner(options *types.CmdOptionsType) *Runner {
 		mutex:   sync.RWMutex{},
 	}
 
-	return runner
+	// 生成本次运行ID，所有输出记录共用
+	if options.OutputAppendID {
+		runner.RunID = newRunID()
+		logger.Infof("本次运行ID：%s", runner.RunID)
+	}
+
+	return runner, nil
+}
+
+// configureNetwork 按命令行参数设置网络请求相关的全局配置，扫描与原始请求重放共用
+func configureNetwork(options *types.CmdOptionsType) error {
+	// 按需缓存DNS解析结果
+	network.SetDNSCache(time.Duration(options.DNSCacheTTL) * time.Second)
+
+	// 未指定代理时按需使用环境变量代理
+	if options.EnvProxy && options.Proxy == "" {
+		network.SetEnvProxy(true)
+	}
+
+	// rawhttp客户端使用的代理，需在首次调用 GetRawHTTP 之前设置
+	network.SetRawHTTPProxy(options.Proxy)
+
+	// 代理请求失败时直连重试
+	network.SetProxyFallback(options.ProxyFallback)
+
+	// 设置代理绕过列表
+	if len(options.NoProxy) > 0 {
+		network.SetNoProxy(options.NoProxy)
+	}
+
+	// 设置X-Forwarded-For伪造来源IP模式
+	if err := network.SetSpoofIP(options.SpoofIP); err != nil {
+		return err
+	}
+
+	// https目标按倍数延长超时
+	network.SetHTTPSTimeoutMultiplier(options.HTTPSTimeoutFactor)
+
+	// 设置目标站点与HTTPS代理的证书校验方式
+	network.SetTLSVerify(options.VerifyTLS, options.VerifyProxyTLS)
+
+	// 设置TLS最低版本与加密套件
+	return network.SetTLSOptions(options.MinTLS, options.TLSDefaultCiphers)
 }
 
-// Run 执行扫描
+// Run 执行扫描，仅在扫描无法进行时返回错误，失败目标的汇总通过 LastScanSummary 获取
 func (r *Runner) Run(options *types.CmdOptionsType) error {
 
 	// 检查扫描器是否已经运行
@@ -102,17 +285,37 @@ func (r *Runner) Run(options *types.CmdOptionsType) error {
 	// 打印扫描目标数
 	logger.Info(fmt.Sprintf("准备扫描 %d 个目标", len(targets)))
 
+	// 设置输出记录附带的运行ID
+	output.SetRunID(r.RunID)
+	output.SetOutputDedup(options.OutputDedup)
+
 	// 初始化输出文件
 	if r.Config.OutputFile != "" {
 		if err := output.InitOutput(r.Config.OutputFile, r.Config.OutputFormat); err != nil {
 			return fmt.Errorf("初始化输出文件失败: %v", err)
 		}
-		logger.Info(fmt.Sprintf("日志输出文件：%s", r.Config.OutputFile))
+		logger.Info(fmt.Sprintf("日志输出文件：%s", output.OutputPath(r.Config.OutputFile)))
 		defer func() {
 			_ = output.Close()
 		}()
 	}
 
+	// 初始化请求审计日志
+	if options.RequestLog != "" {
+		if err := network.InitRequestLog(options.RequestLog); err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("请求审计日志文件：%s", options.RequestLog))
+		defer func() {
+			_ = network.CloseRequestLog()
+		}()
+	}
+
+	// 设置自定义控制台输出模板
+	if err := output.SetOutputTemplate(options.OutputTemplate); err != nil {
+		return fmt.Errorf("输出模板解析失败: %v", err)
+	}
+
 	// 初始化socket文件输出
 	if r.Config.SockOutputFile != "" {
 		if err := output.InitSockOutput(r.Config.SockOutputFile); err != nil {
@@ -125,7 +328,11 @@ func (r *Runner) Run(options *types.CmdOptionsType) error {
 	if err := LoadFingerprints(options.FingerOptions); err != nil {
 		return fmt.Errorf("加载指纹规则出错: %v", err)
 	}
-	logger.Info(fmt.Sprintf("加载指纹数量：%v个", len(AllFinger)))
+	logger.Info(fmt.Sprintf("加载指纹数量：%v个", GetFingerCount()))
+
+	// 支持通过SIGHUP信号热加载指纹规则
+	stopReload := WatchFingerprintReload(options.FingerOptions)
+	defer stopReload()
 
 	fingerActive := false
 	// 是否做主动指纹识别
@@ -154,14 +361,21 @@ func (r *Runner) Run(options *types.CmdOptionsType) error {
 	// 清除所有缓存
 	ClearAllCache()
 
-	// 打印统计信息
-	r.mutex.RLock()
+	// 打印统计信息并汇总失败目标
+	r.mutex.Lock()
 	printSummary(targets, r.Results)
-	r.mutex.RUnlock()
-
+	r.summary = newScanError(targets, r.Results)
+	r.mutex.Unlock()
 	return nil
 }
 
+// LastScanSummary 返回最近一次扫描按错误类别汇总的失败目标，没有失败目标时返回nil
+func (r *Runner) LastScanSummary() *ScanError {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.summary
+}
+
 // ScanTarget 扫描单个目标URL
 func (r *Runner) ScanTarget(target string) (*TargetResult, error) {
 	if !r.isRunning.Load() {
@@ -169,7 +383,7 @@ func (r *Runner) ScanTarget(target string) (*TargetResult, error) {
 	}
 
 	// 处理单个URL
-	result, err := ProcessURL(target, r.Config.Proxy, r.Config.Timeout, r.Config.FingerWorkerCount)
+	result, err := scanTargetWithStats(target, r.Config)
 	if err != nil {
 		return nil, err
 	}
@@ -177,8 +391,138 @@ func (r *Runner) ScanTarget(target string) (*TargetResult, error) {
 	return result, nil
 }
 
+// IsRunning 扫描器是否处于运行状态
+func (r *Runner) IsRunning() bool {
+	return r.isRunning.Load()
+}
+
+// Start 以常驻方式启动扫描器：加载指纹并初始化规则池，供服务模式通过 ScanTarget/ScanTargets 调用
+func (r *Runner) Start(options *types.CmdOptionsType) error {
+	if !r.isRunning.CompareAndSwap(false, true) {
+		return fmt.Errorf("扫描器已在运行中")
+	}
+
+	// 设置输出记录附带的运行ID
+	output.SetRunID(r.RunID)
+	output.SetOutputDedup(options.OutputDedup)
+
+	// 初始化请求审计日志
+	if err := network.InitRequestLog(options.RequestLog); err != nil {
+		r.isRunning.Store(false)
+		return err
+	}
+
+	// 加载指纹规则
+	if err := LoadFingerprints(options.FingerOptions); err != nil {
+		r.isRunning.Store(false)
+		_ = network.CloseRequestLog()
+		return fmt.Errorf("加载指纹规则出错: %v", err)
+	}
+	logger.Info(fmt.Sprintf("加载指纹数量：%v个", GetFingerCount()))
+
+	// 初始化全局规则池
+	if !IsRulePoolInitialized() {
+		if err := InitGlobalRulePool(r.Config.FingerWorkerCount, options.Active); err != nil {
+			r.isRunning.Store(false)
+			_ = network.CloseRequestLog()
+			return err
+		}
+	}
+
+	// 常驻运行时同样支持通过SIGHUP信号热加载指纹规则
+	r.stopReload = WatchFingerprintReload(options.FingerOptions)
+	return nil
+}
+
+// Stop 停止常驻扫描器并释放规则池资源
+func (r *Runner) Stop() {
+	if !r.isRunning.CompareAndSwap(true, false) {
+		return
+	}
+	if r.stopReload != nil {
+		r.stopReload()
+		r.stopReload = nil
+	}
+	ReleaseRulePool()
+	ClearAllCache()
+	_ = network.CloseRequestLog()
+}
+
+// ScanTargets 并发扫描一组目标，按输入顺序返回结果，不输出到控制台或文件
+func (r *Runner) ScanTargets(targets []string) ([]*TargetResult, error) {
+	if !r.isRunning.Load() {
+		return nil, fmt.Errorf("扫描器未运行")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("未找到有效的目标URL")
+	}
+
+	results := make([]*TargetResult, len(targets))
+	var wg sync.WaitGroup
+
+	pool, err := NewWorkPoolWithFunc(
+		r.Config.URLWorkerCount,
+		func(i interface{}) {
+			defer wg.Done()
+			index, ok := i.(int)
+			if !ok {
+				logger.Error("无效的URL任务类型")
+				return
+			}
+
+			target := targets[index]
+			startTime := time.Now()
+			targetResult, err := scanTargetWithStats(target, r.Config)
+			if err != nil {
+				logger.Errorf("处理目标 %s 失败: %v", target, err)
+				targetResult = &TargetResult{
+					URL:     target,
+					Matches: make([]*FingerMatch, 0),
+					Err:     err,
+				}
+			}
+			targetResult.Duration = time.Since(startTime)
+			sortMatches(targetResult.Matches)
+			results[index] = targetResult
+		},
+		r.Config.URLWorkerCount*5,
+		3*time.Minute,
+		func(i interface{}) { logger.Errorf("URL池goroutine异常: %v", i) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建URL处理池失败: %v", err)
+	}
+	defer pool.Release()
+
+	for index, target := range targets {
+		wg.Add(1)
+		if err := pool.Invoke(index); err != nil {
+			wg.Done()
+			logger.Errorf("提交目标 %s 到线程池失败: %v", target, err)
+			results[index] = &TargetResult{
+				URL:     target,
+				Matches: make([]*FingerMatch, 0),
+				Err:     err,
+			}
+		}
+	}
+	wg.Wait()
+
+	// 常驻运行时及时落盘审计日志
+	if err := network.FlushRequestLog(); err != nil {
+		logger.Debugf("刷新请求日志出错: %v", err)
+	}
+
+	return results, nil
+}
+
 // runScan 执行扫描过程
 func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error {
+	// 按需打乱扫描顺序
+	if r.Config.RandomizeTargets {
+		targets = shuffleTargets(targets, r.Config.Seed)
+	}
+
 	// 使用较小缓冲通道收集结果，避免为大规模目标一次性分配巨大缓冲区
 	resultChan := make(chan struct {
 		target string
@@ -236,8 +580,10 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error
 		}
 	}()
 
-	// 收集结果的协程
+	// 收集结果的协程，返回前等待其处理完全部结果
+	collectDone := make(chan struct{})
 	go func() {
+		defer close(collectDone)
 		for data := range resultChan {
 			r.mutex.Lock()
 			r.Results[data.target] = data.result
@@ -257,9 +603,30 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error
 
 	// 定义URL处理任务结构体
 	type urlTask struct {
+		index  int
 		target string
 	}
 
+	// 输出结果并释放大对象
+	emitResult := func(targetResult *TargetResult) {
+		if targetResult == nil {
+			return
+		}
+		// 将结果写入文件并显示结果
+		handleMatchResults(targetResult, options, saveResult, r.Config.OutputFormat)
+
+		// 结果已输出，释放大对象以降低常驻内存
+		if !r.Config.KeepRaw {
+			releaseRawData(targetResult)
+		}
+	}
+
+	// 有序模式下按输入顺序输出
+	var emitter *orderedEmitter
+	if r.Config.Ordered {
+		emitter = newOrderedEmitter(emitResult)
+	}
+
 	var urlWg sync.WaitGroup
 
 	// 创建URL处理工作池（通过统一封装）
@@ -275,43 +642,45 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error
 
 			target := task.target
 
-			// 处理单个URL
-			targetResult, err := ProcessURL(target, options.Proxy, options.Timeout, r.Config.FingerWorkerCount)
+			// 有序模式下处理异常退出时以nil占位，避免后续结果一直缓存而无法输出
+			submitted := false
+			if emitter != nil {
+				defer func() {
+					if !submitted {
+						emitter.Submit(task.index, nil)
+					}
+				}()
+			}
+
+			// 处理单个URL并记录耗时
+			startTime := time.Now()
+			targetResult, err := scanTargetWithStats(target, r.Config)
 			if err != nil {
 				logger.Errorf("处理目标 %s 失败: %v", target, err)
 				targetResult = &TargetResult{
 					URL:     target,
 					Matches: make([]*FingerMatch, 0),
+					Err:     err,
 				}
 			}
-
-			// 将结果写入文件并显示结果
-			handleMatchResults(targetResult, options, saveResult, r.Config.OutputFormat)
-
-			// 结果已输出，释放大对象以降低常驻内存
-			for _, m := range targetResult.Matches {
-				m.Request = nil
-				m.Response = nil
+			targetResult.Duration = time.Since(startTime)
+
+			// 输出结果，有序模式下交由有序输出器按顺序输出
+			if emitter != nil {
+				emitter.Submit(task.index, targetResult)
+				submitted = true
+			} else {
+				emitResult(targetResult)
 			}
-			targetResult.LastRequest = nil
-			targetResult.LastResponse = nil
 
-			// 通过通道发送结果
-			select {
-			case resultChan <- struct {
+			// 通过通道发送结果，收集协程在通道关闭前持续消费，阻塞发送不会丢失结果
+			resultChan <- struct {
 				target string
 				result *TargetResult
-			}{target, targetResult}:
-			default:
-				logger.Debug("结果通道已满，丢弃结果")
-			}
+			}{target, targetResult}
 
 			// 通知完成一个任务
-			select {
-			case doneChan <- struct{}{}:
-			default:
-				logger.Debug("完成通道已满")
-			}
+			doneChan <- struct{}{}
 		},
 		r.Config.URLWorkerCount*5,
 		3*time.Minute,
@@ -323,21 +692,51 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error
 	}
 	defer pool.Release()
 
-	// 提交所有目标到线程池
-	for _, target := range targets {
-		urlWg.Add(1)
-		if err := pool.Invoke(urlTask{target: target}); err != nil {
-			urlWg.Done()
-			logger.Errorf("提交目标 %s 到线程池失败: %v", target, err)
-		}
+	// 分批大小，未设置时一次性提交全部目标
+	chunkSize := r.Config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(targets)
 	}
 
-	// 等待当前批次完成
-	urlWg.Wait()
+	// 按批次提交目标到线程池
+	for start := 0; start < len(targets); start += chunkSize {
+		end := start + chunkSize
+		if end > len(targets) {
+			end = len(targets)
+		}
+		for index := start; index < end; index++ {
+			target := targets[index]
+			urlWg.Add(1)
+			if err := pool.Invoke(urlTask{index: index, target: target}); err != nil {
+				urlWg.Done()
+				logger.Errorf("提交目标 %s 到线程池失败: %v", target, err)
+				// 提交失败的目标占位，避免阻塞后续结果输出
+				if emitter != nil {
+					emitter.Submit(index, nil)
+				}
+			}
+		}
+
+		// 等待当前批次完成
+		urlWg.Wait()
+
+		// 批次之间刷新输出并清理缓存，控制长时间运行的内存占用
+		if end < len(targets) {
+			if err := output.Flush(); err != nil {
+				logger.Debugf("刷新输出文件出错: %v", err)
+			}
+			if err := network.FlushRequestLog(); err != nil {
+				logger.Debugf("刷新请求日志出错: %v", err)
+			}
+			ClearAllCache()
+			logger.Debugf("第 %d-%d 个目标扫描完成，已刷新输出并清理缓存", start+1, end)
+		}
+	}
 
 	// 等待所有URL处理完成
 	close(resultChan)
 	close(doneChan)
+	<-collectDone
 
 	// 停止刷新进度条
 	close(stopRefreshChan)
@@ -357,8 +756,62 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptionsType) error
 
 	// 打印池统计信息
 	stats := GetRulePoolStats()
-	logger.Infof("规则池统计 - 总任务: %d, 已完成: %d, 失败: %d",
-		stats.TotalTasks, stats.CompletedTasks, stats.FailedTasks)
+	logger.Infof("规则池统计 - 总任务: %d, 已完成: %d, 失败: %d（其中超时: %d）",
+		stats.TotalTasks, stats.CompletedTasks, stats.FailedTasks, stats.TimedOutTasks)
 
 	return nil
 }
+
+// newRunID 生成运行ID，由启动时间与随机串组成
+func newRunID() string {
+	return time.Now().Format("20060102150405") + "-" + common.RandomString(6)
+}
+
+// shuffleTargets 返回打乱顺序后的目标副本，seed 为0时随机生成种子并记录，便于复现
+func shuffleTargets(targets []string, seed int64) []string {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger.Infof("已打乱目标扫描顺序，随机种子：%d", seed)
+
+	shuffled := make([]string, len(targets))
+	copy(shuffled, targets)
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// orderedEmitter 有序结果输出器，缓存提前完成的结果，按输入下标依次输出
+type orderedEmitter struct {
+	mutex   sync.Mutex
+	next    int                   // 下一个待输出的下标
+	pending map[int]*TargetResult // 已完成但未轮到输出的结果
+	emit    func(*TargetResult)   // 实际输出函数
+}
+
+// newOrderedEmitter 创建有序结果输出器
+func newOrderedEmitter(emit func(*TargetResult)) *orderedEmitter {
+	return &orderedEmitter{
+		pending: make(map[int]*TargetResult),
+		emit:    emit,
+	}
+}
+
+// Submit 提交指定下标的结果，并输出所有已连续就绪的结果，result 为 nil 时仅占位
+func (o *orderedEmitter) Submit(index int, result *TargetResult) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.pending[index] = result
+	for {
+		ready, ok := o.pending[o.next]
+		if !ok {
+			return
+		}
+		delete(o.pending, o.next)
+		o.emit(ready)
+		o.next++
+	}
+}
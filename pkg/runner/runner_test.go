@@ -0,0 +1,543 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"xfirefly/pkg/output"
+	"xfirefly/pkg/types"
+)
+
+// closedPortURL 返回一个已关闭端口的URL，连接该地址会被拒绝
+func closedPortURL(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return "http://" + addr
+}
+
+func TestRunLastScanSummary(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "summary-finger")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<title>ok</title>")
+	}))
+	defer srv.Close()
+
+	options := &types.CmdOptionsType{
+		Target:        []string{srv.URL, closedPortURL(t), closedPortURL(t)},
+		FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:       5,
+	}
+	r, err := NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	// 部分目标失败不应使Run返回错误
+	if err := r.Run(options); err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+
+	summary := r.LastScanSummary()
+	if summary == nil {
+		t.Fatal("存在失败目标时应返回失败汇总")
+	}
+	if summary.Total != 3 || summary.Failed != 2 {
+		t.Errorf("汇总 = %d/%d，期望 2/3", summary.Failed, summary.Total)
+	}
+	if summary.Kinds[ErrorKindRefused] != 2 {
+		t.Errorf("连接拒绝数 = %d，期望 2，实际汇总 %v", summary.Kinds[ErrorKindRefused], summary.Kinds)
+	}
+	if got := summary.Ratio(ErrorKindRefused); got < 0.66 || got > 0.67 {
+		t.Errorf("连接拒绝占比 = %v，期望 2/3", got)
+	}
+
+	// 全部目标成功时汇总为nil
+	options.Target = []string{srv.URL}
+	if err := r.Run(options); err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if summary := r.LastScanSummary(); summary != nil {
+		t.Errorf("全部目标成功时汇总应为nil，实际 %v", summary)
+	}
+}
+
+func TestOrderedEmitter(t *testing.T) {
+	const total = 200
+	var emitted []string
+	emitter := newOrderedEmitter(func(result *TargetResult) {
+		// 提交失败的目标以nil占位
+		if result == nil {
+			emitted = append(emitted, "nil")
+			return
+		}
+		emitted = append(emitted, result.URL)
+	})
+
+	// 乱序并发提交，下标为 7 的目标提交失败只占位
+	var wg sync.WaitGroup
+	for _, index := range rand.Perm(total) {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			if index == 7 {
+				emitter.Submit(index, nil)
+				return
+			}
+			emitter.Submit(index, &TargetResult{URL: strconv.Itoa(index)})
+		}(index)
+	}
+	wg.Wait()
+
+	if len(emitted) != total {
+		t.Fatalf("输出结果数 = %d，期望 %d", len(emitted), total)
+	}
+	for i, url := range emitted {
+		want := strconv.Itoa(i)
+		if i == 7 {
+			want = "nil"
+		}
+		if url != want {
+			t.Fatalf("第 %d 个输出 = %s，期望 %s", i, url, want)
+		}
+	}
+}
+
+func TestRunOrdered(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "ordered-finger")
+
+	// 靠前的目标响应更慢，并发扫描时完成顺序与输入顺序相反
+	delays := []time.Duration{300 * time.Millisecond, 200 * time.Millisecond, 100 * time.Millisecond, 0}
+	targets := make([]string, len(delays))
+	for i, delay := range delays {
+		delay := delay
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			_, _ = io.WriteString(w, "<title>ordered</title>")
+		}))
+		defer srv.Close()
+		targets[i] = srv.URL
+	}
+
+	ClearAllCache()
+	outputPath := filepath.Join(t.TempDir(), "ordered.json")
+	options := &types.CmdOptionsType{
+		Target:        targets,
+		FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:       5,
+		Threads:       len(targets),
+		Output:        outputPath,
+		JSONOutput:    true,
+		Ordered:       true,
+	}
+	r, err := NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	if err := r.Run(options); err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if err := output.CloseFileOutput(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var got []string
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var row output.JSONOutput
+		if err := decoder.Decode(&row); err != nil {
+			t.Fatalf("解析输出失败: %v", err)
+		}
+		got = append(got, row.URL)
+	}
+	if strings.Join(got, ",") != strings.Join(targets, ",") {
+		t.Errorf("输出顺序 = %v，期望与输入顺序一致 %v", got, targets)
+	}
+}
+
+func TestRunStdinTargets(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "stdin-finger")
+
+	targets := make([]string, 2)
+	for i := range targets {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "<title>stdin</title>")
+		}))
+		defer srv.Close()
+		targets[i] = srv.URL
+	}
+
+	// 以管道替换标准输入，模拟 cat urls.txt | xfirefly
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := io.WriteString(w, targets[0]+"\n\n"+targets[1]+"\n"+targets[0]+"\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	previousStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = previousStdin }()
+
+	ClearAllCache()
+	outputPath := filepath.Join(t.TempDir(), "stdin.json")
+	options := &types.CmdOptionsType{
+		StdinInput:    true,
+		FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:       5,
+		Threads:       2,
+		Output:        outputPath,
+		JSONOutput:    true,
+		Ordered:       true,
+	}
+	r, err := NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	if err := r.Run(options); err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if err := output.CloseFileOutput(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var got []string
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var row output.JSONOutput
+		if err := decoder.Decode(&row); err != nil {
+			t.Fatalf("解析输出失败: %v", err)
+		}
+		got = append(got, row.URL)
+	}
+	// 空行被跳过，重复目标只扫描一次
+	if strings.Join(got, ",") != strings.Join(targets, ",") {
+		t.Errorf("扫描目标 = %v，期望 %v", got, targets)
+	}
+}
+
+func TestRunChunkSize(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "chunk-finger")
+
+	// 统计同时在处理的请求数
+	var inFlight, maxInFlight int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt64(&maxInFlight)
+			if current <= peak || atomic.CompareAndSwapInt64(&maxInFlight, peak, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "<title>chunk</title>")
+	})
+	targets := make([]string, 4)
+	for i := range targets {
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+		targets[i] = srv.URL
+	}
+
+	tests := []struct {
+		name      string
+		chunkSize int
+		wantMax   int64 // 同时处理请求数的上限
+	}{
+		{"每批一个目标", 1, 1},
+		{"不分批", 0, int64(len(targets))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&maxInFlight, 0)
+			options := &types.CmdOptionsType{
+				Target:        targets,
+				FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+				Timeout:       5,
+				Threads:       len(targets),
+				ChunkSize:     tt.chunkSize,
+			}
+			r, err := NewRunner(options)
+			if err != nil {
+				t.Fatalf("创建Runner失败: %v", err)
+			}
+			if err := r.Run(options); err != nil {
+				t.Fatalf("Run 返回错误: %v", err)
+			}
+			for _, target := range targets {
+				if result := r.Results[target]; result == nil || result.Err != nil {
+					t.Errorf("目标 %s 未得到扫描结果: %+v", target, result)
+				}
+			}
+			if got := atomic.LoadInt64(&maxInFlight); got > tt.wantMax {
+				t.Errorf("同时处理的请求数 = %d，期望不超过 %d", got, tt.wantMax)
+			}
+			if tt.chunkSize == 0 && atomic.LoadInt64(&maxInFlight) < 2 {
+				t.Error("不分批时目标应并发扫描")
+			}
+		})
+	}
+}
+
+func TestNewScanConfig(t *testing.T) {
+	if _, err := NewScanConfig(nil); err == nil {
+		t.Error("参数为空时应返回错误")
+	}
+
+	invalid := []struct {
+		name   string
+		modify func(*types.CmdOptionsType)
+	}{
+		{"超时为负数", func(o *types.CmdOptionsType) { o.Timeout = -1 }},
+		{"探测重试次数为负数", func(o *types.CmdOptionsType) { o.ProbeRetries = -1 }},
+		{"https超时倍数为负数", func(o *types.CmdOptionsType) { o.HTTPSTimeoutFactor = -0.5 }},
+		{"指纹评估超时为负数", func(o *types.CmdOptionsType) { o.FingerprintTimeout = -1 }},
+		{"输出刷新间隔为负数", func(o *types.CmdOptionsType) { o.OutputFlushInterval = -1 }},
+		{"排除CDN但禁用Wappalyzer", func(o *types.CmdOptionsType) { o.ExcludeCDN, o.Wappalyzer = true, false }},
+		{"DNS缓存有效期为负数", func(o *types.CmdOptionsType) { o.DNSCacheTTL = -1 }},
+		{"分批大小为负数", func(o *types.CmdOptionsType) { o.ChunkSize = -1 }},
+		{"探测方法含非法字符", func(o *types.CmdOptionsType) { o.ProbeMethod = "GET /" }},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &types.CmdOptionsType{Wappalyzer: true}
+			tt.modify(options)
+			if _, err := NewScanConfig(options); err == nil {
+				t.Error("期望返回参数错误")
+			}
+		})
+	}
+
+	// 可修正的参数被钳制或规范化
+	config, err := NewScanConfig(&types.CmdOptionsType{
+		Wappalyzer:  true,
+		RuleThreads: MaxRuleWorkers + 1,
+		ProbeMethod: " head ",
+		PathPrefix:  " /app/ ",
+		IgnoreBody:  true,
+	})
+	if err != nil {
+		t.Fatalf("NewScanConfig 失败: %v", err)
+	}
+	if config.URLWorkerCount != DefaultURLWorkers {
+		t.Errorf("URLWorkerCount = %d，期望默认值 %d", config.URLWorkerCount, DefaultURLWorkers)
+	}
+	if config.FingerWorkerCount != MaxRuleWorkers {
+		t.Errorf("FingerWorkerCount = %d，期望钳制为 %d", config.FingerWorkerCount, MaxRuleWorkers)
+	}
+	if config.ProbeMethod != "HEAD" || config.PathPrefix != "/app" {
+		t.Errorf("ProbeMethod = %q，PathPrefix = %q，期望 HEAD 与 /app", config.ProbeMethod, config.PathPrefix)
+	}
+	if !config.HeaderOnlyMatch {
+		t.Error("--ignore-body 应同时启用仅响应头匹配")
+	}
+	if config, _ := NewScanConfig(&types.CmdOptionsType{Wappalyzer: true, RuleThreads: 1}); config.FingerWorkerCount != MinRuleWorkers {
+		t.Errorf("FingerWorkerCount = %d，期望钳制为 %d", config.FingerWorkerCount, MinRuleWorkers)
+	}
+}
+
+func TestRunKeepRaw(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "keep-raw-finger")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<title>raw</title>")
+	}))
+	defer srv.Close()
+
+	for _, keepRaw := range []bool{true, false} {
+		t.Run(strconv.FormatBool(keepRaw), func(t *testing.T) {
+			ClearAllCache()
+			options := &types.CmdOptionsType{
+				Target:        []string{srv.URL},
+				FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+				Timeout:       5,
+				KeepRaw:       keepRaw,
+			}
+			r, err := NewRunner(options)
+			if err != nil {
+				t.Fatalf("创建Runner失败: %v", err)
+			}
+			if err := r.Run(options); err != nil {
+				t.Fatalf("Run 返回错误: %v", err)
+			}
+			result := r.Results[srv.URL]
+			if result == nil || len(result.Matches) != 1 {
+				t.Fatalf("目标应命中 keep-raw-finger，实际 %+v", result)
+			}
+			// 输出后默认释放请求/响应，--keep-raw 时保留
+			if hasRaw := result.Matches[0].Response != nil && result.Matches[0].Request != nil; hasRaw != keepRaw {
+				t.Errorf("匹配结果保留请求响应 = %v，期望 %v", hasRaw, keepRaw)
+			}
+			if keepRaw && string(result.Matches[0].Response.Body) != "<title>raw</title>" {
+				t.Errorf("保留的响应体 = %q", result.Matches[0].Response.Body)
+			}
+		})
+	}
+}
+
+func TestShuffleTargets(t *testing.T) {
+	targets := make([]string, 20)
+	for i := range targets {
+		targets[i] = "http://host" + strconv.Itoa(i) + ".example.com"
+	}
+	input := append([]string(nil), targets...)
+
+	first := shuffleTargets(targets, 42)
+	// 不修改原切片
+	if strings.Join(targets, ",") != strings.Join(input, ",") {
+		t.Fatal("shuffleTargets 不应修改传入的目标切片")
+	}
+	if strings.Join(first, ",") == strings.Join(targets, ",") {
+		t.Error("打乱后的顺序不应与输入顺序一致")
+	}
+	sorted := append([]string(nil), first...)
+	sort.Strings(sorted)
+	want := append([]string(nil), targets...)
+	sort.Strings(want)
+	if strings.Join(sorted, ",") != strings.Join(want, ",") {
+		t.Errorf("打乱后的目标集合 = %v，期望与输入相同", first)
+	}
+
+	// 相同种子结果可复现，不同种子顺序不同
+	if second := shuffleTargets(targets, 42); strings.Join(second, ",") != strings.Join(first, ",") {
+		t.Errorf("相同种子的打乱结果 = %v，期望 %v", second, first)
+	}
+	if other := shuffleTargets(targets, 7); strings.Join(other, ",") == strings.Join(first, ",") {
+		t.Error("不同种子的打乱结果不应相同")
+	}
+}
+
+func TestRunOutputAppendID(t *testing.T) {
+	previous := GetAllFingerSnapshot()
+	defer func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	}()
+	fingerDir := t.TempDir()
+	writeFinger(t, fingerDir, "run-id-finger")
+
+	targets := make([]string, 3)
+	for i := range targets {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "<title>run-id</title>")
+		}))
+		defer srv.Close()
+		targets[i] = srv.URL
+	}
+
+	ClearAllCache()
+	defer output.SetRunID("")
+	outputPath := filepath.Join(t.TempDir(), "run-id.json")
+	options := &types.CmdOptionsType{
+		Target:         targets,
+		FingerOptions:  types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:        5,
+		Threads:        len(targets),
+		Output:         outputPath,
+		JSONOutput:     true,
+		OutputAppendID: true,
+	}
+	r, err := NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	if r.RunID == "" {
+		t.Fatal("启用 --output-append-id 时应生成运行ID")
+	}
+	if err := r.Run(options); err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if err := output.CloseFileOutput(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows := 0
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var row output.JSONOutput
+		if err := decoder.Decode(&row); err != nil {
+			t.Fatalf("解析输出失败: %v", err)
+		}
+		rows++
+		if row.RunID != r.RunID {
+			t.Errorf("%s 的运行ID = %q，期望本次运行共用 %q", row.URL, row.RunID, r.RunID)
+		}
+	}
+	if rows != len(targets) {
+		t.Errorf("输出记录 %d 条，期望 %d 条", rows, len(targets))
+	}
+	if other := newRunID(); other == r.RunID {
+		t.Errorf("不同运行的ID应不同，均为 %s", other)
+	}
+}
@@ -2,20 +2,99 @@ package runner
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"xfirefly/pkg/finger"
 	"xfirefly/pkg/output"
 	"xfirefly/pkg/types"
 	"xfirefly/pkg/utils/common"
+	"xfirefly/pkg/wappalyzer"
 
 	"github.com/donnie4w/go-logger/logger"
 )
 
-// getTargets 从命令行参数或文件中读取目标，并进行去重处理
+// getTargets 从命令行参数或文件中读取目标，进行去重、扩展名过滤处理并检查目标数量上限
 func getTargets(options *types.CmdOptionsType) ([]string, error) {
+	targets, err := loadTargets(options)
+	if err != nil {
+		return nil, err
+	}
+	targets = excludeExtensions(targets, options.ExcludeExtensions)
+	return limitTargets(targets, options.MaxTargets, options.TruncateTargets)
+}
+
+// limitTargets 检查目标数量是否超过上限，truncate 为 true 时截断，否则返回错误
+func limitTargets(targets []string, maxTargets int, truncate bool) ([]string, error) {
+	if maxTargets <= 0 || len(targets) <= maxTargets {
+		return targets, nil
+	}
+	if !truncate {
+		return nil, fmt.Errorf("目标数量 %d 超过上限 %d，可使用--max-targets-truncate截断", len(targets), maxTargets)
+	}
+	logger.Warnf("目标数量 %d 超过上限 %d，仅扫描前 %d 个目标", len(targets), maxTargets, maxTargets)
+	return targets[:maxTargets], nil
+}
+
+// excludeExtensions 过滤URL路径以指定扩展名结尾的目标，如静态资源 .js/.png/.css
+func excludeExtensions(targets []string, extensions []string) []string {
+	if len(extensions) == 0 {
+		return targets
+	}
+	excluded := make(map[string]struct{}, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		excluded[ext] = struct{}{}
+	}
+	if len(excluded) == 0 {
+		return targets
+	}
+
+	filtered := targets[:0]
+	for _, target := range targets {
+		if _, ok := excluded[targetExtension(target)]; ok {
+			continue
+		}
+		filtered = append(filtered, target)
+	}
+	if skipped := len(targets) - len(filtered); skipped > 0 {
+		logger.Infof("已按扩展名过滤目标：%v个，剩余目标数量：%v个", skipped, len(filtered))
+	}
+	return filtered
+}
+
+// targetExtension 获取目标URL路径的小写扩展名，忽略查询参数与锚点，无路径时返回空
+func targetExtension(target string) string {
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if i := strings.Index(target, "://"); i >= 0 {
+		target = target[i+3:]
+	}
+	i := strings.Index(target, "/")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(path.Ext(target[i:]))
+}
+
+// loadTargets 从命令行参数、文件或标准输入中读取目标，并进行去重处理
+func loadTargets(options *types.CmdOptionsType) ([]string, error) {
 	// 优先使用命令行直接指定的目标
 	if len(options.Target) > 0 {
 		// 记录原始目标数
@@ -28,12 +107,25 @@ func getTargets(options *types.CmdOptionsType) ([]string, error) {
 		return targets, nil
 	}
 
-	// 其次从文件读取（流式扫描，内存占用更低）
+	// 从历史JSON输出结果中读取目标
+	if options.InputJSON != "" {
+		file, err := os.Open(options.InputJSON)
+		if err != nil {
+			return nil, fmt.Errorf("读取JSON结果文件失败: %v", err)
+		}
+		defer func() { _ = file.Close() }()
+		return readJSONTargets(file)
+	}
+
+	// 未指定目标时从标准输入读取
 	if options.TargetsList == "" {
+		if options.StdinInput {
+			return readTargets(os.Stdin)
+		}
 		return nil, fmt.Errorf("目标文件为空")
 	}
 
-	// 读取文件内容
+	// 其次从文件读取（流式扫描，内存占用更低）
 	file, err := os.Open(options.TargetsList)
 	if err != nil {
 		//logger.Error(fmt.Sprintf("读取目标文件失败: %v", err))
@@ -41,12 +133,19 @@ func getTargets(options *types.CmdOptionsType) ([]string, error) {
 	}
 	defer func() { _ = file.Close() }()
 
-	scanner := bufio.NewScanner(file)
+	return readTargets(file)
+}
+
+// readTargets 按行读取目标并去重，空行会被忽略
+func readTargets(reader io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(reader)
 	// 提升扫描缓存，避免异常长行导致的扫描失败
 	buf := make([]byte, 0, 1024*1024)
 	scanner.Buffer(buf, 1024*1024)
 
 	unique := make(map[string]struct{}, 1024)
+	// 按首次出现顺序保存目标，保证输出顺序与输入一致
+	targets := make([]string, 0, 1024)
 	totalLines := 0
 	for scanner.Scan() {
 		// 移除字符串前后空白字符
@@ -59,16 +158,13 @@ func getTargets(options *types.CmdOptionsType) ([]string, error) {
 		totalLines++
 		if _, ok := unique[line]; !ok {
 			unique[line] = struct{}{}
+			targets = append(targets, line)
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		logger.Error(fmt.Sprintf("扫描目标文件出错: %v", err))
 	}
 
-	targets := make([]string, 0, len(unique))
-	for t := range unique {
-		targets = append(targets, t)
-	}
 	// 计算重复目标数量
 	duplicateCount := totalLines - len(targets)
 	logger.Info(fmt.Sprintf("原始目标数量：%v个，重复目标数量：%v个，去重后目标数量：%v个", totalLines, duplicateCount, len(targets)))
@@ -76,12 +172,51 @@ func getTargets(options *types.CmdOptionsType) ([]string, error) {
 	return targets, nil
 }
 
+// readJSONTargets 从JSON输出结果中提取url字段作为目标，兼容逐条写入的JSON对象流与JSON数组
+func readJSONTargets(reader io.Reader) ([]string, error) {
+	var urls []string
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("解析JSON结果文件失败: %v", err)
+		}
+
+		var records []output.JSONOutput
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+			if err := json.Unmarshal(raw, &records); err != nil {
+				return nil, fmt.Errorf("解析JSON结果文件失败: %v", err)
+			}
+		} else {
+			var record output.JSONOutput
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return nil, fmt.Errorf("解析JSON结果文件失败: %v", err)
+			}
+			records = append(records, record)
+		}
+		for _, record := range records {
+			if record.URL != "" {
+				urls = append(urls, record.URL)
+			}
+		}
+	}
+
+	// 复用按行读取的去重逻辑
+	return readTargets(strings.NewReader(strings.Join(urls, "\n")))
+}
+
 // ProcessURL 处理单个URL的所有指纹识别，获取目标基础信息并执行指纹识别
-func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult, error) {
+func ProcessURL(target string, config *ScanConfig) (*TargetResult, error) {
 	// 确保目标不为空
 	if target == "" {
 		return nil, fmt.Errorf("目标URL不能为空")
 	}
+	if config == nil {
+		config = &ScanConfig{ProbeRetries: defaultProbeRetries}
+	}
+	proxy := config.Proxy
 
 	// 创建目标结果对象，提前预分配
 	targetResult := &TargetResult{
@@ -93,13 +228,27 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 		Wappalyzer: nil,
 	}
 
-	// 获取目标基础信息
-	baseInfoResp, err := GetBaseInfo(target, proxy, timeout)
+	// 优先复用磁盘缓存中的基础信息，避免重复请求
+	baseInfoResp, lastRequest, lastResponse, cached := loadBaseInfoDiskCache(target, config)
+	if !cached {
+		// 获取目标基础信息
+		var err error
+		baseInfoResp, err = GetBaseInfo(target, config)
+
+		// 域名不存在时改用www/非www形式重试一次
+		if err != nil && config.TryWWW {
+			if alt, ok := wwwFallbackTarget(target, config.Proxy); ok {
+				logger.Infof("目标 %s 域名不存在，改用 %s 重试", target, alt)
+				baseInfoResp, err = GetBaseInfo(alt, config)
+			}
+		}
 
-	// 即使获取基础信息失败，也继续处理
-	if err != nil {
-		logger.Debug(fmt.Sprintf("获取目标 %s 基础信息失败: %v", target, err))
-		return targetResult, nil
+		// 即使获取基础信息失败，也继续处理
+		if err != nil {
+			logger.Debug(fmt.Sprintf("获取目标 %s 基础信息失败: %v", target, err))
+			targetResult.Err = err
+			return targetResult, nil
+		}
 	}
 
 	// 更新目标结果对象
@@ -108,14 +257,30 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 	targetResult.Server = baseInfoResp.Server
 	targetResult.Wappalyzer = baseInfoResp.Wappalyzer
 	targetResult.URL = baseInfoResp.Url
+	targetResult.RequiresAuth = baseInfoResp.RequiresAuth
+	if baseInfoResp.Response != nil {
+		targetResult.Security = finger.GetSecurityHeaders(baseInfoResp.Response.Header)
+	}
+	if config.TLSProbe {
+		targetResult.TLS = probeTLS(targetResult.URL, config)
+	}
 	logger.Debug(fmt.Sprintf("初始URL：%s", targetResult.URL))
 
+	// 目标位于CDN之后时，仅记录基础信息，跳过指纹识别
+	if config.ExcludeCDN && isBehindCDN(baseInfoResp.Wappalyzer) {
+		logger.Debugf("目标 %s 位于CDN之后，已跳过指纹识别", targetResult.URL)
+		return targetResult, nil
+	}
+
 	// 初始化缓存和变量映射
 	var variableMap = make(map[string]any, 4) // 预分配map容量
-	lastResponse, lastRequest := initializeCache(baseInfoResp, proxy)
 	if lastResponse == nil {
-		// 如果无法获取响应，直接返回
-		return targetResult, nil
+		lastResponse, lastRequest = initializeCache(baseInfoResp, proxy, config.probeMethod())
+		if lastResponse == nil {
+			// 如果无法获取响应，直接返回
+			return targetResult, nil
+		}
+		storeBaseInfoDiskCache(target, config, baseInfoResp, lastRequest, lastResponse)
 	}
 
 	variableMap["request"] = lastRequest
@@ -123,8 +288,12 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 
 	targetResult.LastRequest = lastRequest
 	targetResult.LastResponse = lastResponse
+	targetResult.IconHashes = lastResponse.IconHashes
 
-	UpdateTargetCache(variableMap, targetResult.URL, false)
+	// 忽略响应体时首页响应不完整，不写入请求缓存，避免规则复用空响应体
+	if !config.IgnoreBody {
+		UpdateTargetCache(variableMap, targetResult.URL, false, proxy)
+	}
 
 	// 创建基础信息对象
 	baseInfo := &BaseInfo{
@@ -134,22 +303,58 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 	}
 
 	// 如果没有指纹规则，直接返回结果
-	if len(AllFinger) == 0 {
+	if GetFingerCount() == 0 {
+		return targetResult, nil
+	}
+
+	// 主动识别前检测目标是否对任意路径返回相同页面，避免路径类指纹误报
+	if config.Active && detectWildcard(targetResult.URL, lastResponse.Body, config) {
+		logger.Infof("目标 %s 对任意路径返回相似内容，已忽略基于路径的指纹", targetResult.URL)
+		baseInfo.Wildcard = true
+		targetResult.Wildcard = true
+	}
+
+	// 被动识别时结果仅取决于首页响应，相同内容的目标直接复用已有结果
+	bodyCacheKey := ""
+	if !config.Active {
+		bodyCacheKey = GenerateBodyCacheKey(lastResponse, baseInfoResp.Wappalyzer)
+	}
+	if cached, ok := GetBodyCacheMatches(bodyCacheKey); ok {
+		logger.Debugf("目标 %s 响应内容与已识别目标一致，复用识别结果", targetResult.URL)
+		for _, m := range cached {
+			m.Request = lastRequest
+			m.Response = lastResponse
+		}
+		targetResult.Matches = cached
+		ClearTargetURLCache(targetResult.URL, proxy)
 		return targetResult, nil
 	}
 
 	// 执行指纹识别
-	matches := runFingerDetection(baseInfoResp.Url, baseInfo, proxy, timeout)
+	matches := runFingerDetection(baseInfoResp.Url, baseInfo, config)
 	targetResult.Matches = matches
+	StoreBodyCacheMatches(bodyCacheKey, matches)
 
 	// 指纹规则运行完成之后立即删除缓存，减少内存压力
-	ClearTargetURLCache(targetResult.URL)
+	ClearTargetURLCache(targetResult.URL, proxy)
 
 	return targetResult, nil
 }
 
+// isBehindCDN 根据Wappalyzer识别结果判断目标是否位于CDN之后
+// nginx、Envoy 等反向代理常直接部署在源站前，不视为CDN
+func isBehindCDN(wapp *wappalyzer.TypeWappalyzer) bool {
+	if wapp == nil {
+		return false
+	}
+	return len(wapp.CDN) > 0
+}
+
 // runFingerDetection 执行指纹识别，使用全局规则池高效处理指纹识别任务
-func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout int) []*FingerMatch {
+func runFingerDetection(target string, baseInfo *BaseInfo, config *ScanConfig) []*FingerMatch {
+	proxy := config.Proxy
+	timeout := config.Timeout
+
 	// 确保全局规则池已初始化
 	if !IsRulePoolInitialized() {
 		logger.Error("全局规则池未初始化")
@@ -185,18 +390,67 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 	// 统计实际提交的任务数
 	submittedTasks := int64(0)
 
+	// 开启首个命中即停止时，通过目标级上下文取消剩余任务
+	var targetCtx context.Context
+	cancelTarget := func() {}
+	if config.StopAtFirstMatch {
+		targetCtx, cancelTarget = context.WithCancel(context.Background())
+	}
+	headerOnlyCtx := targetCtx
+	if headerOnlyCtx == nil {
+		headerOnlyCtx = context.Background()
+	}
+	defer cancelTarget()
+
+	// 提交任务前启动结果收集协程，规则任务阻塞发送结果时始终有协程在消费（仅由单协程写入，无需互斥）
+	matches := make([]*FingerMatch, 0, ruleCount/4+1)
+	resultDone := make(chan struct{})
+
+	go func() {
+		defer close(resultDone)
+		for result := range resultChan {
+			if result != nil && result.Result {
+				matches = append(matches, result)
+				cancelTarget()
+			}
+		}
+	}()
+
+	// 仅依赖首页响应头的指纹直接在当前协程评估，结果最后合并
+	headerOnlyMatches := make([]*FingerMatch, 0)
+
 	// 提交所有指纹任务到全局规则池
 	for _, fingerprint := range localFingers {
+		if targetCtx != nil && targetCtx.Err() != nil {
+			logger.Debugf("目标 %s 已命中指纹，停止提交剩余指纹任务", target)
+			break
+		}
+		if baseInfo.Wildcard && isPathFinger(fingerprint) {
+			continue
+		}
+		if config.HeaderOnlyMatch && isHeaderOnlyFinger(fingerprint) {
+			match, err := evaluateFingerprintWithCache(headerOnlyCtx, fingerprint, target, baseInfo, proxy, timeout, config.Active)
+			if err != nil {
+				logger.Debugf("指纹 %s 响应头快速匹配失败: %v", fingerprint.Id, err)
+			} else if match != nil && match.Result {
+				headerOnlyMatches = append(headerOnlyMatches, match)
+				cancelTarget()
+			}
+			continue
+		}
+
 		wg.Add(1)
 
 		task := &RuleTask{
-			Target:     target,
-			Finger:     fingerprint,
-			BaseInfo:   baseInfo,
-			Proxy:      proxy,
-			Timeout:    timeout,
-			ResultChan: resultChan,
-			WaitGroup:  &wg,
+			Target:        target,
+			Finger:        fingerprint,
+			BaseInfo:      baseInfo,
+			Proxy:         proxy,
+			Timeout:       timeout,
+			FingerTimeout: config.FingerprintTimeout,
+			Ctx:           targetCtx,
+			ResultChan:    resultChan,
+			WaitGroup:     &wg,
 		}
 
 		// 简化重试机制，只在池满时重试一次
@@ -215,25 +469,13 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 		submittedTasks++
 	}
 
-	// 启动结果收集协程，避免阻塞主流程（仅由单协程写入，无需互斥）
-	matches := make([]*FingerMatch, 0, ruleCount/4+1)
-	resultDone := make(chan struct{})
-
-	go func() {
-		defer close(resultDone)
-		for result := range resultChan {
-			if result != nil && result.Result {
-				matches = append(matches, result)
-			}
-		}
-	}()
-
 	// 等待所有指纹任务完成
 	wg.Wait()
 	close(resultChan)
 
 	// 等待结果收集完成
 	<-resultDone
+	matches = append(matches, headerOnlyMatches...)
 
 	// 记录性能信息
 	duration := time.Since(startTime)
@@ -243,27 +485,153 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 	return matches
 }
 
+// severityRank 指纹等级排序权重，数值越小越靠前
+var severityRank = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+	"info":     4,
+}
+
+// getSeverityRank 获取指纹等级权重，未知等级排在最后
+func getSeverityRank(severity string) int {
+	if rank, ok := severityRank[strings.ToLower(strings.TrimSpace(severity))]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+// sortMatches 按指纹等级、指纹ID对匹配结果进行稳定排序，保证重要结果优先展示
+func sortMatches(matches []*FingerMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		ri := getSeverityRank(matches[i].Finger.Info.Severity)
+		rj := getSeverityRank(matches[j].Finger.Info.Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return matches[i].Finger.Id < matches[j].Finger.Id
+	})
+}
+
+// headerOnlyFields 响应头快速匹配允许引用的 response 字段
+var headerOnlyFields = map[string]bool{
+	"headers":      true,
+	"status":       true,
+	"content_type": true,
+	"raw_header":   true,
+	"url":          true,
+}
+
+// responseFieldRegex 匹配表达式中引用的 response 字段
+var responseFieldRegex = regexp.MustCompile(`\bresponse\.(\w+)`)
+
+// headerOnlyCache 缓存指纹是否仅依赖响应头的判断结果
+var headerOnlyCache sync.Map
+
+// isHeaderOnlyFinger 判断指纹是否仅依赖首页响应头、状态码、server和title，无需额外请求
+func isHeaderOnlyFinger(fg *finger.Finger) bool {
+	if cached, ok := headerOnlyCache.Load(fg); ok {
+		return cached.(bool)
+	}
+	result := checkHeaderOnlyFinger(fg)
+	headerOnlyCache.Store(fg, result)
+	return result
+}
+
+// checkHeaderOnlyFinger 检查指纹的全部规则是否为首页GET请求且表达式只引用响应头相关字段
+func checkHeaderOnlyFinger(fg *finger.Finger) bool {
+	if len(fg.Rules) == 0 || len(fg.Set) > 0 || len(fg.Payloads.Payloads) > 0 {
+		return false
+	}
+	for _, rule := range fg.Rules {
+		req := rule.Value.Request
+		reqType := strings.ToLower(req.Type)
+		if reqType != "" && reqType != common.HttpType {
+			return false
+		}
+		path := strings.TrimSpace(req.Path)
+		if (path != "" && path != "/") || req.Raw != "" || req.Body != "" || len(req.Headers) != 0 || len(req.Cookies) != 0 {
+			return false
+		}
+		if req.Method != "" && strings.ToUpper(req.Method) != "GET" {
+			return false
+		}
+		if len(rule.Value.Output) > 0 || len(rule.Value.Extractors) > 0 {
+			return false
+		}
+		for _, m := range rule.Value.Matchers {
+			if !m.HeaderOnly() {
+				return false
+			}
+		}
+		for _, expr := range append([]string{rule.Value.Expression}, rule.Value.Expressions...) {
+			for _, field := range responseFieldRegex.FindAllStringSubmatch(expr, -1) {
+				if !headerOnlyFields[field[1]] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
 // handleMatchResults 处理匹配结果，将结果输出到终端和文件
 func handleMatchResults(targetResult *TargetResult, options *types.CmdOptionsType, printResult func(string), outputFormat string) {
+	sortMatches(targetResult.Matches)
 	output.HandleMatchResults(&output.TargetResult{
-		URL:        targetResult.URL,
-		StatusCode: targetResult.StatusCode,
-		Title:      targetResult.Title,
-		ServerInfo: targetResult.Server,
-		Matches:    convertFingerMatches(targetResult.Matches),
-		Wappalyzer: targetResult.Wappalyzer,
+		URL:          targetResult.URL,
+		StatusCode:   targetResult.StatusCode,
+		Title:        targetResult.Title,
+		ServerInfo:   targetResult.Server,
+		Matches:      convertFingerMatches(targetResult.Matches),
+		Wappalyzer:   targetResult.Wappalyzer,
+		Security:     targetResult.Security,
+		TLS:          targetResult.TLS,
+		RequiresAuth: targetResult.RequiresAuth,
+		IconHashes:   targetResult.IconHashes,
+		Duration:     targetResult.Duration,
 	}, options.Output, options.SockOutput, printResult, outputFormat, targetResult.LastResponse)
 }
 
+// releaseRawData 释放结果中的请求/响应数据，结果输出后调用以降低常驻内存
+func releaseRawData(targetResult *TargetResult) {
+	for _, m := range targetResult.Matches {
+		m.Request = nil
+		m.Response = nil
+	}
+	targetResult.LastRequest = nil
+	targetResult.LastResponse = nil
+}
+
+// ToJSONOutput 将扫描结果转换为与JSON文件输出一致的结构
+func ToJSONOutput(targetResult *TargetResult) *output.JSONOutput {
+	return output.NewJSONOutput(output.CreateWriteOptions(&output.TargetResult{
+		URL:          targetResult.URL,
+		StatusCode:   targetResult.StatusCode,
+		Title:        targetResult.Title,
+		ServerInfo:   targetResult.Server,
+		Matches:      convertFingerMatches(targetResult.Matches),
+		Wappalyzer:   targetResult.Wappalyzer,
+		Security:     targetResult.Security,
+		TLS:          targetResult.TLS,
+		RequiresAuth: targetResult.RequiresAuth,
+		IconHashes:   targetResult.IconHashes,
+		Duration:     targetResult.Duration,
+	}, "", "json", targetResult.LastResponse))
+}
+
 // convertFingerMatches 将pkg.FingerMatch切片转换为output.FingerMatch切片
 func convertFingerMatches(matches []*FingerMatch) []*output.FingerMatch {
 	result := make([]*output.FingerMatch, len(matches))
 	for i, match := range matches {
 		result[i] = &output.FingerMatch{
-			Finger:   match.Finger,
-			Result:   match.Result,
-			Request:  match.Request,
-			Response: match.Response,
+			Finger:       match.Finger,
+			Result:       match.Result,
+			Request:      match.Request,
+			Response:     match.Response,
+			Extracted:    match.Extracted,
+			MatchedRules: match.MatchedRules,
 		}
 	}
 	return result
@@ -275,12 +643,17 @@ func printSummary(targets []string, results map[string]*TargetResult) {
 	outputResults := make(map[string]*output.TargetResult)
 	for key, result := range results {
 		outputResults[key] = &output.TargetResult{
-			URL:        result.URL,
-			StatusCode: result.StatusCode,
-			Title:      result.Title,
-			ServerInfo: result.Server,
-			Matches:    convertFingerMatches(result.Matches),
-			Wappalyzer: result.Wappalyzer,
+			URL:          result.URL,
+			StatusCode:   result.StatusCode,
+			Title:        result.Title,
+			ServerInfo:   result.Server,
+			Matches:      convertFingerMatches(result.Matches),
+			Wappalyzer:   result.Wappalyzer,
+			Security:     result.Security,
+			TLS:          result.TLS,
+			RequiresAuth: result.RequiresAuth,
+			IconHashes:   result.IconHashes,
+			Duration:     result.Duration,
 		}
 	}
 	output.PrintSummary(targets, outputResults)
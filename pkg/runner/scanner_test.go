@@ -0,0 +1,475 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/types"
+	"xfirefly/pkg/utils/proto"
+	"xfirefly/pkg/wappalyzer"
+)
+
+// useFingers 临时替换全局指纹，测试结束后恢复
+func useFingers(t *testing.T, fingers ...*finger.Finger) {
+	t.Helper()
+	previous := GetAllFingerSnapshot()
+	allFingerMutex.Lock()
+	AllFinger = fingers
+	allFingerMutex.Unlock()
+	t.Cleanup(func() {
+		allFingerMutex.Lock()
+		AllFinger = previous
+		allFingerMutex.Unlock()
+	})
+}
+
+// useRulePool 初始化测试使用的全局规则池，测试结束后释放
+func useRulePool(t *testing.T, workerCount int, fingerActive bool) {
+	t.Helper()
+	ReleaseRulePool()
+	if err := InitGlobalRulePool(workerCount, fingerActive); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ReleaseRulePool)
+}
+
+const sameBodyFinger = `
+id: same-body
+info:
+  name: same-body
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200 && response.body.bcontains(b"same-body")
+expression: r0()
+`
+
+func TestProcessURLReusesBodyResult(t *testing.T) {
+	const body = "<html><title>Same</title><body>same-body</body></html>"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, body)
+	})
+	first := httptest.NewServer(handler)
+	defer first.Close()
+	second := httptest.NewServer(handler)
+	defer second.Close()
+
+	useFingers(t, parseFinger(t, sameBodyFinger))
+	useRulePool(t, 2, false)
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(false)
+	ClearAllCache()
+	defer ClearAllCache()
+
+	config := &ScanConfig{Timeout: 5}
+	submitted := func(target string) int64 {
+		t.Helper()
+		before := GetRulePoolStats().TotalTasks
+		result, err := ProcessURL(target, config)
+		if err != nil {
+			t.Fatalf("处理目标 %s 失败: %v", target, err)
+		}
+		if len(result.Matches) != 1 || !result.Matches[0].Result || result.Matches[0].Finger.Id != "same-body" {
+			t.Errorf("目标 %s 应命中 same-body 指纹，实际 %v", target, result.Matches)
+		}
+		return GetRulePoolStats().TotalTasks - before
+	}
+
+	if n := submitted(first.URL); n != 1 {
+		t.Errorf("首个目标应提交 1 个指纹任务，实际 %d", n)
+	}
+	if n := submitted(second.URL); n != 0 {
+		t.Errorf("响应内容相同的目标应复用已有结果，实际提交 %d 个指纹任务", n)
+	}
+}
+
+func TestProcessURLStopAtFirstMatch(t *testing.T) {
+	// 首页立即命中 same-body，/slow 在客户端放弃前一直挂起，其余路径返回404以免被判定为泛解析
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "<html><body>same-body</body></html>")
+	}))
+	defer srv.Close()
+
+	useFingers(t, parseFinger(t, slowFinger), parseFinger(t, sameBodyFinger))
+	useRulePool(t, 2, true)
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(false)
+	ClearAllCache()
+	defer ClearAllCache()
+
+	start := time.Now()
+	result, err := ProcessURL(srv.URL, &ScanConfig{Timeout: 10, Active: true, StopAtFirstMatch: true})
+	if err != nil {
+		t.Fatalf("处理目标失败: %v", err)
+	}
+	// 命中后通过目标上下文中止挂起的请求，无需等待请求超时
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("处理耗时 %v，命中后未取消剩余指纹", elapsed)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Finger.Id != "same-body" {
+		t.Errorf("命中指纹 = %v，期望仅 same-body", result.Matches)
+	}
+}
+
+func TestProcessURLIgnoreBody(t *testing.T) {
+	var hits int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			atomic.AddInt64(&hits, 1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "<html><body>same-body</body></html>")
+	}))
+	defer srv.Close()
+
+	useFingers(t, parseFinger(t, sameBodyFinger))
+	useRulePool(t, 1, false)
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(false)
+	ClearAllCache()
+	defer ClearAllCache()
+
+	// 忽略响应体的首页响应不写入请求缓存，依赖响应体的规则重新请求首页
+	result, err := ProcessURL(srv.URL, &ScanConfig{Timeout: 5, IgnoreBody: true, HeaderOnlyMatch: true})
+	if err != nil {
+		t.Fatalf("处理目标失败: %v", err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Finger.Id != "same-body" {
+		t.Errorf("命中指纹 = %v，期望 same-body", result.Matches)
+	}
+	if got := atomic.LoadInt64(&hits); got != 2 {
+		t.Errorf("首页请求次数 = %d，期望 2", got)
+	}
+}
+
+func TestProcessURLOutputVariables(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "<html><title>Grafana</title><footer>Version 7.2.1</footer></html>")
+	}))
+	defer srv.Close()
+
+	useFingers(t, parseFinger(t, `
+id: output-finger
+info:
+  name: output-finger
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.body.bcontains(b"Grafana")
+    output:
+      product: '"grafana"'
+      version: '"Version (?P<ver>[0-9.]+)".bsubmatch(response.body)'
+expression: r0()
+`))
+	useRulePool(t, 1, false)
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(false)
+	ClearAllCache()
+	defer ClearAllCache()
+
+	result, err := ProcessURL(srv.URL, &ScanConfig{Timeout: 5})
+	if err != nil {
+		t.Fatalf("处理目标失败: %v", err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Finger.Id != "output-finger" {
+		t.Fatalf("命中指纹 = %v，期望 output-finger", result.Matches)
+	}
+	// 普通变量按名称记录，submatch 分组展开为 变量名.分组名
+	want := map[string]string{"product": "grafana", "version.ver": "7.2.1"}
+	if got := result.Matches[0].Extracted; !reflect.DeepEqual(got, want) {
+		t.Errorf("提取变量 = %v，期望 %v", got, want)
+	}
+
+	data, err := json.Marshal(ToJSONOutput(result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Extracted map[string]map[string]string `json:"extracted"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("解析JSON输出失败: %v", err)
+	}
+	if got := decoded.Extracted["output-finger"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON extracted = %s，期望按指纹ID记录 %v", data, want)
+	}
+}
+
+func TestSortMatches(t *testing.T) {
+	match := func(id, severity string) *FingerMatch {
+		return &FingerMatch{Finger: &finger.Finger{Id: id, Info: finger.Info{Severity: severity}}, Result: true}
+	}
+	matches := []*FingerMatch{
+		match("zeta", "info"),
+		match("beta", ""),
+		match("alpha", "INFO"),
+		match("gamma", " High "),
+		match("delta", "critical"),
+		match("eta", "unknown"),
+		match("epsilon", "medium"),
+	}
+	sortMatches(matches)
+
+	// 按等级从高到低排列，同等级按ID排序，未知等级排在最后
+	want := []string{"delta", "gamma", "epsilon", "alpha", "zeta", "beta", "eta"}
+	for i, m := range matches {
+		if m.Finger.Id != want[i] {
+			t.Fatalf("第 %d 个指纹 = %s，期望 %s", i, m.Finger.Id, want[i])
+		}
+	}
+}
+
+func TestReadTargetsKeepsInputOrder(t *testing.T) {
+	input := "http://c.example.com\n  http://a.example.com \n\nhttp://c.example.com\nhttp://b.example.com\n"
+	targets, err := readTargets(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("读取目标失败: %v", err)
+	}
+	want := []string{"http://c.example.com", "http://a.example.com", "http://b.example.com"}
+	if strings.Join(targets, ",") != strings.Join(want, ",") {
+		t.Errorf("目标 = %v，期望按首次出现顺序去重 %v", targets, want)
+	}
+}
+
+func TestReadJSONTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			"逐条写入的JSON对象",
+			"{\n  \"url\": \"http://a.example.com\",\n  \"status_code\": 200\n}\n{\"url\": \"http://b.example.com\"}\n",
+			[]string{"http://a.example.com", "http://b.example.com"},
+			false,
+		},
+		{
+			"JSON数组去重并跳过空url",
+			`[{"url":"http://b.example.com"},{"url":""},{"url":"http://a.example.com"},{"url":"http://b.example.com"}]`,
+			[]string{"http://b.example.com", "http://a.example.com"},
+			false,
+		},
+		{"空文件", "", nil, false},
+		{"格式错误", `{"url": "http://a.example.com"`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readJSONTargets(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readJSONTargets 错误 = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("目标 = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+
+	// --input-json 指定的文件作为目标来源
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := os.WriteFile(path, []byte(`{"url":"http://c.example.com"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := getTargets(&types.CmdOptionsType{InputJSON: path})
+	if err != nil || len(got) != 1 || got[0] != "http://c.example.com" {
+		t.Errorf("getTargets = %v（%v），期望 [http://c.example.com]", got, err)
+	}
+	if _, err := getTargets(&types.CmdOptionsType{InputJSON: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("JSON结果文件不存在时应返回错误")
+	}
+}
+
+func TestGetTargetsMaxTargets(t *testing.T) {
+	targets := []string{"http://a.example.com", "http://b.example.com", "http://c.example.com"}
+	tests := []struct {
+		name     string
+		max      int
+		truncate bool
+		want     int
+		wantErr  bool
+	}{
+		{"未设置上限", 0, false, 3, false},
+		{"未超过上限", 3, false, 3, false},
+		{"超过上限报错", 2, false, 0, true},
+		{"超过上限截断", 2, true, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getTargets(&types.CmdOptionsType{Target: targets, MaxTargets: tt.max, TruncateTargets: tt.truncate})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getTargets 错误 = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "--max-targets-truncate") {
+					t.Errorf("错误信息 %q 应提示截断参数", err)
+				}
+				return
+			}
+			if len(got) != tt.want {
+				t.Fatalf("目标数 = %d，期望 %d", len(got), tt.want)
+			}
+			// 截断时保留前面的目标
+			for i := range got {
+				if got[i] != targets[i] {
+					t.Errorf("第 %d 个目标 = %s，期望 %s", i, got[i], targets[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetTargetsExcludeExtensions(t *testing.T) {
+	targets := []string{
+		"http://a.example.com",
+		"http://a.example.com/static/app.JS",
+		"http://a.example.com/logo.png?v=1",
+		"http://a.example.com/index.php",
+		"http://a.example.com/download#file.css",
+		"cdn.example.com/theme.css",
+		"example.js",
+	}
+	original := append([]string(nil), targets...)
+
+	got, err := getTargets(&types.CmdOptionsType{Target: targets, ExcludeExtensions: []string{"js", " .PNG", "css", ""}})
+	if err != nil {
+		t.Fatalf("getTargets 失败: %v", err)
+	}
+	// 扩展名不区分大小写，忽略查询参数与锚点，无路径的主机名不按扩展名过滤
+	want := []string{
+		"http://a.example.com",
+		"http://a.example.com/index.php",
+		"http://a.example.com/download#file.css",
+		"example.js",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("过滤后目标 = %v，期望 %v", got, want)
+	}
+	if strings.Join(targets, ",") != strings.Join(original, ",") {
+		t.Errorf("过滤修改了传入的目标列表: %v", targets)
+	}
+}
+
+func TestCheckHeaderOnlyFinger(t *testing.T) {
+	// rule 生成只有一条规则的指纹，request 与 extra 为规则下的yaml内容
+	rule := func(request, extra string) string {
+		return "id: header-only\ninfo:\n  name: header-only\nrules:\n  r0:\n    request:\n" + request + extra + "expression: r0()\n"
+	}
+	const homeRequest = "      method: GET\n      path: /\n"
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"响应头与状态码", rule(homeRequest, "    expression: response.status == 200 && response.headers[\"server\"].contains(\"nginx\")\n"), true},
+		{"title与content_type", rule(homeRequest, "    expression: title.contains(\"Admin\") && response.content_type.contains(\"html\")\n"), true},
+		{"响应头匹配器", rule(homeRequest, "    matchers:\n      - type: word\n        part: header\n        words: [\"nginx\"]\n      - type: status\n        status: [200]\n"), true},
+		{"引用响应体", rule(homeRequest, "    expression: response.body.contains(\"nginx\")\n"), false},
+		{"响应体匹配器", rule(homeRequest, "    matchers:\n      - type: word\n        words: [\"nginx\"]\n"), false},
+		{"非首页路径", rule("      method: GET\n      path: /admin\n", "    expression: response.status == 200\n"), false},
+		{"POST请求", rule("      method: POST\n      path: /\n", "    expression: response.status == 200\n"), false},
+		{"自定义请求头", rule(homeRequest+"      headers:\n        X-Test: 1\n", "    expression: response.status == 200\n"), false},
+		{"TCP规则", rule("      type: tcp\n      host: \"{{hostname}}:22\"\n", "    expression: response.raw.bcontains(b\"SSH\")\n"), false},
+		{"输出变量", rule(homeRequest, "    expression: response.status == 200\n    output:\n      version: '\"1\"'\n"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkHeaderOnlyFinger(parseFinger(t, tt.content)); got != tt.want {
+				t.Errorf("checkHeaderOnlyFinger = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessURLExcludeCDN(t *testing.T) {
+	var probes int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/probe" {
+			atomic.AddInt64(&probes, 1)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	useFingers(t, parseFinger(t, `
+id: probe-finger
+info:
+  name: probe-finger
+rules:
+  r0:
+    request:
+      method: GET
+      path: /probe
+    expression: response.status == 200
+expression: r0()
+`))
+	useRulePool(t, 1, true)
+	finger.SetFaviconDisabled(true)
+	defer finger.SetFaviconDisabled(false)
+	if err := SetCacheDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetCacheDir("") }()
+
+	tests := []struct {
+		name      string
+		wapp      *wappalyzer.TypeWappalyzer
+		wantProbe bool
+	}{
+		{"CDN后的目标跳过指纹识别", &wappalyzer.TypeWappalyzer{CDN: []string{"Cloudflare"}}, false},
+		{"仅识别到反向代理的目标照常识别", &wappalyzer.TypeWappalyzer{ReverseProxies: []string{"Nginx"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearAllCache()
+			defer ClearAllCache()
+			atomic.StoreInt64(&probes, 0)
+
+			// 通过磁盘缓存提供带Wappalyzer识别结果的基础信息
+			config := &ScanConfig{Timeout: 5, Active: true, ExcludeCDN: true}
+			resp := &proto.Response{Status: 200}
+			resp.SetBody([]byte("<title>home</title>"))
+			base := &BaseInfoResponse{Url: srv.URL, StatusCode: 200, Wappalyzer: tt.wapp}
+			storeBaseInfoDiskCache(srv.URL, config, base, &proto.Request{Method: "GET"}, resp)
+
+			result, err := ProcessURL(srv.URL, config)
+			if err != nil {
+				t.Fatalf("处理目标失败: %v", err)
+			}
+			if result.StatusCode != 200 {
+				t.Errorf("基础信息状态码 = %d，期望 200", result.StatusCode)
+			}
+			probed := atomic.LoadInt64(&probes) > 0
+			if probed != tt.wantProbe || (len(result.Matches) == 1) != tt.wantProbe {
+				t.Errorf("规则请求 = %v，命中指纹 = %v，期望执行规则 = %v", probed, result.Matches, tt.wantProbe)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"net/http"
+	"time"
 	"xfirefly/pkg/finger"
 	"xfirefly/pkg/types"
 	"xfirefly/pkg/utils/proto"
@@ -16,6 +17,8 @@ type BaseInfoResponse struct {
 	StatusCode int32
 	Response   *http.Response
 	Wappalyzer *wappalyzer.TypeWappalyzer
+	// RequiresAuth 首页跳转到了登录/认证页面，认证后的内容未参与指纹识别
+	RequiresAuth bool
 	// BodyBytes 保存已读取的响应体字节，便于后续复用，避免重复读取与拷贝
 	BodyBytes []byte
 }
@@ -28,16 +31,25 @@ type TargetResult struct {
 	Server       *types.ServerInfo          // server信息
 	Matches      []*FingerMatch             // 匹配信息
 	Wappalyzer   *wappalyzer.TypeWappalyzer // 站点信息数据
+	Security     *types.SecurityHeaders     // 安全响应头分析结果
+	TLS          *types.TLSInfo             // TLS协议版本探测结果
+	Wildcard     bool                       // 目标对任意路径返回相似内容，基于路径的指纹已被忽略
+	RequiresAuth bool                       // 首页跳转到了登录/认证页面
+	IconHashes   []string                   // 首页候选icon的hash列表
+	Duration     time.Duration              // 该目标的扫描耗时
+	Err          error                      // 扫描失败原因，成功时为nil
 	LastRequest  *proto.Request             // 该URL的请求缓存
 	LastResponse *proto.Response            // 该URL的响应缓存
 }
 
 // FingerMatch 存储每个匹配的指纹信息
 type FingerMatch struct {
-	Finger   *finger.Finger  // 指纹信息
-	Result   bool            // 识别结果
-	Request  *proto.Request  // 请求数据
-	Response *proto.Response // 响应数据
+	Finger       *finger.Finger    // 指纹信息
+	Result       bool              // 识别结果
+	Request      *proto.Request    // 请求数据
+	Response     *proto.Response   // 响应数据
+	Extracted    map[string]string // 规则output中提取的变量
+	MatchedRules []string          // 命中的规则，格式为 "规则名: 表达式"
 }
 
 // BaseInfo 存储目标的基础信息
@@ -45,15 +57,51 @@ type BaseInfo struct {
 	Title      string
 	Server     *types.ServerInfo
 	StatusCode int32
+	Wildcard   bool // 目标对任意路径返回相似内容
 }
 
 // ScanConfig 存储扫描配置参数
 type ScanConfig struct {
-	Proxy             string // 代理配置
-	Timeout           int    // 超时配置
-	URLWorkerCount    int    // 请求线程数
-	FingerWorkerCount int    // 指纹检测线程数
-	OutputFormat      string // 输出格式
-	OutputFile        string // 输出文件
-	SockOutputFile    string // 输出sock文件
+	Proxy              string                 // 代理配置
+	Timeout            int                    // 超时配置
+	URLWorkerCount     int                    // 请求线程数
+	FingerWorkerCount  int                    // 指纹检测线程数
+	OutputFormat       string                 // 输出格式
+	OutputFile         string                 // 输出文件
+	SockOutputFile     string                 // 输出sock文件
+	ExcludeCDN         bool                   // 跳过CDN目标的指纹识别
+	ProbeMethod        string                 // 基础信息探测请求方法
+	ProbeRetries       int                    // 基础信息探测失败重试次数，0表示不重试
+	Active             bool                   // 是否启用主动指纹识别
+	RetryOnEmptyBody   bool                   // 基础信息探测返回空响应体时重试一次
+	Ordered            bool                   // 按输入顺序输出结果
+	HeaderOnlyMatch    bool                   // 仅依赖首页响应头的指纹直接基于基础信息评估，不进入规则池
+	IgnoreBody         bool                   // 基础信息探测不读取响应体，仅保留状态码与响应头
+	TryWWW             bool                   // 域名不存在时改用www/非www形式重试一次
+	TLSProbe           bool                   // 探测HTTPS目标接受的TLS协议版本
+	ChunkSize          int                    // 分批扫描的批次大小，0表示不分批
+	PathPrefix         string                 // 协议识别后追加到目标的路径
+	RandomizeTargets   bool                   // 打乱目标扫描顺序
+	Seed               int64                  // 打乱目标顺序使用的随机种子，0表示随机生成
+	Wappalyzer         *wappalyzer.Wappalyzer // 共享的Wappalyzer实例，为空时每次探测单独创建
+	NoWappalyzer       bool                   // 跳过Wappalyzer站点技术识别
+	FingerprintTimeout time.Duration          // 单个指纹评估的最长耗时，0表示不限制
+	KeepRaw            bool                   // 结果输出后保留请求/响应数据，供嵌入调用方使用
+	StopAtFirstMatch   bool                   // 目标命中首个指纹后停止评估剩余指纹
+}
+
+// probeMethod 返回基础信息探测使用的请求方法，未配置时默认GET
+func (c *ScanConfig) probeMethod() string {
+	if c == nil || c.ProbeMethod == "" {
+		return http.MethodGet
+	}
+	return c.ProbeMethod
+}
+
+// probeRetries 返回基础信息探测失败重试次数，0表示不重试，未配置扫描参数时默认3次
+func (c *ScanConfig) probeRetries() int {
+	if c == nil {
+		return defaultProbeRetries
+	}
+	return max(c.ProbeRetries, 0)
 }
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"xfirefly/pkg/finger"
+	"xfirefly/pkg/network"
+	"xfirefly/pkg/utils/common"
+
+	"github.com/donnie4w/go-logger/logger"
+)
+
+// wildcardSimilarity 随机路径响应体与首页响应体长度差异比例不超过该值时才进一步比较内容
+const wildcardSimilarity = 0.1
+
+// wildcardMaxDistance 随机路径响应体与首页响应体 simhash 汉明距离不超过该值时视为内容相似
+const wildcardMaxDistance = 3
+
+// detectWildcard 请求一个随机不存在的路径，若返回200且内容与首页相似，则认为目标对任意路径均返回相同页面
+func detectWildcard(target string, homeBody []byte, config *ScanConfig) bool {
+	probeURL := common.ParseTarget(target, "/"+common.RandomString(16))
+	timeout := requestTimeout(config)
+	// 上下文需在读取完响应体后再取消
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := network.SendRequestHttp(ctx, http.MethodGet, probeURL, "", network.OptionsRequest{
+		Proxy:           config.Proxy,
+		Timeout:         timeout,
+		FollowRedirects: false,
+	})
+	if err != nil {
+		logger.Debugf("目标 %s 通配检测请求失败: %v", target, err)
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
+	if err != nil {
+		return false
+	}
+	return similarBody(homeBody, body)
+}
+
+// similarBody 判断两个响应体是否相似，内容一致，或长度差异在阈值内且 simhash 接近时视为相似
+// 仅长度接近而内容不同的页面（如同一模板的不同页面）不视为相似
+func similarBody(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	longer := max(len(a), len(b))
+	diff := len(a) - len(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	if float64(diff)/float64(longer) > wildcardSimilarity {
+		return false
+	}
+	// 内容过短无法提取特征时 simhash 为0，无法判断相似度，按不相似处理
+	hashA, hashB := common.SimHash(a), common.SimHash(b)
+	if hashA == 0 || hashB == 0 {
+		return false
+	}
+	return common.HammingDistance(hashA, hashB) <= wildcardMaxDistance
+}
+
+// isPathFinger 判断指纹是否包含请求非首页路径的HTTP规则，此类指纹在通配目标上会产生误报
+func isPathFinger(fg *finger.Finger) bool {
+	for _, rule := range fg.Rules {
+		req := rule.Value.Request
+		reqType := strings.ToLower(req.Type)
+		if reqType != "" && reqType != common.HttpType {
+			continue
+		}
+		path := strings.TrimSpace(req.Path)
+		if path != "" && path != "/" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectWildcard(t *testing.T) {
+	home := "<html><head><title>首页</title></head><body>" + strings.Repeat("welcome to the home page. ", 64) + "</body></html>"
+
+	// 任意路径均返回首页，响应体分两次发送，确保读取响应体时请求仍在进行
+	catchAll := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		half := len(home) / 2
+		_, _ = io.WriteString(w, home[:half])
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, home[half:])
+	}))
+	defer catchAll.Close()
+	// 仅首页存在，其余路径返回404
+	normal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, home)
+	}))
+	defer normal.Close()
+
+	// 超时时间为0时使用默认超时
+	for _, timeout := range []int{5, 0} {
+		config := &ScanConfig{Timeout: timeout}
+		if !detectWildcard(catchAll.URL, []byte(home), config) {
+			t.Errorf("Timeout=%d 时任意路径均返回首页的目标应被识别为通配目标", timeout)
+		}
+		if detectWildcard(normal.URL, []byte(home), config) {
+			t.Errorf("Timeout=%d 时不存在的路径返回404的目标不应被识别为通配目标", timeout)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -14,6 +16,7 @@ import (
 // Pool 抽象的工作池接口，屏蔽对 ants 的直接依赖
 type Pool interface {
 	Invoke(i interface{}) error
+	Running() int
 	Release()
 }
 
@@ -23,6 +26,7 @@ type antsPoolWrapper struct {
 }
 
 func (p *antsPoolWrapper) Invoke(i interface{}) error { return p.inner.Invoke(i) }
+func (p *antsPoolWrapper) Running() int               { return p.inner.Running() }
 func (p *antsPoolWrapper) Release()                   { p.inner.Release() }
 
 // NewWorkPoolWithFunc 创建一个带函数处理器的工作池
@@ -53,9 +57,10 @@ func NewWorkPoolWithFunc(
 
 // GlobalRulePoolStats 全局规则池统计信息
 type GlobalRulePoolStats struct {
-	TotalTasks     int64 // 成功提交的总任务数
-	CompletedTasks int64 // 已完成任务数
-	FailedTasks    int64 // 失败任务数
+	TotalTasks     int64 // 成功提交的总任务数，任务结束后满足 TotalTasks == CompletedTasks + FailedTasks
+	CompletedTasks int64 // 已完成任务数（含目标取消后跳过的任务）
+	FailedTasks    int64 // 失败任务数（含执行出错、超时放弃与异常退出的任务）
+	TimedOutTasks  int64 // 超时放弃的任务数，已计入FailedTasks
 }
 
 var (
@@ -67,11 +72,15 @@ var (
 
 // RuleTask 规则处理任务结构（供调用方构造任务使用）
 type RuleTask struct {
-	Target     string
-	Finger     *finger.Finger
-	BaseInfo   *BaseInfo
-	Proxy      string
-	Timeout    int
+	Target   string
+	Finger   *finger.Finger
+	BaseInfo *BaseInfo
+	Proxy    string
+	Timeout  int
+	// FingerTimeout 单个指纹评估的最长耗时，超时后放弃该指纹并释放工作线程，0表示不限制
+	FingerTimeout time.Duration
+	// Ctx 目标级上下文，取消后尚未开始的任务直接跳过、正在评估的任务通过请求上下文中止，为空表示不可取消
+	Ctx        context.Context
 	ResultChan chan<- *FingerMatch // 结果通道
 	WaitGroup  *sync.WaitGroup     // 等待组
 }
@@ -87,10 +96,23 @@ func InitGlobalRulePool(workerCount int, fingerActive bool) error {
 			return
 		}
 
-		processRuleTask(task, fingerActive)
-
-		// 完成计数
-		atomic.AddInt64(&rulePoolStats.CompletedTasks, 1)
+		// 每个任务只计数一次，计数先于 WaitGroup 释放，等待结束后统计满足 TotalTasks == CompletedTasks + FailedTasks
+		completed := false
+		defer func() {
+			// 异常退出的任务在此捕获并计入失败
+			if r := recover(); r != nil {
+				logger.Errorf("规则池任务异常: %v", r)
+			}
+			if completed {
+				atomic.AddInt64(&rulePoolStats.CompletedTasks, 1)
+			} else {
+				atomic.AddInt64(&rulePoolStats.FailedTasks, 1)
+			}
+			if task.WaitGroup != nil {
+				task.WaitGroup.Done()
+			}
+		}()
+		completed = processRuleTask(task, fingerActive)
 	}
 
 	pool, err := NewWorkPoolWithFunc(
@@ -98,8 +120,8 @@ func InitGlobalRulePool(workerCount int, fingerActive bool) error {
 		handler,
 		workerCount*10,
 		2*time.Minute,
+		// 任务异常已由 handler 捕获计数，此处仅作兜底记录
 		func(i interface{}) {
-			atomic.AddInt64(&rulePoolStats.FailedTasks, 1)
 			logger.Errorf("规则池goroutine异常: %v", i)
 		},
 	)
@@ -128,10 +150,12 @@ func SubmitRuleTask(task *RuleTask) error {
 	if globalRulePool == nil {
 		return fmt.Errorf("全局规则池未初始化")
 	}
+	// 先计入总数，避免任务在计数前完成导致已完成数短暂超过总数
+	atomic.AddInt64(&rulePoolStats.TotalTasks, 1)
 	if err := globalRulePool.Invoke(task); err != nil {
+		atomic.AddInt64(&rulePoolStats.TotalTasks, -1)
 		return err
 	}
-	atomic.AddInt64(&rulePoolStats.TotalTasks, 1)
 	return nil
 }
 
@@ -141,7 +165,16 @@ func GetRulePoolStats() GlobalRulePoolStats {
 		TotalTasks:     atomic.LoadInt64(&rulePoolStats.TotalTasks),
 		CompletedTasks: atomic.LoadInt64(&rulePoolStats.CompletedTasks),
 		FailedTasks:    atomic.LoadInt64(&rulePoolStats.FailedTasks),
+		TimedOutTasks:  atomic.LoadInt64(&rulePoolStats.TimedOutTasks),
+	}
+}
+
+// GetRulePoolRunning 获取全局规则池中正在运行的工作线程数
+func GetRulePoolRunning() int {
+	if globalRulePool == nil {
+		return 0
 	}
+	return globalRulePool.Running()
 }
 
 // GetPoolStats 对外统一命名的统计获取函数（与对外API一致）
@@ -152,38 +185,112 @@ func ResetPoolStats() {
 	atomic.StoreInt64(&rulePoolStats.TotalTasks, 0)
 	atomic.StoreInt64(&rulePoolStats.CompletedTasks, 0)
 	atomic.StoreInt64(&rulePoolStats.FailedTasks, 0)
+	atomic.StoreInt64(&rulePoolStats.TimedOutTasks, 0)
 }
 
-// processRuleTask 处理单个规则识别任务
-func processRuleTask(task *RuleTask, fingerActive bool) {
-	defer func() {
-		if task.WaitGroup != nil {
-			task.WaitGroup.Done()
-		}
-	}()
+// processRuleTask 处理单个规则识别任务，执行出错或超时放弃时返回false，WaitGroup 由规则池 handler 在计数后释放
+func processRuleTask(task *RuleTask, fingerActive bool) bool {
+	// 目标已取消（如已命中首个指纹）时跳过尚未开始的任务
+	if task.Ctx != nil && task.Ctx.Err() != nil {
+		logger.Debugf("目标 %s 已停止识别，跳过指纹 %s", task.Target, task.Finger.Id)
+		return true
+	}
 
 	// 执行指纹识别
-	result, err := evaluateFingerprintWithCache(
-		task.Finger,
-		task.Target,
-		task.BaseInfo,
-		task.Proxy,
-		task.Timeout,
-		fingerActive,
-	)
+	result, err := evaluateFingerprintWithTimeout(task, fingerActive)
 
+	if errors.Is(err, context.Canceled) {
+		logger.Debugf("目标 %s 已停止识别，放弃指纹 %s", task.Target, task.Finger.Id)
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		atomic.AddInt64(&rulePoolStats.TimedOutTasks, 1)
+		logger.Warnf("指纹 %s 评估超过 %v，已放弃", task.Finger.Id, task.FingerTimeout)
+		return false
+	}
 	if err != nil {
-		atomic.AddInt64(&rulePoolStats.FailedTasks, 1)
 		logger.Warnf("规则 %s 执行失败: %v", task.Finger.Id, err)
-		return
+		return false
 	}
 
-	// 只有匹配成功的结果才发送到结果通道
+	// 只有匹配成功的结果才发送到结果通道，通道在所有任务完成前不会关闭且收集协程持续消费，阻塞发送不会丢失结果
 	if result != nil && result.Result {
-		select {
-		case task.ResultChan <- result:
-		default:
-			logger.Debugf("结果通道已满，丢弃规则 %s 的结果", task.Finger.Id)
-		}
+		task.ResultChan <- result
+	}
+	return true
+}
+
+// evaluateFingerprintWithTimeout 在限定时间内评估单个指纹，超时或目标被取消时通过请求上下文中止正在发送的请求，
+// 并返回上下文的错误，已得到的评估结果被丢弃
+func evaluateFingerprintWithTimeout(task *RuleTask, fingerActive bool) (*FingerMatch, error) {
+	if task.FingerTimeout <= 0 && task.Ctx == nil {
+		return evaluateFingerprintWithCache(context.Background(), task.Finger, task.Target, task.BaseInfo, task.Proxy, task.Timeout, fingerActive)
+	}
+
+	parent := task.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if task.FingerTimeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, task.FingerTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+
+	match, err := evaluateFingerprintWithCache(ctx, task.Finger, task.Target, task.BaseInfo, task.Proxy, task.Timeout, fingerActive)
+	// 请求被中止时规则按未命中处理，结果不可信，统一返回上下文错误
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return match, err
+}
+
+// ===================== 目标扫描统计 =====================
+
+// ScanStats 目标扫描统计信息
+type ScanStats struct {
+	ScannedTargets int64 // 已完成扫描的目标数
+	FailedTargets  int64 // 扫描失败的目标数
+	MatchedTargets int64 // 命中指纹的目标数
+	TotalMatches   int64 // 命中指纹总数
+	InFlight       int64 // 正在扫描的目标数
+}
+
+// scanStats 全局目标扫描统计
+var scanStats ScanStats
+
+// GetScanStats 获取目标扫描统计信息
+func GetScanStats() ScanStats {
+	return ScanStats{
+		ScannedTargets: atomic.LoadInt64(&scanStats.ScannedTargets),
+		FailedTargets:  atomic.LoadInt64(&scanStats.FailedTargets),
+		MatchedTargets: atomic.LoadInt64(&scanStats.MatchedTargets),
+		TotalMatches:   atomic.LoadInt64(&scanStats.TotalMatches),
+		InFlight:       atomic.LoadInt64(&scanStats.InFlight),
+	}
+}
+
+// scanTargetWithStats 扫描单个目标并记录统计信息
+func scanTargetWithStats(target string, config *ScanConfig) (*TargetResult, error) {
+	atomic.AddInt64(&scanStats.InFlight, 1)
+	defer atomic.AddInt64(&scanStats.InFlight, -1)
+
+	targetResult, err := ProcessURL(target, config)
+	atomic.AddInt64(&scanStats.ScannedTargets, 1)
+	if err != nil {
+		atomic.AddInt64(&scanStats.FailedTargets, 1)
+		return nil, err
+	}
+	// 基础信息获取失败的目标同样计入失败
+	if targetResult.Err != nil {
+		atomic.AddInt64(&scanStats.FailedTargets, 1)
+	}
+	if len(targetResult.Matches) > 0 {
+		atomic.AddInt64(&scanStats.MatchedTargets, 1)
+		atomic.AddInt64(&scanStats.TotalMatches, int64(len(targetResult.Matches)))
 	}
+	return targetResult, nil
 }
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const slowFinger = `
+id: slow-finger
+info:
+  name: slow-finger
+rules:
+  r0:
+    request:
+      method: GET
+      path: /slow
+    expression: response.status == 200
+expression: r0()
+`
+
+// waitTimeout 等待wg结束，超过 timeout 返回false
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestRuleTaskFingerTimeout(t *testing.T) {
+	// 请求在客户端放弃前一直挂起
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	// 主动模式下才会请求首页以外的路径
+	useRulePool(t, 1, true)
+	ResetPoolStats()
+	t.Cleanup(ResetPoolStats)
+
+	fg := parseFinger(t, slowFinger)
+	const tasks = 3
+	var wg sync.WaitGroup
+	resultChan := make(chan *FingerMatch, tasks)
+	start := time.Now()
+	for i := 0; i < tasks; i++ {
+		wg.Add(1)
+		err := SubmitRuleTask(&RuleTask{
+			Target:        srv.URL,
+			Finger:        fg,
+			BaseInfo:      &BaseInfo{StatusCode: 200},
+			Timeout:       10,
+			FingerTimeout: 200 * time.Millisecond,
+			ResultChan:    resultChan,
+			WaitGroup:     &wg,
+		})
+		if err != nil {
+			t.Fatalf("提交任务失败: %v", err)
+		}
+	}
+
+	// 单个工作线程依次放弃超时的指纹，不会被挂起的请求阻塞
+	if !waitTimeout(&wg, 5*time.Second) {
+		t.Fatal("超时的指纹未被放弃，规则池被阻塞")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("任务耗时 %v，超时未及时生效", elapsed)
+	}
+
+	stats := GetRulePoolStats()
+	if stats.TimedOutTasks != tasks {
+		t.Errorf("TimedOutTasks = %d，期望 %d", stats.TimedOutTasks, tasks)
+	}
+	if stats.FailedTasks != tasks || stats.CompletedTasks != 0 {
+		t.Errorf("FailedTasks = %d，CompletedTasks = %d，期望 %d 与 0", stats.FailedTasks, stats.CompletedTasks, tasks)
+	}
+	if len(resultChan) != 0 {
+		t.Error("超时放弃的指纹不应输出结果")
+	}
+}
+
+func TestRulePoolStatsReconcile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	useRulePool(t, 4, true)
+	ResetPoolStats()
+	t.Cleanup(ResetPoolStats)
+
+	okFinger := parseFinger(t, sameBodyFinger)
+	slow := parseFinger(t, slowFinger)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var wg sync.WaitGroup
+	resultChan := make(chan *FingerMatch, 16)
+	tasks := []*RuleTask{
+		// 正常完成
+		{Finger: okFinger},
+		{Finger: okFinger},
+		// 超时放弃，计入失败
+		{Finger: slow, FingerTimeout: 100 * time.Millisecond},
+		// 目标已取消，跳过计入完成
+		{Finger: slow, Ctx: canceled},
+		// 指纹为空导致异常退出，由panic处理函数计入失败
+		{Finger: nil},
+	}
+	for _, task := range tasks {
+		task.Target = srv.URL
+		task.BaseInfo = &BaseInfo{StatusCode: 200}
+		task.Timeout = 5
+		task.ResultChan = resultChan
+		task.WaitGroup = &wg
+		wg.Add(1)
+		if err := SubmitRuleTask(task); err != nil {
+			t.Fatalf("提交任务失败: %v", err)
+		}
+	}
+	if !waitTimeout(&wg, 5*time.Second) {
+		t.Fatal("任务未在期限内结束")
+	}
+
+	// 计数先于 WaitGroup 释放，等待结束后统计即已收敛
+	stats := GetRulePoolStats()
+	if stats.TotalTasks != int64(len(tasks)) {
+		t.Errorf("TotalTasks = %d，期望 %d", stats.TotalTasks, len(tasks))
+	}
+	if stats.TotalTasks != stats.CompletedTasks+stats.FailedTasks {
+		t.Errorf("TotalTasks = %d，CompletedTasks + FailedTasks = %d + %d，期望相等", stats.TotalTasks, stats.CompletedTasks, stats.FailedTasks)
+	}
+	if stats.FailedTasks != 2 || stats.TimedOutTasks != 1 {
+		t.Errorf("FailedTasks = %d，TimedOutTasks = %d，期望 2 与 1", stats.FailedTasks, stats.TimedOutTasks)
+	}
+}
+
+func TestProcessRuleTaskKeepsResultWhenChannelFull(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ClearAllCache()
+	defer ClearAllCache()
+
+	fg := parseFinger(t, `
+id: status-ok
+info:
+  name: status-ok
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200
+expression: r0()
+`)
+
+	// 无缓冲通道，消费方稍后才开始读取
+	resultChan := make(chan *FingerMatch)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		processRuleTask(&RuleTask{
+			Target:     srv.URL,
+			Finger:     fg,
+			BaseInfo:   &BaseInfo{StatusCode: 200},
+			Timeout:    5,
+			ResultChan: resultChan,
+		}, false)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case result := <-resultChan:
+		if result == nil || !result.Result {
+			t.Errorf("收到的结果 = %+v，期望命中", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("结果通道已满时命中结果被丢弃")
+	}
+	<-done
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"xfirefly/pkg/runner"
+)
+
+// healthResponse 健康检查响应
+type healthResponse struct {
+	Status  string `json:"status"`
+	Fingers int    `json:"fingers"`
+}
+
+// handleHealthz 处理 GET /healthz 健康检查，扫描器未运行或未加载指纹时返回503
+func (s *Server) handleHealthz(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "仅支持GET请求")
+		return
+	}
+
+	fingers := runner.GetFingerCount()
+	if !s.runner.IsRunning() || fingers == 0 {
+		writeJSON(w, http.StatusServiceUnavailable, &healthResponse{Status: "unavailable", Fingers: fingers})
+		return
+	}
+	writeJSON(w, http.StatusOK, &healthResponse{Status: "ok", Fingers: fingers})
+}
+
+// handleMetrics 处理 GET /metrics，以Prometheus文本格式输出扫描与规则池统计
+func (s *Server) handleMetrics(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "仅支持GET请求")
+		return
+	}
+
+	scanStats := runner.GetScanStats()
+	poolStats := runner.GetRulePoolStats()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	writeMetric(w, "xfirefly_scans_completed_total", "counter", "已完成扫描的目标数", scanStats.ScannedTargets)
+	writeMetric(w, "xfirefly_scan_errors_total", "counter", "扫描失败的目标数", scanStats.FailedTargets)
+	writeMetric(w, "xfirefly_matched_targets_total", "counter", "命中指纹的目标数", scanStats.MatchedTargets)
+	writeMetric(w, "xfirefly_matches_total", "counter", "命中指纹总数", scanStats.TotalMatches)
+	writeMetric(w, "xfirefly_scans_in_flight", "gauge", "正在扫描的目标数", scanStats.InFlight)
+	writeMetric(w, "xfirefly_rule_tasks_total", "counter", "提交到规则池的任务数", poolStats.TotalTasks)
+	writeMetric(w, "xfirefly_rule_tasks_completed_total", "counter", "规则池已完成任务数", poolStats.CompletedTasks)
+	writeMetric(w, "xfirefly_rule_tasks_failed_total", "counter", "规则池失败任务数", poolStats.FailedTasks)
+	writeMetric(w, "xfirefly_rule_tasks_timed_out_total", "counter", "规则池超时放弃的任务数", poolStats.TimedOutTasks)
+	writeMetric(w, "xfirefly_rule_workers_in_flight", "gauge", "规则池正在运行的工作线程数", int64(runner.GetRulePoolRunning()))
+	writeMetric(w, "xfirefly_fingers_loaded", "gauge", "当前加载的指纹数量", int64(runner.GetFingerCount()))
+}
+
+// writeMetric 按Prometheus文本格式写入单个指标
+func writeMetric(w io.Writer, name, metricType, help string, value int64) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHandleHealthz(t *testing.T) {
+	r := newTestRunner(t, false)
+	srv := httptest.NewServer(NewServer(r).Handler())
+	defer srv.Close()
+
+	check := func(want int, wantStatus string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/healthz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("状态码 = %d，期望 %d", resp.StatusCode, want)
+		}
+		var health healthResponse
+		if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if health.Status != wantStatus {
+			t.Errorf("status = %q，期望 %q", health.Status, wantStatus)
+		}
+	}
+
+	// 扫描器未运行
+	check(http.StatusServiceUnavailable, "unavailable")
+
+	// 启动后已加载指纹
+	if err := r.Start(newTestOptions(t)); err != nil {
+		t.Fatalf("启动Runner失败: %v", err)
+	}
+	check(http.StatusOK, "ok")
+
+	// 停止后恢复为不可用
+	r.Stop()
+	check(http.StatusServiceUnavailable, "unavailable")
+}
+
+// readMetrics 读取 /metrics 输出，返回指标值与声明了 HELP、TYPE 的指标名
+func readMetrics(t *testing.T, url string) (map[string]int64, map[string]string) {
+	t.Helper()
+	resp, err := http.Get(url + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("状态码 = %d，期望 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q，期望Prometheus文本格式", ct)
+	}
+
+	values := make(map[string]int64)
+	metricTypes := make(map[string]string)
+	helps := make(map[string]bool)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			helps[fields[2]] = true
+		case strings.HasPrefix(line, "# TYPE "):
+			metricTypes[fields[2]] = fields[3]
+		default:
+			if len(fields) != 2 {
+				t.Fatalf("无法解析的指标行: %q", line)
+			}
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				t.Fatalf("指标 %s 的值无效: %v", fields[0], err)
+			}
+			if !helps[fields[0]] || metricTypes[fields[0]] == "" {
+				t.Errorf("指标 %s 缺少 HELP 或 TYPE 声明", fields[0])
+			}
+			values[fields[0]] = value
+		}
+	}
+	return values, metricTypes
+}
+
+func TestHandleMetrics(t *testing.T) {
+	target := newMarkerTarget(t)
+	srv := httptest.NewServer(NewServer(newTestRunner(t, true)).Handler())
+	defer srv.Close()
+
+	before, metricTypes := readMetrics(t, srv.URL)
+	wantTypes := map[string]string{
+		"xfirefly_scans_completed_total":      "counter",
+		"xfirefly_scan_errors_total":          "counter",
+		"xfirefly_matched_targets_total":      "counter",
+		"xfirefly_matches_total":              "counter",
+		"xfirefly_scans_in_flight":            "gauge",
+		"xfirefly_rule_tasks_total":           "counter",
+		"xfirefly_rule_tasks_completed_total": "counter",
+		"xfirefly_rule_tasks_failed_total":    "counter",
+		"xfirefly_rule_tasks_timed_out_total": "counter",
+		"xfirefly_rule_workers_in_flight":     "gauge",
+		"xfirefly_fingers_loaded":             "gauge",
+	}
+	for name, want := range wantTypes {
+		if got := metricTypes[name]; got != want {
+			t.Errorf("指标 %s 类型 = %q，期望 %q", name, got, want)
+		}
+	}
+
+	// 扫描一个命中目标与一个不可达目标
+	resp, err := http.Post(srv.URL+"/scan", "application/json",
+		strings.NewReader(`{"targets":["`+target.URL+`","http://127.0.0.1:1"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	after, _ := readMetrics(t, srv.URL)
+	deltas := map[string]int64{
+		"xfirefly_scans_completed_total": 2,
+		"xfirefly_scan_errors_total":     1,
+		"xfirefly_matched_targets_total": 1,
+		"xfirefly_matches_total":         1,
+	}
+	for name, want := range deltas {
+		if got := after[name] - before[name]; got != want {
+			t.Errorf("%s 增加 %d，期望 %d", name, got, want)
+		}
+	}
+	if after["xfirefly_scans_in_flight"] != 0 {
+		t.Errorf("扫描结束后 xfirefly_scans_in_flight = %d，期望 0", after["xfirefly_scans_in_flight"])
+	}
+	if after["xfirefly_fingers_loaded"] != 1 {
+		t.Errorf("xfirefly_fingers_loaded = %d，期望 1", after["xfirefly_fingers_loaded"])
+	}
+	if got := after["xfirefly_rule_tasks_total"]; got != after["xfirefly_rule_tasks_completed_total"]+after["xfirefly_rule_tasks_failed_total"] {
+		t.Errorf("规则池任务总数 %d 与已完成、失败数之和不一致", got)
+	}
+}
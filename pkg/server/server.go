@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"xfirefly/pkg/output"
+	"xfirefly/pkg/runner"
+	"xfirefly/pkg/utils/common"
+
+	"github.com/donnie4w/go-logger/logger"
+)
+
+// maxRequestBodySize 扫描请求体大小上限
+const maxRequestBodySize = 10 << 20
+
+// Server 以HTTP接口对外提供指纹识别服务
+type Server struct {
+	runner *runner.Runner // 已启动的扫描器
+	mux    *http.ServeMux // 路由
+}
+
+// ScanRequest 扫描请求
+type ScanRequest struct {
+	Targets []string `json:"targets"`
+}
+
+// ScanResponse 扫描响应，结果与JSON文件输出格式一致
+type ScanResponse struct {
+	Results []*output.JSONOutput `json:"results"`
+}
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer 基于已启动的扫描器创建HTTP服务
+func NewServer(r *runner.Runner) *Server {
+	s := &Server{
+		runner: r,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/scan", s.handleScan)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	return s
+}
+
+// Handler 返回服务的HTTP处理器
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe 监听指定地址并提供服务，阻塞直到服务退出
+func (s *Server) ListenAndServe(addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logger.Infof("HTTP服务已启动，监听地址：%s", addr)
+	return httpServer.ListenAndServe()
+}
+
+// handleScan 处理 POST /scan 扫描请求
+func (s *Server) handleScan(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "仅支持POST请求")
+		return
+	}
+
+	var scanReq ScanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestBodySize))
+	if err := decoder.Decode(&scanReq); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("请求体解析失败: %v", err))
+		return
+	}
+
+	// 去除空白与重复目标
+	targets := common.RemoveDuplicateURLs(scanReq.Targets)
+	if len(targets) == 0 {
+		writeError(w, http.StatusBadRequest, "targets不能为空")
+		return
+	}
+
+	results, err := s.runner.ScanTargets(targets)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &ScanResponse{Results: make([]*output.JSONOutput, 0, len(results))}
+	for _, result := range results {
+		resp.Results = append(resp.Results, runner.ToJSONOutput(result))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debugf("写入响应失败: %v", err)
+	}
+}
+
+// writeError 写入错误响应
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &errorResponse{Error: msg})
+}
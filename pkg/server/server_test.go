@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"xfirefly/pkg/runner"
+	"xfirefly/pkg/types"
+)
+
+const markerFinger = `
+id: server-marker
+info:
+  name: server-marker
+rules:
+  r0:
+    request:
+      method: GET
+      path: /
+    expression: response.status == 200 && response.body.bcontains(b"server-marker")
+expression: r0()
+`
+
+// newTestOptions 返回仅加载测试指纹的扫描参数
+func newTestOptions(t *testing.T) *types.CmdOptionsType {
+	t.Helper()
+	fingerDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(fingerDir, "server-marker.yaml"), []byte(markerFinger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &types.CmdOptionsType{
+		FingerOptions: types.YamlFingerType{FingerPath: fingerDir},
+		Timeout:       5,
+	}
+}
+
+// newTestRunner 创建使用测试指纹的扫描器，start 为 true 时启动扫描器，测试结束后停止
+func newTestRunner(t *testing.T, start bool) *runner.Runner {
+	t.Helper()
+	options := newTestOptions(t)
+	r, err := runner.NewRunner(options)
+	if err != nil {
+		t.Fatalf("创建Runner失败: %v", err)
+	}
+	t.Cleanup(r.Stop)
+	if start {
+		if err := r.Start(options); err != nil {
+			t.Fatalf("启动Runner失败: %v", err)
+		}
+	}
+	return r
+}
+
+// newMarkerTarget 创建首页包含指纹特征的目标站点
+func newMarkerTarget(t *testing.T) *httptest.Server {
+	t.Helper()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "<html><title>Marker</title><body>server-marker</body></html>")
+	}))
+	t.Cleanup(target.Close)
+	return target
+}
+
+func TestHandleScan(t *testing.T) {
+	target := newMarkerTarget(t)
+	srv := httptest.NewServer(NewServer(newTestRunner(t, true)).Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/scan", "application/json", strings.NewReader(`{"targets":["`+target.URL+`"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("状态码 = %d，期望 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q，期望 application/json", ct)
+	}
+	var scanResp ScanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&scanResp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if len(scanResp.Results) != 1 {
+		t.Fatalf("结果数量 = %d，期望 1", len(scanResp.Results))
+	}
+	result := scanResp.Results[0]
+	if result.URL != target.URL || result.StatusCode != http.StatusOK || result.Title != "Marker" {
+		t.Errorf("结果 = %+v，期望 %s 的首页信息", result, target.URL)
+	}
+	if !result.MatchResult || len(result.FingerIDs) != 1 || result.FingerIDs[0] != "server-marker" {
+		t.Errorf("命中指纹 = %v，期望 [server-marker]", result.FingerIDs)
+	}
+}
+
+func TestHandleScanBadRequest(t *testing.T) {
+	srv := httptest.NewServer(NewServer(newTestRunner(t, true)).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"非POST请求", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"请求体不是JSON", http.MethodPost, "targets", http.StatusBadRequest},
+		{"目标为空", http.MethodPost, `{"targets":[" "]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+"/scan", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("状态码 = %d，期望 %d", resp.StatusCode, tt.want)
+			}
+			var errResp errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+				t.Errorf("错误响应应包含 error 字段，解析错误: %v", err)
+			}
+		})
+	}
+}
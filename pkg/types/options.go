@@ -8,24 +8,80 @@ type YamlFingerType struct {
 
 // CmdOptionsType 命令行选项结构体
 type CmdOptionsType struct {
-	Target        []string       // 测试目标
-	TargetsList   string         // 测试目标文件
-	Output        string         // 输出文件路径
-	JSONOutput    bool           // 是否使用JSON格式输出结果
-	SockOutput    string         // socket文件输出路径，启用后会以JSON格式输出到socket文件
-	Proxy         string         // 代理地址
-	Threads       int            // 并发线程数
-	RuleThreads   int            // 指纹规则线程数
-	Timeout       int            // 超时时间，默认5秒
-	Retries       int            // 重试次数，默认1次
-	MaxRedirects  int            // 最大跳转次数，默认5次
-	Debug         bool           // 设置debug模式
-	NoTimestamp   bool           // 输出时间戳
-	FileLog       bool           // 是否禁用文件日志，仅输出到控制台
-	FingerOptions YamlFingerType // Finger yaml文件配置
-	Active        bool           // 主动指纹探测
-	InitConfig    bool           // 初始化配置文件
-	PrintPreset   bool           // 打印预配置
-	Config        string         // 指定配置文件
-	Version       bool           // 打印版本信息
+	Target                []string       // 测试目标
+	TargetsList           string         // 测试目标文件
+	StdinInput            bool           // 未指定目标时从标准输入读取目标
+	InputJSON             string         // 从历史JSON输出结果中读取目标
+	ExcludeExtensions     []string       // 跳过URL路径为指定扩展名的目标
+	MaxTargets            int            // 最大目标数量，0表示不限制
+	PathPrefix            string         // 协议识别后追加到每个目标的路径
+	ChunkSize             int            // 分批扫描的批次大小，0表示不分批
+	TruncateTargets       bool           // 目标数量超过上限时截断而非报错
+	RandomizeTargets      bool           // 打乱目标扫描顺序
+	Seed                  int64          // 打乱目标顺序使用的随机种子，0表示随机生成
+	Output                string         // 输出文件路径
+	JSONOutput            bool           // 是否使用JSON格式输出结果
+	OutputTemplate        string         // 自定义控制台输出模板（text/template）
+	OutputDedup           bool           // 丢弃同一次运行中完全相同的输出记录
+	OutputAppendID        bool           // 输出记录附带本次运行ID
+	SockOutput            string         // socket文件输出路径，启用后会以JSON格式输出到socket文件
+	RequestLog            string         // 请求审计日志文件路径
+	Proxy                 string         // 代理地址
+	MinTLS                string         // 最低TLS版本
+	TLSDefaultCiphers     bool           // 使用Go默认的TLS加密套件
+	TLSProbe              bool           // 探测HTTPS目标接受的TLS协议版本
+	NoProxy               []string       // 不走代理直连的主机列表
+	EnvProxy              bool           // 未指定代理时使用环境变量中的代理
+	ProxyTest             string         // 启动时通过代理请求的探测地址，为空时仅检测代理端口可连接
+	IgnoreProxyErrors     bool           // 代理检测失败时仅告警并继续运行
+	Threads               int            // 并发线程数
+	RuleThreads           int            // 指纹规则线程数
+	Timeout               int            // 超时时间，默认5秒
+	HTTPSTimeoutFactor    float64        // https目标的超时倍数
+	Retries               int            // 重试次数，默认1次
+	MaxRedirects          int            // 最大跳转次数，默认5次
+	Debug                 bool           // 设置debug模式
+	NoTimestamp           bool           // 输出时间戳
+	FileLog               bool           // 是否禁用文件日志，仅输出到控制台
+	FingerOptions         YamlFingerType // Finger yaml文件配置
+	Active                bool           // 主动指纹探测
+	ExcludeCDN            bool           // 跳过CDN后的目标，仅记录基础信息
+	NoFavicon             bool           // 禁用favicon抓取与hash计算
+	HeaderOnlyMatch       bool           // 仅依赖响应头的指纹直接基于基础信息评估
+	FaviconPaths          []string       // 页面图标与 /favicon.ico 均获取失败时依次尝试的备用路径
+	IconURL               string         // 手动指定的icon地址，完整URL或以 / 开头的站点路径
+	AllIcons              bool           // 计算页面中全部候选icon的hash
+	Wappalyzer            bool           // 是否启用Wappalyzer站点技术识别，默认启用
+	IgnoreBody            bool           // 基础信息探测不读取响应体，仅基于状态码与响应头识别
+	TryWWW                bool           // 域名不存在时改用www/非www形式重试一次
+	RawMode               bool           // 指纹规则的HTTP请求通过rawhttp按原始字节发送
+	ProbeMethod           string         // 基础信息探测使用的请求方法，默认GET
+	ProbeRetries          int            // 基础信息探测失败重试次数，0表示不重试
+	RetryOnEmptyBody      bool           // 基础信息探测返回空响应体时重试一次
+	Ordered               bool           // 按输入顺序输出结果
+	InitConfig            bool           // 初始化配置文件
+	PrintPreset           bool           // 打印预配置
+	ListFingers           bool           // 打印当前加载的指纹信息
+	ReplayRequest         string         // 发送到目标的原始请求文件，用于规则调试
+	ReplayExpr            string         // 重放请求后评估的CEL表达式
+	Config                string         // 指定配置文件
+	Version               bool           // 打印版本信息
+	Serve                 string         // 以HTTP服务模式运行的监听地址
+	Passive               string         // 被动识别模式读取的请求响应目录
+	OutputEncoding        string         // txt/csv输出文件的字符编码
+	VerifyTLS             bool           // 校验目标站点的TLS证书
+	VerifyProxyTLS        bool           // 校验HTTPS代理的TLS证书
+	DNSCacheTTL           int            // DNS缓存有效期（秒），0表示不缓存
+	OutputGzip            bool           // 以gzip压缩格式写入输出文件
+	OutputFlushInterval   int            // 定期将输出文件刷新到磁盘的间隔（秒），0表示不定期刷新
+	ProxyFallback         bool           // 经代理请求失败时直连重试一次
+	FingerprintTimeout    int            // 单个指纹评估的最长耗时（秒），0表示不限制
+	KeepRaw               bool           // 结果输出后保留请求/响应数据
+	OutputIncludeRequest  bool           // JSON输出中附带匹配指纹的原始请求
+	OutputIncludeResponse bool           // JSON输出中附带匹配指纹的原始响应
+	RedactHeaders         []string       // 输出中掩码值的敏感请求/响应头，nil时使用默认列表，空列表时不掩码
+	TitleMaxLen           int            // 标题最大长度，0表示不限制
+	SpoofIP               string         // X-Forwarded-For 伪造来源IP模式
+	StopAtFirstMatch      bool           // 目标命中首个指纹后停止评估剩余指纹
+	CacheDir              string         // 基础信息持久化缓存目录
 }
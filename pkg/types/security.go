@@ -0,0 +1,10 @@
+package types
+
+// SecurityHeaders 定义安全响应头分析结果的结构体
+type SecurityHeaders struct {
+	ContentSecurityPolicy   string   `json:"content_security_policy,omitempty"`   // Content-Security-Policy
+	StrictTransportSecurity string   `json:"strict_transport_security,omitempty"` // Strict-Transport-Security
+	XFrameOptions           string   `json:"x_frame_options,omitempty"`           // X-Frame-Options
+	XContentTypeOptions     string   `json:"x_content_type_options,omitempty"`    // X-Content-Type-Options
+	Missing                 []string `json:"missing,omitempty"`                   // 缺失的安全响应头
+}
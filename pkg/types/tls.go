@@ -0,0 +1,7 @@
+package types
+
+// TLSInfo 定义TLS协议版本探测结果的结构体
+type TLSInfo struct {
+	Versions []string `json:"versions"`       // 目标接受的TLS协议版本
+	Weak     []string `json:"weak,omitempty"` // 已被废弃的弱协议版本（TLS1.0/TLS1.1）
+}
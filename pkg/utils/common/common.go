@@ -12,8 +12,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"math/big"
+	"math/bits"
 	"math/rand"
 	"net"
 	"net/url"
@@ -40,11 +42,12 @@ type AddressInfo struct {
 }
 
 const (
-	HttpType = "http"
-	TcpType  = "tcp"
-	UdpType  = "udp"
-	SslType  = "ssl"
-	GoType   = "go"
+	HttpType  = "http"
+	Http2Type = "http2" // HTTP/2请求，http目标使用h2c先验知识方式，https目标通过TLS协商h2
+	TcpType   = "tcp"
+	UdpType   = "udp"
+	SslType   = "ssl"
+	GoType    = "go"
 )
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyz"
@@ -271,7 +274,7 @@ func Str2UTF8(str string) string {
 	if !utf8.ValidString(str) {
 		nstr, err := Str2GB18030Str(str)
 		if err != nil {
-			logger.Error("Str2UTF8 error: %v", err)
+			logger.Errorf("Str2UTF8 error: %v", err)
 			return ""
 		}
 		return nstr
@@ -310,6 +313,55 @@ func Mmh3Hash32(raw []byte) int32 {
 	return int32(h32.Sum32())
 }
 
+// SimHash
+//
+//	@Description: 计算给定字节的 simhash（局部敏感哈希），相似内容的哈希值汉明距离较小
+//	@param raw 字节数组
+//	@return uint64 simhash值
+func SimHash(raw []byte) uint64 {
+	var weights [64]int
+	// 以单词为特征，单词过少时退化为3字节分片
+	features := bytes.Fields(raw)
+	if len(features) < 3 {
+		features = features[:0]
+		for i := 0; i+3 <= len(raw); i++ {
+			features = append(features, raw[i:i+3])
+		}
+	}
+	if len(features) == 0 {
+		return 0
+	}
+	for _, feature := range features {
+		h := fnv.New64a()
+		_, _ = h.Write(feature)
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	var hash uint64
+	for i := 0; i < 64; i++ {
+		if weights[i] > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// HammingDistance
+//
+//	@Description: 计算两个 simhash 值之间的汉明距离
+//	@param a simhash值
+//	@param b simhash值
+//	@return int 汉明距离
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
 // StandBase64Encode
 //
 //	@Description: 标准化Base64编码(符合 MIME 标准（RFC 2045） 中对 Base64 编码文本的建议：每行不超过 76 个字符。)
@@ -526,12 +578,26 @@ func ParseAddress(address string) (AddressInfo, error) {
 
 // GetRandomIP 获取随机ip地址
 func GetRandomIP() string {
+	randMutex.Lock()
+	defer randMutex.Unlock()
 	//rand.Seed(time.Now().UnixNano())
 	ip := make(net.IP, 4)
 	binary.BigEndian.PutUint32(ip, randSource.Uint32())
 	return ip.String()
 }
 
+// GetRandomIPv6 获取随机IPv6地址，位于全球单播地址段 2000::/3
+func GetRandomIPv6() string {
+	randMutex.Lock()
+	defer randMutex.Unlock()
+	ip := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ip[:8], randSource.Uint64())
+	binary.BigEndian.PutUint64(ip[8:], randSource.Uint64())
+	// 高3位固定为001
+	ip[0] = 0x20 | (ip[0] & 0x1f)
+	return ip.String()
+}
+
 // RemoveDuplicateURLs 去除重复的URL
 func RemoveDuplicateURLs(urls []string) []string {
 	// 使用map来判断URL是否重复
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"net"
+	"testing"
+)
+
+func TestGetRandomIPv6(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		s := GetRandomIPv6()
+		ip := net.ParseIP(s)
+		if ip == nil || ip.To4() != nil {
+			t.Fatalf("GetRandomIPv6() = %q，期望合法的IPv6地址", s)
+		}
+		// 位于全球单播地址段 2000::/3
+		if ip[0]&0xe0 != 0x20 {
+			t.Errorf("GetRandomIPv6() = %s，期望位于 2000::/3", s)
+		}
+		seen[s] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("100 次生成仅得到 %d 个不同地址", len(seen))
+	}
+}
@@ -2,6 +2,8 @@ package common
 
 import (
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -23,3 +25,61 @@ func Exists(path string) bool {
 func IsYamlFile(filename string) bool {
 	return strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml")
 }
+
+// HasGlobMeta 判断路径中是否包含通配符
+func HasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// GlobRoot 返回通配符模式中第一个通配段之前的目录，作为遍历的根目录
+func GlobRoot(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	root := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if HasGlobMeta(segment) {
+			break
+		}
+		root = append(root, segment)
+	}
+	if len(root) == 0 {
+		return "."
+	}
+	if len(root) == 1 && root[0] == "" {
+		return "/"
+	}
+	return filepath.FromSlash(strings.Join(root, "/"))
+}
+
+// MatchGlob 判断路径是否匹配通配符模式，单段规则同 path.Match，** 匹配任意层级目录
+func MatchGlob(pattern, name string) bool {
+	patternSegments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	nameSegments := strings.Split(filepath.ToSlash(filepath.Clean(name)), "/")
+	return matchGlobSegments(patternSegments, nameSegments)
+}
+
+// matchGlobSegments 逐段匹配路径
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
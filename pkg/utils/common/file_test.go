@@ -0,0 +1,47 @@
+package common
+
+import "testing"
+
+func TestGlobRoot(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"rules/**/apache-*.yaml", "rules"},
+		{"rules/web/*.yaml", "rules/web"},
+		{"*.yaml", "."},
+		{"/etc/rules/*/x.yaml", "/etc/rules"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := GlobRoot(tt.pattern); got != tt.want {
+				t.Errorf("GlobRoot(%q) = %q，期望 %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**匹配零层目录", "rules/**/apache-*.yaml", "rules/apache-httpd.yaml", true},
+		{"**匹配多层目录", "rules/**/apache-*.yaml", "rules/web/server/apache-tomcat.yaml", true},
+		{"文件名不匹配", "rules/**/apache-*.yaml", "rules/web/nginx.yaml", false},
+		{"*不跨越目录", "rules/*.yaml", "rules/web/nginx.yaml", false},
+		{"单层通配", "rules/*/nginx.yaml", "rules/web/nginx.yaml", true},
+		{"末尾**匹配任意文件", "rules/**", "rules/web/nginx.yaml", true},
+		{"?匹配单个字符", "rules/v?.yaml", "rules/v1.yaml", true},
+		{"非法模式", "rules/[.yaml", "rules/[.yaml", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v，期望 %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
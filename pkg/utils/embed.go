@@ -6,6 +6,9 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
 	finger2 "xfirefly/pkg/finger"
 	"xfirefly/pkg/utils/common"
 
@@ -51,7 +54,7 @@ func GetFingerPath() string {
 
 // GetFingerYaml 获取指纹yaml文件
 func GetFingerYaml() ([]*finger2.Finger, error) {
-	var allFinger []*finger2.Finger
+	var paths []string
 
 	// 递归遍历所有目录查找yaml文件
 	err := fs.WalkDir(EmbeddedFingerFS, "fingerprint", func(path string, d fs.DirEntry, err error) error {
@@ -61,13 +64,7 @@ func GetFingerYaml() ([]*finger2.Finger, error) {
 
 		// 只处理yaml文件
 		if !d.IsDir() && common.IsYamlFile(d.Name()) {
-			poc, err := finger2.Load(path, EmbeddedFingerFS)
-			if err != nil {
-				return fmt.Errorf("加载文件 %s 出错: %v", path, err)
-			}
-			if poc != nil {
-				allFinger = append(allFinger, poc)
-			}
+			paths = append(paths, path)
 		}
 		return nil
 	})
@@ -76,29 +73,87 @@ func GetFingerYaml() ([]*finger2.Finger, error) {
 		return nil, fmt.Errorf("遍历指纹目录出错: %v", err)
 	}
 
-	return allFinger, nil
+	return loadFingers(paths, func(path string) (*finger2.Finger, error) {
+		poc, err := finger2.Load(path, EmbeddedFingerFS)
+		if err != nil {
+			return nil, fmt.Errorf("加载文件 %s 出错: %v", path, err)
+		}
+		return poc, nil
+	}, true)
 }
 
 // GetCustomFingerYaml 获取指定目录及其子目录下所有指纹文件并返回
+// path 包含通配符时（如 rules/**/apache-*.yaml），仅加载匹配模式的文件
+// 文件收集后由多个协程并发解析，返回结果按路径排序
 func GetCustomFingerYaml(path string) ([]*finger2.Finger, error) {
-	// 临时存储所有指纹文件
-	var fingerYamls []*finger2.Finger
-	// 读取所有目录下的指纹文件
-	err := filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
+	// 通配符模式从第一个通配段之前的目录开始遍历
+	pattern := ""
+	root := path
+	if common.HasGlobMeta(path) {
+		pattern = path
+		root = common.GlobRoot(path)
+	}
+
+	// 收集所有待解析的指纹文件路径
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if pattern != "" && !common.MatchGlob(pattern, path) {
+			return nil
+		}
 		if !d.IsDir() && common.IsYamlFile(path) {
-			if poc, err := finger2.Read(path); err == nil && poc != nil {
-				// 添加到临时存储
-				fingerYamls = append(fingerYamls, poc)
-			}
+			paths = append(paths, path)
 		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	// 返回所有指纹文件
-	return fingerYamls, nil
+
+	// 解析失败的文件直接跳过
+	return loadFingers(paths, finger2.Read, false)
+}
+
+// loadFingers 使用有限数量的协程并发解析指纹文件，结果按路径排序，保证加载顺序稳定
+// strict 为 true 时任一文件解析失败即返回错误，否则跳过解析失败的文件
+func loadFingers(paths []string, parse func(string) (*finger2.Finger, error), strict bool) ([]*finger2.Finger, error) {
+	sort.Strings(paths)
+
+	// 结果按下标写回，避免并发追加导致顺序不确定
+	parsed := make([]*finger2.Finger, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	workers := min(runtime.NumCPU(), len(paths))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				parsed[i], errs[i] = parse(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	fingers := make([]*finger2.Finger, 0, len(parsed))
+	for i, poc := range parsed {
+		if errs[i] != nil {
+			if strict {
+				return nil, errs[i]
+			}
+			logger.Warnf("指纹文件 %s 解析失败，已跳过: %v", paths[i], errs[i])
+			continue
+		}
+		if poc != nil {
+			fingers = append(fingers, poc)
+		}
+	}
+	return fingers, nil
 }
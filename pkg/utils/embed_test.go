@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	finger2 "xfirefly/pkg/finger"
+)
+
+func TestGetCustomFingerYamlParallel(t *testing.T) {
+	dir := t.TempDir()
+	const files = 2000
+	for i := 0; i < files; i++ {
+		// 规则名逆序书写，检查解析后保持文件中的书写顺序
+		content := fmt.Sprintf(`id: finger-%04d
+info:
+  name: finger-%04d
+rules:
+  z%d:
+    request:
+      path: /z
+    expression: response.status == 200
+  m%d:
+    request:
+      path: /m
+    expression: response.status == 200
+  a%d:
+    request:
+      path: /a
+    expression: response.status == 200
+expression: z%d() && m%d() && a%d()
+`, i, i, i, i, i, i, i, i)
+		sub := filepath.Join(dir, fmt.Sprintf("group-%d", i%10))
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("finger-%04d.yaml", i)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 解析失败的文件被跳过，不影响其它文件
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("rules: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fingers, err := GetCustomFingerYaml(dir)
+	if err != nil {
+		t.Fatalf("加载指纹失败: %v", err)
+	}
+	if len(fingers) != files {
+		t.Fatalf("加载指纹数量 = %d，期望 %d", len(fingers), files)
+	}
+
+	var previous string
+	for _, f := range fingers {
+		var num int
+		if _, err := fmt.Sscanf(f.Id, "finger-%d", &num); err != nil {
+			t.Fatalf("指纹ID %q 无法解析: %v", f.Id, err)
+		}
+		want := fmt.Sprintf("z%d,m%d,a%d", num, num, num)
+		keys := make([]string, 0, len(f.Rules))
+		for _, rule := range f.Rules {
+			keys = append(keys, rule.Key)
+		}
+		if got := strings.Join(keys, ","); got != want {
+			t.Errorf("%s 规则顺序 = %s，期望 %s", f.Id, got, want)
+		}
+
+		// 结果按文件路径排序
+		path := fmt.Sprintf("group-%d/finger-%04d.yaml", num%10, num)
+		if path < previous {
+			t.Errorf("指纹 %s 的顺序不是按路径排序", f.Id)
+		}
+		previous = path
+	}
+}
+
+func TestGetCustomFingerYamlGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"apache-httpd.yaml", "web/apache-tomcat.yaml", "web/nginx.yaml", "apache-notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		id := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		content := fmt.Sprintf("id: %s\ninfo:\n  name: %s\nrules:\n  r0:\n    request:\n      path: /\n    expression: response.status == 200\nexpression: r0()\n", id, id)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fingers, err := GetCustomFingerYaml(filepath.Join(dir, "**", "apache-*.yaml"))
+	if err != nil {
+		t.Fatalf("加载指纹失败: %v", err)
+	}
+	ids := make([]string, 0, len(fingers))
+	for _, f := range fingers {
+		ids = append(ids, f.Id)
+	}
+	if got := strings.Join(ids, ","); got != "apache-httpd,apache-tomcat" {
+		t.Errorf("加载的指纹 = %s，期望 apache-httpd,apache-tomcat", got)
+	}
+}
+
+func TestLoadFingers(t *testing.T) {
+	paths := []string{"c.yaml", "broken.yaml", "a.yaml", "empty.yaml", "b.yaml"}
+	parse := func(path string) (*finger2.Finger, error) {
+		switch path {
+		case "broken.yaml":
+			return nil, fmt.Errorf("解析 %s 失败", path)
+		case "empty.yaml":
+			return nil, nil
+		}
+		return &finger2.Finger{Id: strings.TrimSuffix(path, ".yaml")}, nil
+	}
+
+	// 非严格模式跳过解析失败与空的文件，结果按路径排序
+	fingers, err := loadFingers(append([]string(nil), paths...), parse, false)
+	if err != nil {
+		t.Fatalf("loadFingers 返回错误: %v", err)
+	}
+	ids := make([]string, 0, len(fingers))
+	for _, f := range fingers {
+		ids = append(ids, f.Id)
+	}
+	if got := strings.Join(ids, ","); got != "a,b,c" {
+		t.Errorf("加载的指纹 = %s，期望 a,b,c", got)
+	}
+
+	// 严格模式（内置指纹）任一文件解析失败即返回错误
+	if _, err := loadFingers(append([]string(nil), paths...), parse, true); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("严格模式错误 = %v，期望返回 broken.yaml 的解析错误", err)
+	}
+
+	if fingers, err := loadFingers(nil, parse, true); err != nil || len(fingers) != 0 {
+		t.Errorf("无文件时 loadFingers = %v（%v），期望空结果", fingers, err)
+	}
+}
@@ -0,0 +1,13 @@
+package proto
+
+import "bytes"
+
+// SetBody 设置响应体，复制 b 后由 body 与 rawbody 共用同一份副本，调用方之后修改 b 不影响响应体
+func (x *Response) SetBody(b []byte) {
+	body := bytes.Clone(b)
+	if body == nil {
+		body = []byte{}
+	}
+	x.Body = body
+	x.Rawbody = body
+}
@@ -7,14 +7,18 @@ import (
 )
 
 // Wappalyzer 技术识别器结构体
+//
+// 指纹库仅在 NewWappalyzer 时编译加载，之后的分析过程只读取已编译的指纹且不修改任何共享状态，
+// 因此同一实例可以被多个协程并发调用，无需加锁，也无需为每个目标单独创建实例
 type Wappalyzer struct {
-	client *wappalyzer.Wappalyze
+	client *wappalyzer.Wappalyze // 创建后只读，不得替换或重新加载
 }
 
 // TypeWappalyzer 存储网站技术栈信息的结构体
 type TypeWappalyzer struct {
 	WebServers           []string `json:"web_servers"`           //WEB服务器
 	ReverseProxies       []string `json:"reverse_proxies"`       //代理服务器
+	CDN                  []string `json:"cdn"`                   //CDN服务
 	JavaScriptFrameworks []string `json:"javascript_frameworks"` //JS框架
 	JavaScriptLibraries  []string `json:"javascript_libraries"`  //JavaScript库
 	WebFrameworks        []string `json:"web_frameworks"`        //WEB框架
@@ -61,6 +65,7 @@ func (w *Wappalyzer) FormatData(data map[string]wappalyzer.AppInfo) *TypeWappaly
 		"Hosting panels":        &result.HostingPanels,
 		"Caching":               &result.Caching,
 		"Reverse proxies":       &result.ReverseProxies,
+		"CDN":                   &result.CDN,
 		"Static site generator": &result.StaticSiteGenerator,
 	}
 
@@ -89,7 +94,9 @@ func ConvertHeaders(headers map[string]string) map[string][]string {
 	return result
 }
 
-// GetWappalyzer 分析HTTP响应头和响应体，识别网站使用的技术栈
+// GetWappalyzer 分析HTTP响应头和响应体，识别网站使用的技术栈，可并发调用
+//
+// 每次调用都会生成独立的结果对象，respHeader 与 respData 仅被读取，调用方在返回后可继续复用
 func (w *Wappalyzer) GetWappalyzer(respHeader map[string][]string, respData []byte) (*TypeWappalyzer, error) {
 	if w == nil || w.client == nil {
 		return nil, fmt.Errorf("wappalyzer实例未正确初始化")
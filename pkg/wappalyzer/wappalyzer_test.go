@@ -0,0 +1,89 @@
+package wappalyzer
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// normalize 对各类别下的技术名称排序，FormatData 按 map 遍历顺序追加，顺序不固定
+func normalize(r *TypeWappalyzer) *TypeWappalyzer {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	v := reflect.ValueOf(&c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if names, ok := v.Field(i).Interface().([]string); ok {
+			sorted := append([]string(nil), names...)
+			sort.Strings(sorted)
+			v.Field(i).Set(reflect.ValueOf(sorted))
+		}
+	}
+	return &c
+}
+
+func TestGetWappalyzerConcurrent(t *testing.T) {
+	w, err := NewWappalyzer()
+	if err != nil {
+		t.Fatalf("初始化Wappalyzer失败: %v", err)
+	}
+	// 所有协程共用同一份请求头与响应体，分析过程只读，配合 -race 检查数据竞争
+	headers := map[string][]string{
+		"Server":       {"nginx/1.20.1"},
+		"X-Powered-By": {"PHP/7.4.3"},
+		"Set-Cookie":   {"PHPSESSID=abc; path=/"},
+	}
+	body := []byte(`<html><head><script src="/js/jquery-3.6.0.min.js"></script></head><body>wp-content</body></html>`)
+
+	want, err := w.GetWappalyzer(headers, body)
+	if err != nil {
+		t.Fatalf("GetWappalyzer 失败: %v", err)
+	}
+
+	const workers = 16
+	var wg sync.WaitGroup
+	results := make([]*TypeWappalyzer, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if i%2 == 0 {
+					results[i], errs[i] = w.GetWappalyzer(headers, body)
+				} else {
+					results[i], errs[i] = w.Analyze(headers, body)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("第 %d 个协程分析失败: %v", i, errs[i])
+		}
+		if !reflect.DeepEqual(normalize(results[i]), normalize(want)) {
+			t.Errorf("第 %d 个协程结果 = %+v，期望与串行结果一致 %+v", i, results[i], want)
+		}
+		// 每次调用返回独立的结果对象
+		if results[i] == want {
+			t.Errorf("第 %d 个协程返回了共享的结果对象", i)
+		}
+	}
+	if headers["Server"][0] != "nginx/1.20.1" || string(body[:6]) != "<html>" {
+		t.Error("分析过程不应修改传入的请求头与响应体")
+	}
+}
+
+func TestGetWappalyzerUninitialized(t *testing.T) {
+	var w *Wappalyzer
+	if _, err := w.GetWappalyzer(nil, nil); err == nil {
+		t.Error("未初始化的实例应返回错误")
+	}
+	if _, err := (&Wappalyzer{}).Analyze(nil, nil); err == nil {
+		t.Error("client 为空时应返回错误")
+	}
+}